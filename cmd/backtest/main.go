@@ -0,0 +1,84 @@
+// Package main is the entry point for the backtest command.
+// It replays a stored property CSV and compares analyzer configurations.
+//
+// Usage:
+//
+//	aws s3 cp s3://<bucket>/properties.csv .
+//	go run ./cmd/backtest -input properties.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/analyzer"
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func main() {
+	defaults := analyzer.DefaultBacktestOptions()
+
+	input := flag.String("input", "properties.csv", "path to the stored property CSV")
+	interval := flag.Duration("interval", defaults.Interval, "step between replayed cutoff dates")
+	quickDelist := flag.Duration("quick-delist", defaults.QuickDelist, "maximum listing duration counted as delisted quickly")
+	folds := flag.Int("folds", defaults.Folds, "number of cross-validation folds (0 disables)")
+	flag.Parse()
+
+	if err := run(*input, analyzer.BacktestOptions{
+		Interval:    *interval,
+		QuickDelist: *quickDelist,
+		Folds:       *folds,
+	}); err != nil {
+		log.Fatal(err)
+	}
+}
+
+const day = 24 * time.Hour
+
+// modelConfigs returns the analyzer configurations compared by the backtest.
+func modelConfigs() []analyzer.ModelConfig {
+	return []analyzer.ModelConfig{
+		{Name: "ols+knn3", Options: []analyzer.Option{analyzer.WithComparablesK(3)}},
+		{Name: "ols+knn5", Options: []analyzer.Option{analyzer.WithComparablesK(5)}},
+		{Name: "ols+knn10", Options: []analyzer.Option{analyzer.WithComparablesK(10)}},
+		{Name: "ols-decay30d", Options: []analyzer.Option{analyzer.WithTimeDecay(30 * day)}},
+		{Name: "ols-decay90d", Options: []analyzer.Option{analyzer.WithTimeDecay(90 * day)}},
+		{Name: "ols-lookback180d", Options: []analyzer.Option{analyzer.WithLookback(180 * day)}},
+		{Name: "ols-seasonal", Options: []analyzer.Option{analyzer.WithSeasonality(true)}},
+		{Name: "ols-decay90d-seasonal", Options: []analyzer.Option{
+			analyzer.WithTimeDecay(90 * day),
+			analyzer.WithSeasonality(true),
+		}},
+		{Name: "forest", Options: []analyzer.Option{analyzer.WithModelType(analyzer.ModelTypeForest)}},
+		{Name: "ols-spline", Options: []analyzer.Option{
+			analyzer.WithFeatureTransforms(map[string]analyzer.FeatureTransform{
+				"walk_minutes": {Kind: analyzer.TransformSpline, Knots: []float64{5, 10}},
+				"age":          {Kind: analyzer.TransformSpline, Knots: []float64{3, 15}},
+			}),
+		}},
+		{Name: "forest-decay90d", Options: []analyzer.Option{
+			analyzer.WithModelType(analyzer.ModelTypeForest),
+			analyzer.WithTimeDecay(90 * day),
+		}},
+	}
+}
+
+func run(input string, opts analyzer.BacktestOptions) error {
+	f, err := os.Open(input)
+	if err != nil {
+		return fmt.Errorf("failed to open input: %w", err)
+	}
+	defer f.Close()
+
+	properties, err := models.LoadFromCSV(f)
+	if err != nil {
+		return fmt.Errorf("failed to load properties: %w", err)
+	}
+	log.Printf("Loaded %d properties from %s", len(properties), input)
+
+	results := analyzer.Backtest(properties, modelConfigs(), opts)
+	return analyzer.WriteBacktestTable(os.Stdout, results)
+}
@@ -0,0 +1,125 @@
+// Package main is the entry point of the read-only web dashboard over the
+// stored data.
+//
+// Usage:
+//
+//	dashboard [-addr localhost:8081] [-profile NAME] [-local DIR | -endpoint URL] [-refresh 5m]
+//
+// The dashboard reads the same environment variables as the Lambda function.
+// Data is read from S3 unless -local is set, in which case objects are read
+// from files under DIR/<BUCKET_NAME>/. -endpoint reads from an S3-compatible
+// store (e.g., MinIO) with path-style addressing.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/alp/suumo-hunter/internal/config"
+	"github.com/alp/suumo-hunter/internal/dashboard"
+	"github.com/alp/suumo-hunter/internal/logging"
+	"github.com/alp/suumo-hunter/internal/pipeline"
+	"github.com/alp/suumo-hunter/internal/storage"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8081", "listen address")
+	profile := flag.String("profile", os.Getenv("PROFILE"), "configuration profile")
+	local := flag.String("local", "", "read data from files under this directory instead of S3")
+	endpoint := flag.String("endpoint", "", "URL of an S3-compatible store")
+	refresh := flag.Duration("refresh", 5*time.Minute, "interval at which the stored data is reloaded")
+	flag.Parse()
+
+	cfg, err := config.LoadProfile(*profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, *addr, *local, *endpoint, *refresh, logger); err != nil {
+		logger.Error("Dashboard failed", "error", err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// run serves the dashboard until ctx is canceled.
+func run(ctx context.Context, cfg *config.Config, addr, local, endpoint string, refresh time.Duration, logger *slog.Logger) error {
+	store, err := newStore(ctx, cfg, local, endpoint, storage.WithLogger(logger))
+	if err != nil {
+		return err
+	}
+	a, err := pipeline.NewAnalyzer(cfg)
+	if err != nil {
+		return err
+	}
+	dash, err := dashboard.New(cfg, store, a, dashboard.WithLogger(logger), dashboard.WithRefreshInterval(refresh))
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           dash,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("Listening", "url", "http://"+addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
+
+// newStore creates the storage: local files under dir if it is set, S3 or
+// the S3-compatible store at endpoint otherwise.
+func newStore(ctx context.Context, cfg *config.Config, dir, endpoint string, opts ...storage.Option) (*storage.Storage, error) {
+	if dir != "" {
+		return storage.NewStorage(storage.NewLocalClient(dir), cfg.BucketName, cfg.BucketKey, opts...), nil
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+	return storage.NewStorage(client, cfg.BucketName, cfg.BucketKey, opts...), nil
+}
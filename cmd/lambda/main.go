@@ -4,17 +4,18 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	_ "time/tzdata" // Time zones for digest schedules
 
 	"github.com/aws/aws-lambda-go/lambda"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
-	"github.com/alp/suumo-hunter/internal/analyzer"
 	"github.com/alp/suumo-hunter/internal/config"
-	"github.com/alp/suumo-hunter/internal/models"
-	"github.com/alp/suumo-hunter/internal/notifier"
-	"github.com/alp/suumo-hunter/internal/scraper"
+	"github.com/alp/suumo-hunter/internal/logging"
+	"github.com/alp/suumo-hunter/internal/metrics"
+	"github.com/alp/suumo-hunter/internal/pipeline"
 	"github.com/alp/suumo-hunter/internal/storage"
 )
 
@@ -22,73 +23,81 @@ func main() {
 	lambda.Start(Handler)
 }
 
-// Handler is the Lambda function handler.
-func Handler(ctx context.Context) error {
-	log.Println("Starting SUUMO Hunter...")
+// Event is the Lambda invocation event. All fields are optional, so an
+// empty event (e.g. from a plain EventBridge schedule) runs the scrape job
+// as configured by the environment variables.
+//
+// Example: {"job": "digest", "profile": "family"}
+type Event struct {
+	// Job is the job to run: "scrape" (default), "digest", "report" or "backfill".
+	Job string `json:"job"`
 
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-	log.Printf("Config loaded: bucket=%s, key=%s, maxPage=%d",
-		cfg.BucketName, cfg.BucketKey, cfg.MaxPage)
+	// Profile overrides the PROFILE environment variable.
+	Profile string `json:"profile"`
 
-	// Initialize AWS SDK
-	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	// MaxPages overrides MAX_PAGE if positive.
+	MaxPages int `json:"max_pages"`
+
+	// DryRun runs without uploading to S3 or sending notifications,
+	// in addition to the DRY_RUN environment variable.
+	DryRun bool `json:"dry_run"`
+
+	// NotifyAll notifies every listed property, not only new ones.
+	NotifyAll bool `json:"notify_all"`
+}
+
+// Handler is the Lambda function handler. It returns the summary of the run.
+func Handler(ctx context.Context, event Event) (pipeline.Summary, error) {
+	job, err := pipeline.ParseJob(event.Job)
 	if err != nil {
-		return fmt.Errorf("failed to load AWS config: %w", err)
+		return pipeline.Summary{}, fmt.Errorf("invalid event: %w", err)
 	}
-	s3Client := s3.NewFromConfig(awsCfg)
 
-	// Initialize components
-	store := storage.NewStorage(s3Client, cfg.BucketName, cfg.BucketKey)
-	scrp := scraper.NewScraper(cfg.SuumoSearchURL, scraper.WithMaxPages(cfg.MaxPage))
-	notify := notifier.NewNotifier(cfg.DiscordWebhookURL)
-	analyze := analyzer.NewAnalyzer()
-
-	// Step 1: Download previous data from S3
-	log.Println("Downloading previous data from S3...")
-	previousProperties, err := store.Download(ctx)
+	// Load configuration
+	profile := os.Getenv("PROFILE")
+	if event.Profile != "" {
+		profile = event.Profile
+	}
+	cfg, err := config.LoadProfile(profile)
 	if err != nil {
-		return fmt.Errorf("failed to download previous data: %w", err)
+		return pipeline.Summary{}, fmt.Errorf("failed to load config: %w", err)
+	}
+	if event.MaxPages > 0 {
+		cfg.MaxPage = event.MaxPages
 	}
-	log.Printf("Previous properties: %d", len(previousProperties))
+	cfg.DryRun = cfg.DryRun || event.DryRun
 
-	// Step 2: Scrape SUUMO
-	log.Printf("Scraping SUUMO (max %d pages)...", cfg.MaxPage)
-	currentProperties, err := scrp.Scrape(ctx)
+	logger, err := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
-		return fmt.Errorf("failed to scrape SUUMO: %w", err)
+		return pipeline.Summary{}, fmt.Errorf("failed to create logger: %w", err)
 	}
-	log.Printf("Current properties: %d", len(currentProperties))
-
-	// Step 3: Find new properties
-	newProperties := models.FindNewProperties(currentProperties, previousProperties)
-	log.Printf("New properties: %d", len(newProperties))
+	slog.SetDefault(logger)
+	logger.Info("Starting SUUMO Hunter", "job", job, "profile", cfg.Profile, "bucket", cfg.BucketName,
+		"key", cfg.BucketKey, "max_pages", cfg.MaxPage, "dry_run", cfg.DryRun, "notify_all", event.NotifyAll)
 
-	// Step 4: Merge and save to S3
-	mergedProperties := models.MergeProperties(currentProperties, previousProperties)
-	log.Printf("Uploading merged data (%d properties) to S3...", len(mergedProperties))
-	if err := store.Upload(ctx, mergedProperties); err != nil {
-		return fmt.Errorf("failed to upload data: %w", err)
+	// Initialize AWS SDK
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return pipeline.Summary{}, fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	s3Client := s3.NewFromConfig(awsCfg)
 
-	// Step 5: Analyze and notify if there are new properties
-	if len(newProperties) > 0 {
-		log.Println("Running regression analysis...")
-		// Use merged data for regression, but only analyze new properties
-		scoredProperties := analyze.AnalyzeNewProperties(mergedProperties, newProperties)
-
-		log.Println("Sending Discord notification...")
-		if err := notify.Notify(ctx, scoredProperties); err != nil {
-			return fmt.Errorf("failed to send notification: %w", err)
+	// Metrics are written to the log in Embedded Metric Format
+	emf := metrics.NewEMF(cfg.MetricsNamespace, metrics.L("Profile", cfg.Profile))
+	defer func() {
+		if err := emf.Flush(os.Stdout); err != nil {
+			logger.Error("Failed to write metrics", "error", err)
 		}
-		log.Printf("Notified %d new properties", len(newProperties))
-	} else {
-		log.Println("No new properties found, skipping notification")
+	}()
+
+	// Initialize components
+	store := storage.NewStorage(s3Client, cfg.BucketName, cfg.BucketKey,
+		storage.WithDryRun(cfg.DryRun), storage.WithLogger(logger), storage.WithMetrics(emf))
+	p, err := pipeline.New(cfg, store, pipeline.WithNotifyAll(event.NotifyAll),
+		pipeline.WithLogger(logger), pipeline.WithMetrics(emf))
+	if err != nil {
+		return pipeline.Summary{}, err
 	}
 
-	log.Println("SUUMO Hunter completed successfully!")
-	return nil
+	return p.RunJob(ctx, job)
 }
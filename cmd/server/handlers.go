@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/pipeline"
+	"github.com/alp/suumo-hunter/internal/scheduler"
+)
+
+// health is the response of /healthz.
+type health struct {
+	Status   string               `json:"status"`
+	Running  *scheduler.RunInfo   `json:"running"`
+	LastRun  *scheduler.RunInfo   `json:"last_run"`
+	NextRuns map[string]time.Time `json:"next_runs"`
+}
+
+// healthHandler reports the scheduler status. The server is healthy as long
+// as it responds; failed runs are reported in last_run.
+func healthHandler(sched *scheduler.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h := health{Status: "ok"}
+		h.Running, h.LastRun, h.NextRuns = sched.Status()
+		writeJSON(w, r, http.StatusOK, h)
+	}
+}
+
+// runHandler starts the job of the "job" query parameter (default scrape).
+// Responds 202 if the job was started, 409 if a job is already running, or
+// 503 if the server is shutting down.
+func runHandler(sched *scheduler.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := pipeline.ParseJob(r.URL.Query().Get("job"))
+		if err != nil {
+			writeJSON(w, r, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		switch err := sched.Trigger(string(job)); {
+		case errors.Is(err, scheduler.ErrClosed):
+			writeJSON(w, r, http.StatusServiceUnavailable, map[string]string{"error": "the server is shutting down"})
+		case err != nil:
+			running, _, _ := sched.Status()
+			writeJSON(w, r, http.StatusConflict, map[string]any{"error": err.Error(), "running": running})
+		default:
+			writeJSON(w, r, http.StatusAccepted, map[string]string{"job": string(job), "status": "started"})
+		}
+	}
+}
+
+// writeJSON writes v as a JSON response with the status code. Encoding
+// errors are logged, as the status has already been sent.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write response", "path", r.URL.Path, "error", err)
+	}
+}
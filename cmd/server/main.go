@@ -0,0 +1,169 @@
+// Package main is the entry point of the long-running server, for
+// deployments without Lambda. It runs the pipeline jobs on the schedules in
+// SCHEDULES and serves:
+//
+//	GET  /healthz          Status of the scheduler (running job, last run, next runs)
+//	GET  /metrics          Metrics in the Prometheus text format
+//	POST /run?job=scrape   Start a job now, unless one is running
+//
+// Usage:
+//
+//	server [-local DIR]
+//
+// The server reads the same environment variables as the Lambda function.
+// On SIGTERM or SIGINT it stops scheduling and waits up to SHUTDOWN_TIMEOUT
+// for the running job to finish.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Time zones for schedules and digests
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/alp/suumo-hunter/internal/config"
+	"github.com/alp/suumo-hunter/internal/logging"
+	"github.com/alp/suumo-hunter/internal/metrics"
+	"github.com/alp/suumo-hunter/internal/pipeline"
+	"github.com/alp/suumo-hunter/internal/scheduler"
+	"github.com/alp/suumo-hunter/internal/storage"
+)
+
+func main() {
+	local := flag.String("local", "", "store data as files under this directory instead of S3")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, *local, logger); err != nil {
+		logger.Error("Server failed", "error", err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// run serves and runs the scheduled jobs until ctx is canceled, then shuts
+// down gracefully.
+func run(ctx context.Context, cfg *config.Config, local string, logger *slog.Logger) error {
+	registry := metrics.NewRegistry("suumo_hunter")
+
+	store, err := newStore(ctx, cfg, local, storage.WithLogger(logger), storage.WithMetrics(registry))
+	if err != nil {
+		return err
+	}
+	entries, err := scheduleEntries(cfg)
+	if err != nil {
+		return err
+	}
+
+	// A pipeline is created for each run, as in Lambda
+	runJob := func(ctx context.Context, name string) error {
+		job, err := pipeline.ParseJob(name)
+		if err != nil {
+			return err
+		}
+		p, err := pipeline.New(cfg, store, pipeline.WithLogger(logger), pipeline.WithMetrics(registry))
+		if err != nil {
+			return err
+		}
+		_, err = p.RunJob(ctx, job)
+		return err
+	}
+	sched := scheduler.New(runJob, entries,
+		scheduler.WithJitter(cfg.ScheduleJitter), scheduler.WithLogger(logger), scheduler.WithMetrics(registry))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", healthHandler(sched))
+	mux.Handle("GET /metrics", registry)
+	mux.HandleFunc("POST /run", runHandler(sched))
+	server := &http.Server{
+		Addr:              cfg.ServerAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("Listening", "addr", cfg.ServerAddr)
+		serveErr <- server.ListenAndServe()
+	}()
+	go sched.Run(ctx)
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutting down")
+	case err = <-serveErr:
+		err = fmt.Errorf("failed to serve: %w", err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if serr := server.Shutdown(shutdownCtx); serr != nil && !errors.Is(serr, http.ErrServerClosed) {
+		logger.Error("Failed to shut down HTTP server", "error", serr)
+	}
+	if serr := sched.Shutdown(shutdownCtx); serr != nil {
+		logger.Error("Running job did not finish in time and was canceled", "error", serr)
+	}
+	return err
+}
+
+// newStore creates the storage, on local files under dir if it is set.
+func newStore(ctx context.Context, cfg *config.Config, dir string, opts ...storage.Option) (*storage.Storage, error) {
+	opts = append([]storage.Option{storage.WithDryRun(cfg.DryRun)}, opts...)
+	if dir != "" {
+		return storage.NewStorage(storage.NewLocalClient(dir), cfg.BucketName, cfg.BucketKey, opts...), nil
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return storage.NewStorage(s3.NewFromConfig(awsCfg), cfg.BucketName, cfg.BucketKey, opts...), nil
+}
+
+// scheduleEntries parses the job schedules of the configuration, sorted by job.
+func scheduleEntries(cfg *config.Config) ([]scheduler.Entry, error) {
+	loc, err := time.LoadLocation(cfg.ScheduleTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schedule time zone: %w", err)
+	}
+
+	var entries []scheduler.Entry
+	for name, expr := range cfg.Schedules {
+		job, err := pipeline.ParseJob(name)
+		if err != nil {
+			return nil, err
+		}
+		schedule, err := scheduler.ParseSchedule(expr, loc)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, scheduler.Entry{Job: string(job), Schedule: schedule})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Job < entries[j].Job })
+	return entries, nil
+}
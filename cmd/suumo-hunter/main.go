@@ -0,0 +1,383 @@
+// Package main is the entry point for the suumo-hunter command line tool.
+// It runs the pipeline and inspects stored data locally, without deploying.
+//
+// Usage:
+//
+//	suumo-hunter run [-job scrape|digest|report|backfill] [-pages N] [-dry-run] [-notify-all] [-local DIR]
+//	suumo-hunter scrape [-url URL] [-pages N] [-format json|csv]
+//	suumo-hunter analyze [-input FILE | -local DIR]
+//	suumo-hunter notify [-dry-run] [-since 24h] [-input FILE | -local DIR]
+//	suumo-hunter export [-format csv|json] [-o FILE] [-input FILE | -local DIR]
+//
+// Commands other than scrape read the same environment variables as the
+// Lambda function. Logs are written to stderr as configured by LOG_LEVEL and
+// LOG_FORMAT (e.g., LOG_FORMAT=text for the terminal). Data is read from and
+// written to S3 unless -local is set, in which case objects are stored as
+// files under DIR/<BUCKET_NAME>/.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Time zones for digest schedules
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/alp/suumo-hunter/internal/analyzer"
+	"github.com/alp/suumo-hunter/internal/config"
+	"github.com/alp/suumo-hunter/internal/logging"
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+	"github.com/alp/suumo-hunter/internal/pipeline"
+	"github.com/alp/suumo-hunter/internal/scraper"
+	"github.com/alp/suumo-hunter/internal/storage"
+)
+
+// commands maps subcommand names to their implementations.
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"run":     runCommand,
+	"scrape":  scrapeCommand,
+	"analyze": analyzeCommand,
+	"notify":  notifyCommand,
+	"export":  exportCommand,
+}
+
+const usage = `Usage: suumo-hunter <command> [flags]
+
+Commands:
+  run      Run the full pipeline (scrape, store, analyze, notify)
+  scrape   Scrape SUUMO and print the parsed properties
+  analyze  Fit the rent model on the stored data and print the model report
+  notify   Score recently listed properties and send (or print) notifications
+  export   Export the stored data as CSV or JSON
+
+Run "suumo-hunter <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command: %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	logCfg, err := config.LoadLogging()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	logger, err := logging.New(os.Stderr, logCfg.LogLevel, logCfg.LogFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := command(ctx, os.Args[2:]); err != nil {
+		logger.Error("Command failed", "command", os.Args[1], "error", err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// storeFlags are the flags selecting where stored data is read from.
+type storeFlags struct {
+	profile string
+	local   string
+	input   string
+}
+
+// register adds the store flags to fs. The -input flag is only added if withInput is set.
+func (f *storeFlags) register(fs *flag.FlagSet, withInput bool) {
+	fs.StringVar(&f.profile, "profile", os.Getenv("PROFILE"), "configuration profile")
+	fs.StringVar(&f.local, "local", "", "store data as files under this directory instead of S3")
+	if withInput {
+		fs.StringVar(&f.input, "input", "", "read properties from this CSV file instead of the store")
+	}
+}
+
+// config loads the configuration of the selected profile.
+func (f *storeFlags) config() (*config.Config, error) {
+	cfg, err := config.LoadProfile(f.profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// store creates the storage selected by the flags.
+func (f *storeFlags) store(ctx context.Context, cfg *config.Config) (*storage.Storage, error) {
+	if f.local != "" {
+		return storage.NewStorage(storage.NewLocalClient(f.local), cfg.BucketName, cfg.BucketKey, storage.WithDryRun(cfg.DryRun)), nil
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return storage.NewStorage(s3.NewFromConfig(awsCfg), cfg.BucketName, cfg.BucketKey, storage.WithDryRun(cfg.DryRun)), nil
+}
+
+// properties loads the stored properties, from the -input file if set.
+func (f *storeFlags) properties(ctx context.Context, cfg *config.Config) ([]models.Property, error) {
+	if f.input != "" {
+		file, err := os.Open(f.input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input: %w", err)
+		}
+		defer file.Close()
+		return models.LoadFromCSV(file)
+	}
+
+	store, err := f.store(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return store.Download(ctx)
+}
+
+// runCommand runs a pipeline job once and prints its summary as JSON.
+func runCommand(ctx context.Context, args []string) error {
+	var sf storeFlags
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	sf.register(fs, false)
+	jobName := fs.String("job", string(pipeline.JobScrape), "job to run (scrape, digest, report or backfill)")
+	pages := fs.Int("pages", 0, "maximum number of pages to scrape (default MAX_PAGE)")
+	dryRun := fs.Bool("dry-run", false, "log what would be uploaded and sent instead of doing it")
+	notifyAll := fs.Bool("notify-all", false, "notify every listed property, not only new ones")
+	fs.Parse(args)
+
+	job, err := pipeline.ParseJob(*jobName)
+	if err != nil {
+		return err
+	}
+	cfg, err := sf.config()
+	if err != nil {
+		return err
+	}
+	if *pages > 0 {
+		cfg.MaxPage = *pages
+	}
+	cfg.DryRun = cfg.DryRun || *dryRun
+	store, err := sf.store(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	p, err := pipeline.New(cfg, store, pipeline.WithNotifyAll(*notifyAll))
+	if err != nil {
+		return err
+	}
+	summary, err := p.RunJob(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(summary)
+}
+
+// scrapeCommand scrapes SUUMO and prints the parsed properties.
+func scrapeCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("scrape", flag.ExitOnError)
+	url := fs.String("url", os.Getenv("SUUMO_SEARCH_URL"), "SUUMO search result URL")
+	pages := fs.Int("pages", 1, "maximum number of pages to scrape")
+	format := fs.String("format", "json", "output format (json or csv)")
+	fs.Parse(args)
+
+	if *url == "" {
+		return errors.New("no search URL: set -url or SUUMO_SEARCH_URL")
+	}
+
+	properties, err := scraper.NewScraper(*url, scraper.WithMaxPages(*pages)).Scrape(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to scrape SUUMO: %w", err)
+	}
+	models.MarkSeen(properties, nil, time.Now())
+	slog.Info("Scraped SUUMO", "properties", len(properties))
+
+	return writeProperties(os.Stdout, properties, *format)
+}
+
+// analyzeCommand fits the configured rent model on the stored data and prints its report.
+func analyzeCommand(ctx context.Context, args []string) error {
+	var sf storeFlags
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	sf.register(fs, true)
+	fs.Parse(args)
+
+	cfg, err := sf.config()
+	if err != nil {
+		return err
+	}
+	properties, err := sf.properties(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	slog.Info("Loaded properties", "properties", len(properties))
+
+	analyze, err := pipeline.NewAnalyzer(cfg)
+	if err != nil {
+		return err
+	}
+	model, err := analyze.Fit(properties)
+	if err != nil {
+		return fmt.Errorf("failed to fit rent model: %w", err)
+	}
+
+	return analyzer.WriteModelReport(os.Stdout, model)
+}
+
+// notifyCommand scores the properties first seen within -since of the latest
+// listing and sends the notification, or prints it with -dry-run.
+func notifyCommand(ctx context.Context, args []string) error {
+	var sf storeFlags
+	fs := flag.NewFlagSet("notify", flag.ExitOnError)
+	sf.register(fs, true)
+	dryRun := fs.Bool("dry-run", false, "print the messages instead of sending them")
+	since := fs.Duration("since", 24*time.Hour, "notify properties first seen within this window before the latest listing")
+	fs.Parse(args)
+
+	cfg, err := sf.config()
+	if err != nil {
+		return err
+	}
+	properties, err := sf.properties(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	var latest time.Time
+	for _, p := range properties {
+		if p.FirstSeen.After(latest) {
+			latest = p.FirstSeen
+		}
+	}
+	var recent []models.Property
+	for _, p := range properties {
+		if !p.FirstSeen.Before(latest.Add(-*since)) {
+			recent = append(recent, p)
+		}
+	}
+	slog.Info("Scoring recent properties", "recent", len(recent), "properties", len(properties))
+
+	analyze, err := pipeline.NewAnalyzer(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := pipeline.NotificationFilter(cfg)
+	if err != nil {
+		return err
+	}
+	scored, filtered := filter.Apply(analyze.AnalyzeNewProperties(properties, recent))
+	if filtered.ExcludedCount() > 0 {
+		slog.Info("Filtered out properties", "excluded", filtered.ExcludedCount(), "total", filtered.Total, "rules", filtered.String())
+	}
+
+	var opts []notifier.Option
+	if *dryRun {
+		opts = append(opts, notifier.WithOutput(os.Stdout))
+	}
+	notify, err := pipeline.NewNotifier(cfg, opts...)
+	if err != nil {
+		return err
+	}
+	return notify.Notify(ctx, scored)
+}
+
+// exportCommand writes the stored data as CSV or JSON.
+func exportCommand(ctx context.Context, args []string) error {
+	var sf storeFlags
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	sf.register(fs, true)
+	format := fs.String("format", "csv", "output format (csv or json)")
+	output := fs.String("o", "", "output file (default stdout)")
+	fs.Parse(args)
+	// Before loading anything or creating the output file
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown format: %q", *format)
+	}
+
+	var properties []models.Property
+	var err error
+	if sf.input != "" {
+		// No configuration needed to convert a local file
+		properties, err = sf.properties(ctx, nil)
+	} else {
+		var cfg *config.Config
+		if cfg, err = sf.config(); err != nil {
+			return err
+		}
+		properties, err = sf.properties(ctx, cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		if err := writeProperties(os.Stdout, properties, *format); err != nil {
+			return err
+		}
+	} else {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create output: %w", err)
+		}
+		if err := writeProperties(f, properties, *format); err != nil {
+			f.Close()
+			return err
+		}
+		// Write errors may only surface when the file is closed
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to close output: %w", err)
+		}
+	}
+	slog.Info("Exported properties", "properties", len(properties))
+	return nil
+}
+
+// exportRecord is a property with its derived metrics, as exported to JSON.
+type exportRecord struct {
+	models.Property
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// writeProperties writes properties as CSV (with the derived metric columns)
+// or as JSON records with their metrics.
+func writeProperties(w io.Writer, properties []models.Property, format string) error {
+	switch format {
+	case "csv":
+		return models.SaveToCSV(w, properties)
+	case "json":
+		records := make([]exportRecord, len(properties))
+		for i, p := range properties {
+			records[i] = exportRecord{Property: p, Metrics: make(map[string]float64)}
+			for _, m := range models.Metrics() {
+				records[i].Metrics[m.Name] = m.Value(p)
+			}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	default:
+		return fmt.Errorf("unknown format: %q", format)
+	}
+}
@@ -0,0 +1,257 @@
+package analyzer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+const (
+	// DefaultBacktestInterval is the default step between replayed cutoff dates.
+	DefaultBacktestInterval = 7 * 24 * time.Hour
+
+	// DefaultQuickDelist is the default maximum listing duration for a listing
+	// to count as "delisted quickly" (a proxy for a real bargain).
+	DefaultQuickDelist = 14 * 24 * time.Hour
+
+	// DefaultFolds is the default number of folds for cross-validation.
+	DefaultFolds = 5
+)
+
+// ModelConfig is a named analyzer configuration compared in backtests.
+type ModelConfig struct {
+	Name    string
+	Options []Option
+}
+
+// BacktestOptions configures a backtest run.
+type BacktestOptions struct {
+	Interval    time.Duration // Step between replayed cutoff dates
+	QuickDelist time.Duration // Maximum listing duration counted as "delisted quickly"
+	Folds       int           // Number of cross-validation folds (0 disables)
+}
+
+// DefaultBacktestOptions returns the default backtest options.
+func DefaultBacktestOptions() BacktestOptions {
+	return BacktestOptions{
+		Interval:    DefaultBacktestInterval,
+		QuickDelist: DefaultQuickDelist,
+		Folds:       DefaultFolds,
+	}
+}
+
+// BacktestResult holds the evaluation metrics of one model configuration.
+type BacktestResult struct {
+	Name string
+
+	// Replay metrics: listings scored by a model fitted on earlier data only.
+	Scored int     // Number of listings scored by the regression
+	MAE    float64 // Mean absolute error of the predicted total rent (yen)
+	RMSE   float64 // Root mean squared error of the predicted total rent (yen)
+
+	// Comparables-based estimate accuracy in the replay.
+	ComparablesScored int
+	ComparablesMAE    float64
+
+	// Delisting metrics over listings whose observation window is complete.
+	Bargains                int     // Listings labeled as bargains
+	BargainQuickDelistRate  float64 // Share of bargains delisted quickly
+	BaselineQuickDelistRate float64 // Share of all scored listings delisted quickly
+
+	// K-fold cross-validation over the whole dataset.
+	CVMAE  float64
+	CVRMSE float64
+
+	// Validation error of a model fitted on the whole dataset
+	// (leave-one-out for OLS, out-of-bag for the random forest).
+	ValidationRMSE float64
+}
+
+// errorStats accumulates prediction errors.
+type errorStats struct {
+	n      int
+	absSum float64
+	sqSum  float64
+}
+
+func (e *errorStats) add(predicted, actual float64) {
+	diff := predicted - actual
+	e.n++
+	e.absSum += math.Abs(diff)
+	e.sqSum += diff * diff
+}
+
+func (e *errorStats) mae() float64 {
+	if e.n == 0 {
+		return 0
+	}
+	return e.absSum / float64(e.n)
+}
+
+func (e *errorStats) rmse() float64 {
+	if e.n == 0 {
+		return 0
+	}
+	return math.Sqrt(e.sqSum / float64(e.n))
+}
+
+// Backtest replays the stored history for each model configuration.
+// For each cutoff date the analyzer is fitted on the listings first seen
+// before the cutoff and scores the listings first seen during the following
+// interval. Listings without FirstSeen are only used for cross-validation.
+func Backtest(properties []models.Property, configs []ModelConfig, opts BacktestOptions) []BacktestResult {
+	results := make([]BacktestResult, len(configs))
+	for i, cfg := range configs {
+		a := NewAnalyzer(cfg.Options...)
+		results[i] = a.backtest(properties, opts)
+		results[i].Name = cfg.Name
+	}
+	return results
+}
+
+// backtest evaluates this analyzer against the stored history.
+func (a *Analyzer) backtest(properties []models.Property, opts BacktestOptions) BacktestResult {
+	var result BacktestResult
+
+	var dated []models.Property
+	var start, asOf time.Time
+	for _, p := range properties {
+		if p.FirstSeen.IsZero() {
+			continue
+		}
+		dated = append(dated, p)
+		if start.IsZero() || p.FirstSeen.Before(start) {
+			start = p.FirstSeen
+		}
+		if p.LastSeen.After(asOf) {
+			asOf = p.LastSeen
+		}
+	}
+
+	var regression, comparables errorStats
+	var bargains, bargainsQuick, baseline, baselineQuick int
+
+	if opts.Interval > 0 {
+		for cutoff := start.Add(opts.Interval); !cutoff.After(asOf); cutoff = cutoff.Add(opts.Interval) {
+			train, test := splitByFirstSeen(dated, cutoff, cutoff.Add(opts.Interval))
+			if len(test) == 0 {
+				continue
+			}
+
+			for _, s := range a.AnalyzeNewProperties(train, test) {
+				if s.Label == notifier.ScoreLabelAnalyzing {
+					continue
+				}
+				actual := s.Property.TotalRent()
+				regression.add(actual+s.Score, actual)
+				if s.Comparables != nil {
+					comparables.add(s.Comparables.Median, actual)
+				}
+
+				// Skip listings that could not yet have been delisted quickly
+				if s.Property.FirstSeen.Add(opts.QuickDelist).After(asOf) {
+					continue
+				}
+				quick := !s.Property.IsListed(asOf) &&
+					s.Property.LastSeen.Sub(s.Property.FirstSeen) <= opts.QuickDelist
+				baseline++
+				if quick {
+					baselineQuick++
+				}
+				if s.Label == notifier.ScoreLabelBargain {
+					bargains++
+					if quick {
+						bargainsQuick++
+					}
+				}
+			}
+		}
+	}
+
+	result.Scored = regression.n
+	result.MAE = regression.mae()
+	result.RMSE = regression.rmse()
+	result.ComparablesScored = comparables.n
+	result.ComparablesMAE = comparables.mae()
+	result.Bargains = bargains
+	result.BargainQuickDelistRate = rate(bargainsQuick, bargains)
+	result.BaselineQuickDelistRate = rate(baselineQuick, baseline)
+
+	if opts.Folds > 1 {
+		result.CVMAE, result.CVRMSE = a.CrossValidate(properties, opts.Folds)
+	}
+
+	if model, err := a.Fit(properties); err == nil {
+		result.ValidationRMSE = model.Metrics().ValidationRMSE
+	}
+
+	return result
+}
+
+// splitByFirstSeen returns the listings first seen before cutoff (training data)
+// and those first seen in [cutoff, end) (listings to score).
+func splitByFirstSeen(properties []models.Property, cutoff, end time.Time) (train, test []models.Property) {
+	for _, p := range properties {
+		switch {
+		case p.FirstSeen.Before(cutoff):
+			train = append(train, p)
+		case p.FirstSeen.Before(end):
+			test = append(test, p)
+		}
+	}
+	return train, test
+}
+
+// CrossValidate performs k-fold cross-validation of the rent prediction.
+// Listings are assigned to folds by their position (i mod k).
+// Returns the mean absolute error and root mean squared error in yen.
+func (a *Analyzer) CrossValidate(properties []models.Property, folds int) (mae, rmse float64) {
+	var stats errorStats
+	for fold := 0; fold < folds; fold++ {
+		var train, test []models.Property
+		for i, p := range properties {
+			if i%folds == fold {
+				test = append(test, p)
+			} else {
+				train = append(train, p)
+			}
+		}
+		if len(test) == 0 {
+			continue
+		}
+
+		for _, s := range a.AnalyzeNewProperties(train, test) {
+			if s.Label == notifier.ScoreLabelAnalyzing {
+				continue
+			}
+			actual := s.Property.TotalRent()
+			stats.add(actual+s.Score, actual)
+		}
+	}
+	return stats.mae(), stats.rmse()
+}
+
+// rate returns n/total, or 0 if total is 0.
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// WriteBacktestTable writes the backtest results as an aligned text table.
+func WriteBacktestTable(w io.Writer, results []BacktestResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "MODEL\tSCORED\tMAE\tRMSE\tCV_MAE\tCV_RMSE\tVAL_RMSE\tKNN_MAE\tBARGAINS\tBARGAIN_QUICK\tBASE_QUICK\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%.0f\t%.0f\t%.0f\t%.0f\t%.0f\t%.0f\t%d\t%.1f%%\t%.1f%%\t\n",
+			r.Name, r.Scored, r.MAE, r.RMSE, r.CVMAE, r.CVRMSE, r.ValidationRMSE, r.ComparablesMAE,
+			r.Bargains, r.BargainQuickDelistRate*100, r.BaselineQuickDelistRate*100)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+// generateHistory creates properties first seen one per day over n days.
+// Every listing stays listed for 30 days, except those with rent reduced by
+// 20000 yen (every 7th), which are delisted after 3 days.
+func generateHistory(n int) []models.Property {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	asOf := start.AddDate(0, 0, n+30)
+
+	properties := generateTestProperties(n)
+	for i := range properties {
+		properties[i].Address = "Tokyo " + string(rune('A'+i%26)) + string(rune('a'+i/26))
+		properties[i].FirstSeen = start.AddDate(0, 0, i)
+		properties[i].LastSeen = properties[i].FirstSeen.AddDate(0, 0, 30)
+		if i%7 == 6 {
+			properties[i].Rent -= 20000
+			properties[i].LastSeen = properties[i].FirstSeen.AddDate(0, 0, 3)
+		}
+		if properties[i].LastSeen.After(asOf) {
+			properties[i].LastSeen = asOf
+		}
+	}
+	return properties
+}
+
+func TestBacktest(t *testing.T) {
+	properties := generateHistory(60)
+	configs := []ModelConfig{
+		{Name: "ols+knn3", Options: []Option{WithComparablesK(3)}},
+		{Name: "ols", Options: []Option{WithComparablesK(0)}},
+	}
+
+	results := Backtest(properties, configs, DefaultBacktestOptions())
+
+	if len(results) != len(configs) {
+		t.Fatalf("Backtest() returned %d results, want %d", len(results), len(configs))
+	}
+
+	r := results[0]
+	if r.Name != "ols+knn3" {
+		t.Errorf("Name = %q, want %q", r.Name, "ols+knn3")
+	}
+	if r.Scored == 0 {
+		t.Fatal("Backtest() should score listings appearing after the first cutoff")
+	}
+	if r.Bargains == 0 {
+		t.Error("Backtest() should find bargains among discounted listings")
+	}
+	if r.BargainQuickDelistRate <= r.BaselineQuickDelistRate {
+		t.Errorf("Bargain quick delist rate %f should exceed baseline %f",
+			r.BargainQuickDelistRate, r.BaselineQuickDelistRate)
+	}
+	if r.CVMAE == 0 || r.CVRMSE < r.CVMAE {
+		t.Errorf("Unexpected cross-validation metrics: MAE=%f RMSE=%f", r.CVMAE, r.CVRMSE)
+	}
+
+	if results[1].ComparablesScored != 0 {
+		t.Errorf("ComparablesScored with k=0 = %d, want 0", results[1].ComparablesScored)
+	}
+}
+
+func TestBacktestWithoutHistory(t *testing.T) {
+	properties := generateTestProperties(20) // No FirstSeen/LastSeen
+
+	results := Backtest(properties, []ModelConfig{{Name: "ols"}}, DefaultBacktestOptions())
+
+	if results[0].Scored != 0 {
+		t.Errorf("Scored = %d, want 0 without history", results[0].Scored)
+	}
+	if results[0].CVMAE == 0 {
+		t.Error("Cross-validation should still run without history")
+	}
+}
+
+func TestCrossValidateExactModel(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	// Noise-free data is predicted exactly by the linear model
+	mae, rmse := analyzer.CrossValidate(generateTestProperties(50), 5)
+	if mae > 1 || rmse > 1 {
+		t.Errorf("CrossValidate() = (%f, %f), want ~0", mae, rmse)
+	}
+}
+
+func TestWriteBacktestTable(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteBacktestTable(&buf, []BacktestResult{
+		{Name: "ols", Scored: 10, MAE: 1234.4, Bargains: 2, BargainQuickDelistRate: 0.5},
+	})
+	if err != nil {
+		t.Fatalf("WriteBacktestTable() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"MODEL", "ols", "1234", "50.0%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteBacktestTable() output should contain %q, got %q", want, out)
+		}
+	}
+}
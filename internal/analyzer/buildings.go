@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"math"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+// DescribeBuildings adds the vacancies of the building to scored properties:
+// the number of units listed at asOf and their spread of rent per m².
+// A unit first listed at asOf in a building already known before asOf is a
+// new vacancy in that building; units listed before, also under another ID,
+// are not. Properties that are not a new vacancy and have no other vacancies
+// in their building are left unchanged.
+func (a *Analyzer) DescribeBuildings(scored []notifier.PropertyWithScore, history models.BuildingHistory, asOf time.Time) {
+	index := make(map[string]*models.Building, len(history.Buildings))
+	for i := range history.Buildings {
+		index[history.Buildings[i].Key] = &history.Buildings[i]
+	}
+
+	for i := range scored {
+		b, ok := index[models.BuildingKey(scored[i].Property)]
+		if !ok {
+			continue
+		}
+
+		unit, ok := b.Unit(scored[i].Property)
+		info := &notifier.BuildingInfo{
+			NewVacancy:     ok && b.FirstSeen.Before(asOf) && unit.FirstSeen.Equal(asOf),
+			MinRentPerArea: math.Inf(1),
+		}
+		for _, u := range b.Vacancies(asOf) {
+			info.Vacancies++
+			if v := u.RentPerArea(); v > 0 {
+				info.MinRentPerArea = math.Min(info.MinRentPerArea, v)
+				info.MaxRentPerArea = math.Max(info.MaxRentPerArea, v)
+			}
+		}
+		if math.IsInf(info.MinRentPerArea, 1) {
+			info.MinRentPerArea = 0
+		}
+
+		if info.NewVacancy || info.Vacancies > 1 {
+			scored[i].Building = info
+		}
+	}
+}
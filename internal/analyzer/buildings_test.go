@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+func TestDescribeBuildings(t *testing.T) {
+	analyzer := NewAnalyzer()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	unit := func(id string, floor int, rent float64) models.Property {
+		return models.Property{ID: id, Name: "テストマンション", Address: "中野区中野1-1", Floor: floor, Layout: "1K", Area: 25, Rent: rent}
+	}
+	lonely := models.Property{ID: "jnc_9", Name: "単独マンション", Address: "杉並区高円寺1-1", Area: 30, Rent: 90000}
+
+	history := models.UpdateBuildings(models.BuildingHistory{}, []models.Property{unit("jnc_1", 2, 75000), lonely}, now)
+	later := now.Add(24 * time.Hour)
+	current := []models.Property{unit("jnc_1", 2, 75000), unit("jnc_2", 3, 80000), lonely}
+	history = models.UpdateBuildings(history, current, later)
+
+	scored := notifier.ConvertToPropertyWithScore([]models.Property{current[1], lonely})
+	analyzer.DescribeBuildings(scored, history, later)
+
+	b := scored[0].Building
+	if b == nil {
+		t.Fatal("Building should be set for a new vacancy in a known building")
+	}
+	if !b.NewVacancy || b.Vacancies != 2 || b.MinRentPerArea != 3000 || b.MaxRentPerArea != 3200 {
+		t.Errorf("Building = %+v, want new vacancy with 2 vacancies at 3000-3200/m²", b)
+	}
+
+	if scored[1].Building != nil {
+		t.Errorf("Single vacancy listed before = %+v, want nil", scored[1].Building)
+	}
+
+	// A unit relisted under a new ID is not a new vacancy
+	relisted := unit("jnc_3", 2, 75000)
+	current = []models.Property{relisted, unit("jnc_2", 3, 80000), lonely}
+	evenLater := later.Add(24 * time.Hour)
+	history = models.UpdateBuildings(history, current, evenLater)
+	scored = notifier.ConvertToPropertyWithScore([]models.Property{relisted})
+	analyzer.DescribeBuildings(scored, history, evenLater)
+	if b := scored[0].Building; b == nil || b.NewVacancy || b.Vacancies != 2 {
+		t.Errorf("Relisted unit = %+v, want 2 vacancies without a new vacancy", b)
+	}
+
+	// Units still listed from earlier runs (notified again with NOTIFY_ALL)
+	// are not new vacancies either
+	scored = notifier.ConvertToPropertyWithScore(current)
+	analyzer.DescribeBuildings(scored, history, evenLater)
+	for _, s := range scored {
+		if s.Building != nil && s.Building.NewVacancy {
+			t.Errorf("%s = %+v, want no new vacancy", s.Property.ID, s.Building)
+		}
+	}
+	if scored[2].Building != nil {
+		t.Errorf("Single vacancy listed before = %+v, want nil", scored[2].Building)
+	}
+
+	// A building first seen in this run is not a new vacancy
+	fresh := models.UpdateBuildings(models.BuildingHistory{}, []models.Property{lonely}, later)
+	scored = notifier.ConvertToPropertyWithScore([]models.Property{lonely})
+	analyzer.DescribeBuildings(scored, fresh, later)
+	if scored[0].Building != nil {
+		t.Errorf("Single vacancy in a new building should not be described, got %+v", scored[0].Building)
+	}
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+const (
+	// DefaultComparablesK is the default number of comparable listings to use.
+	DefaultComparablesK = 5
+
+	// MinComparables is the minimum number of comparables required for an estimate.
+	MinComparables = 3
+)
+
+// Scales used to normalize feature differences in the similarity distance.
+// A difference of one scale unit adds 1.0 to the distance.
+const (
+	comparableAreaScale    = 5.0 // m²
+	comparableAgeScale     = 5.0 // years
+	comparableWalkScale    = 5.0 // minutes
+	comparableLayoutWeight = 1.0 // penalty for a different layout
+	comparableNeighborCost = 0.5 // penalty for a neighboring (not the same) station
+)
+
+// comparableCandidate is a candidate listing with its distance to the target.
+type comparableCandidate struct {
+	property models.Property
+	distance float64
+}
+
+// findComparables returns the k listings in pool most similar to target.
+// Candidates are restricted to the same station or its configured neighbors.
+// Returns nil if fewer than MinComparables candidates are found.
+func (a *Analyzer) findComparables(target models.Property, pool []models.Property) *notifier.ComparablesEstimate {
+	if a.comparablesK <= 0 {
+		return nil
+	}
+
+	targetKey := target.UniqueKey()
+	var candidates []comparableCandidate
+	for _, c := range pool {
+		if c.UniqueKey() == targetKey || c.TotalRent() <= 0 || c.Area <= 0 {
+			continue
+		}
+
+		stationCost, ok := a.stationDistance(target.NearestStation, c.NearestStation)
+		if !ok {
+			continue
+		}
+
+		distance := stationCost +
+			math.Abs(target.Area-c.Area)/comparableAreaScale +
+			math.Abs(float64(target.Age-c.Age))/comparableAgeScale +
+			math.Abs(float64(target.WalkMinutes-c.WalkMinutes))/comparableWalkScale
+		if target.Layout != c.Layout {
+			distance += comparableLayoutWeight
+		}
+
+		candidates = append(candidates, comparableCandidate{property: c, distance: distance})
+	}
+
+	if len(candidates) < MinComparables {
+		return nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	if len(candidates) > a.comparablesK {
+		candidates = candidates[:a.comparablesK]
+	}
+
+	comparables := make([]models.Property, len(candidates))
+	rents := make([]float64, len(candidates))
+	for i, c := range candidates {
+		comparables[i] = c.property
+		rents[i] = c.property.TotalRent()
+	}
+
+	return &notifier.ComparablesEstimate{
+		Median:     median(rents),
+		Properties: comparables,
+	}
+}
+
+// stationDistance returns the distance penalty between two stations and
+// whether a listing at station b may be used as a comparable for station a.
+func (a *Analyzer) stationDistance(stationA, stationB string) (float64, bool) {
+	if stationA == stationB {
+		return 0, true
+	}
+	for _, neighbor := range a.neighborStations[stationA] {
+		if neighbor == stationB {
+			return comparableNeighborCost, true
+		}
+	}
+	return 0, false
+}
+
+// median returns the median of values. The slice is sorted in place.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
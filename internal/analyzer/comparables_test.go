@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestFindComparables(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	target := models.Property{
+		Address: "target", NearestStation: "中野", Layout: "1K", Area: 25, Age: 10, WalkMinutes: 5,
+	}
+	pool := []models.Property{
+		target,
+		{Address: "a", NearestStation: "中野", Layout: "1K", Area: 25, Age: 10, WalkMinutes: 5, Rent: 90000, URL: "a"},
+		{Address: "b", NearestStation: "中野", Layout: "1K", Area: 26, Age: 12, WalkMinutes: 6, Rent: 92000, URL: "b"},
+		{Address: "c", NearestStation: "中野", Layout: "1K", Area: 24, Age: 8, WalkMinutes: 4, Rent: 94000, URL: "c"},
+		{Address: "d", NearestStation: "中野", Layout: "2LDK", Area: 60, Age: 30, WalkMinutes: 15, Rent: 150000, URL: "d"},
+		{Address: "e", NearestStation: "渋谷", Layout: "1K", Area: 25, Age: 10, WalkMinutes: 5, Rent: 120000, URL: "e"},
+	}
+
+	analyzer.comparablesK = 3
+	got := analyzer.findComparables(target, pool)
+	if got == nil {
+		t.Fatal("findComparables() returned nil")
+	}
+
+	if len(got.Properties) != 3 {
+		t.Fatalf("findComparables() returned %d comparables, want 3", len(got.Properties))
+	}
+	if got.Properties[0].URL != "a" {
+		t.Errorf("Most similar comparable = %q, want %q", got.Properties[0].URL, "a")
+	}
+	for _, c := range got.Properties {
+		if c.URL == "d" || c.URL == "e" {
+			t.Errorf("Unexpected comparable %q", c.URL)
+		}
+	}
+	if got.Median != 92000 {
+		t.Errorf("Median = %f, want 92000", got.Median)
+	}
+}
+
+func TestFindComparablesNeighborStations(t *testing.T) {
+	analyzer := NewAnalyzer(WithNeighborStations(map[string][]string{"中野": {"高円寺"}}))
+
+	target := models.Property{Address: "target", NearestStation: "高円寺", Layout: "1K", Area: 25}
+	pool := []models.Property{
+		{Address: "a", NearestStation: "中野", Layout: "1K", Area: 25, Rent: 90000},
+		{Address: "b", NearestStation: "中野", Layout: "1K", Area: 25, Rent: 91000},
+		{Address: "c", NearestStation: "中野", Layout: "1K", Area: 25, Rent: 92000},
+	}
+
+	got := analyzer.findComparables(target, pool)
+	if got == nil {
+		t.Fatal("findComparables() should use neighbor stations in both directions")
+	}
+}
+
+func TestFindComparablesInsufficient(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	target := models.Property{Address: "target", NearestStation: "中野", Area: 25}
+	pool := []models.Property{
+		{Address: "a", NearestStation: "中野", Area: 25, Rent: 90000},
+		{Address: "b", NearestStation: "渋谷", Area: 25, Rent: 90000},
+	}
+
+	if got := analyzer.findComparables(target, pool); got != nil {
+		t.Errorf("findComparables() = %+v, want nil", got)
+	}
+
+	disabled := NewAnalyzer(WithComparablesK(0))
+	if got := disabled.findComparables(target, generateTestProperties(20)); got != nil {
+		t.Errorf("findComparables() with k=0 = %+v, want nil", got)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	if got := median([]float64{3, 1, 2}); got != 2 {
+		t.Errorf("median(odd) = %f, want 2", got)
+	}
+	if got := median([]float64{4, 1, 3, 2}); got != 2.5 {
+		t.Errorf("median(even) = %f, want 2.5", got)
+	}
+	if got := median(nil); got != 0 {
+		t.Errorf("median(nil) = %f, want 0", got)
+	}
+}
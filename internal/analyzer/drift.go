@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"math"
+
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+// driftFeatures are the features whose coefficients and distributions are
+// compared for drift. Station dummies and seasonal terms are excluded because
+// their coefficients are noisy with few listings per station.
+var driftFeatures = []string{"area", "age", "floor", "walk_minutes"}
+
+// DriftThresholds configures when a difference between two model fits is
+// reported as drift. Zero disables the respective check.
+type DriftThresholds struct {
+	// Coefficient is the maximum relative change of a coefficient (0.5 = 50%).
+	Coefficient float64
+
+	// RMSE is the maximum relative change of the validation RMSE.
+	RMSE float64
+
+	// Distribution is the maximum shift of a feature mean, in standard
+	// deviations of the previous training data.
+	Distribution float64
+}
+
+// DefaultDriftThresholds returns the default drift thresholds.
+func DefaultDriftThresholds() DriftThresholds {
+	return DriftThresholds{
+		Coefficient:  0.5,
+		RMSE:         0.3,
+		Distribution: 1.0,
+	}
+}
+
+// DetectDrift compares a newly fitted model with the previously persisted one.
+// Features are matched by name, so a change in the station set doesn't
+// prevent the comparison. Distribution shifts require the previous snapshot
+// to include standard deviations.
+func DetectDrift(previous, current *ModelSnapshot, thresholds DriftThresholds) notifier.DriftReport {
+	report := notifier.DriftReport{
+		PreviousTrainedAt: previous.TrainedAt,
+		CurrentTrainedAt:  current.TrainedAt,
+	}
+
+	prevIndex := featureIndex(previous.Features)
+	currIndex := featureIndex(current.Features)
+
+	for _, name := range driftFeatures {
+		i, okPrev := prevIndex[name]
+		j, okCurr := currIndex[name]
+		if !okPrev || !okCurr {
+			continue
+		}
+
+		if thresholds.Coefficient > 0 && i < len(previous.Coefficients) && j < len(current.Coefficients) {
+			report.Items = append(report.Items, relativeDrift(notifier.DriftCoefficient, name,
+				previous.Coefficients[i], current.Coefficients[j], thresholds.Coefficient))
+		}
+
+		if thresholds.Distribution > 0 && i < len(previous.Stds) && i < len(previous.Means) && j < len(current.Means) {
+			item := notifier.DriftItem{
+				Kind:     notifier.DriftDistribution,
+				Feature:  name,
+				Previous: previous.Means[i],
+				Current:  current.Means[j],
+			}
+			diff := math.Abs(item.Current - item.Previous)
+			if previous.Stds[i] > 0 {
+				item.Shift = diff / previous.Stds[i]
+			} else if diff > 0 {
+				item.Shift = math.Inf(1) // Constant feature started to vary
+			}
+			item.Exceeded = item.Shift > thresholds.Distribution
+			report.Items = append(report.Items, item)
+		}
+	}
+
+	if thresholds.RMSE > 0 {
+		report.Items = append(report.Items, relativeDrift(notifier.DriftRMSE, "",
+			previous.Metrics.ValidationRMSE, current.Metrics.ValidationRMSE, thresholds.RMSE))
+	}
+
+	return report
+}
+
+// relativeDrift compares two values by their relative change.
+func relativeDrift(kind notifier.DriftKind, feature string, previous, current, threshold float64) notifier.DriftItem {
+	item := notifier.DriftItem{
+		Kind:     kind,
+		Feature:  feature,
+		Previous: previous,
+		Current:  current,
+	}
+	if previous != 0 {
+		item.Shift = (current - previous) / math.Abs(previous)
+	} else if current != 0 {
+		item.Shift = math.Inf(1)
+	}
+	item.Exceeded = math.Abs(item.Shift) > threshold
+	return item
+}
+
+// featureIndex maps feature names to their column index.
+func featureIndex(features []string) map[string]int {
+	index := make(map[string]int, len(features))
+	for i, name := range features {
+		index[name] = i
+	}
+	return index
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+func TestDetectDrift(t *testing.T) {
+	_, previous := fitTestSnapshot(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	t.Run("same model", func(t *testing.T) {
+		report := DetectDrift(previous, previous, DefaultDriftThresholds())
+		if report.Drifted() {
+			t.Errorf("DetectDrift() of identical models drifted: %+v", report.Items)
+		}
+		// 4 coefficients + 4 distributions + RMSE
+		if len(report.Items) != 9 {
+			t.Errorf("Expected 9 items, got %d", len(report.Items))
+		}
+	})
+
+	t.Run("broken area parsing", func(t *testing.T) {
+		current := *previous
+		current.Coefficients = append([]float64{}, previous.Coefficients...)
+		current.Means = append([]float64{}, previous.Means...)
+		current.Coefficients[1] *= 0.1 // area
+		current.Means[1] += 3 * previous.Stds[1]
+		current.Metrics.ValidationRMSE = previous.Metrics.ValidationRMSE * 2
+
+		report := DetectDrift(previous, &current, DefaultDriftThresholds())
+		if !report.Drifted() {
+			t.Fatal("DetectDrift() should detect drift")
+		}
+
+		exceeded := make(map[notifier.DriftKind]string)
+		for _, item := range report.Items {
+			if item.Exceeded {
+				exceeded[item.Kind] = item.Feature
+			}
+		}
+		if exceeded[notifier.DriftCoefficient] != "area" || exceeded[notifier.DriftDistribution] != "area" {
+			t.Errorf("Expected area coefficient and distribution drift, got %v", exceeded)
+		}
+		if _, ok := exceeded[notifier.DriftRMSE]; !ok {
+			t.Error("Expected RMSE drift")
+		}
+	})
+
+	t.Run("disabled checks", func(t *testing.T) {
+		current := *previous
+		current.Metrics.ValidationRMSE = previous.Metrics.ValidationRMSE * 2
+		report := DetectDrift(previous, &current, DriftThresholds{})
+		if len(report.Items) != 0 {
+			t.Errorf("Expected no items with zero thresholds, got %d", len(report.Items))
+		}
+	})
+
+	t.Run("snapshot without stds", func(t *testing.T) {
+		old := *previous
+		old.Stds = nil
+		for _, item := range DetectDrift(&old, previous, DefaultDriftThresholds()).Items {
+			if item.Kind == notifier.DriftDistribution {
+				t.Error("Distribution drift should be skipped without stds")
+			}
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+const (
+	// DefaultDuplicateAreaTolerance is the default maximum area difference (m²)
+	// between listings of the same unit.
+	DefaultDuplicateAreaTolerance = 1.0
+
+	// DefaultReappearanceGap is the default minimum absence after which a unit
+	// listed again counts as a reappearance.
+	DefaultReappearanceGap = 7 * 24 * time.Hour
+
+	// ClusterRetention is how long clusters are kept after the unit was last listed.
+	ClusterRetention = 365 * 24 * time.Hour
+
+	// BaitDiscount is the minimum discount versus the other listings of the
+	// same unit for a bargain to be flagged as suspected bait.
+	BaitDiscount = 0.1
+
+	// MinReappearances is the number of reappearances from which a unit is flagged.
+	MinReappearances = 2
+)
+
+// WithDuplicateAreaTolerance sets the maximum area difference (m²) between
+// listings of the same unit.
+func WithDuplicateAreaTolerance(tolerance float64) Option {
+	return func(a *Analyzer) {
+		a.duplicateAreaTolerance = tolerance
+	}
+}
+
+// WithReappearanceGap sets the minimum absence after which a unit listed
+// again counts as a reappearance.
+func WithReappearanceGap(gap time.Duration) Option {
+	return func(a *Analyzer) {
+		a.reappearanceGap = gap
+	}
+}
+
+// UpdateClusters assigns the currently listed properties to clusters of
+// near-duplicate listings and returns the updated history.
+//
+// Listings belong to the same unit if they share the building (normalized
+// address or name), floor and layout, and their areas differ by at most the
+// area tolerance. A unit that was not listed for longer than the reappearance
+// gap starts a new episode. Clusters not listed within ClusterRetention are dropped.
+func (a *Analyzer) UpdateClusters(history models.ClusterHistory, current []models.Property, now time.Time) models.ClusterHistory {
+	clusters := make([]models.ListingCluster, 0, len(history.Clusters))
+	for _, c := range history.Clusters {
+		if now.Sub(c.LastSeen()) <= ClusterRetention {
+			clusters = append(clusters, c)
+		}
+	}
+
+	// Candidate clusters by floor and layout
+	candidates := make(map[string][]int)
+	unitKey := func(floor int, layout string) string {
+		return fmt.Sprintf("%d|%s", floor, models.NormalizeText(layout))
+	}
+	for i, c := range clusters {
+		k := unitKey(c.Floor, c.Layout)
+		candidates[k] = append(candidates[k], i)
+	}
+
+	updated := make(map[int]bool)
+	for _, p := range current {
+		k := unitKey(p.Floor, p.Layout)
+		i := -1
+		for _, j := range candidates[k] {
+			if a.sameUnit(&clusters[j], p) {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			clusters = append(clusters, models.ListingCluster{
+				Key:     fmt.Sprintf("%s|%s", models.NormalizeText(p.Address), p.ID),
+				Name:    models.NormalizeText(p.Name),
+				Address: models.NormalizeText(p.Address),
+				Floor:   p.Floor,
+				Layout:  models.NormalizeText(p.Layout),
+				Area:    p.Area,
+			})
+			i = len(clusters) - 1
+			candidates[k] = append(candidates[k], i)
+		}
+
+		c := &clusters[i]
+		upsertListing(c, p, now)
+		if !updated[i] {
+			a.extendEpisodes(c, now)
+			updated[i] = true
+		}
+	}
+
+	return models.ClusterHistory{UpdatedAt: now, Clusters: clusters}
+}
+
+// sameUnit reports whether the property is a listing of the cluster's unit.
+// The floor and layout are already matched by the caller.
+func (a *Analyzer) sameUnit(c *models.ListingCluster, p models.Property) bool {
+	tolerance := a.duplicateAreaTolerance
+	if tolerance <= 0 {
+		tolerance = DefaultDuplicateAreaTolerance
+	}
+	if math.Abs(c.Area-p.Area) > tolerance {
+		return false
+	}
+
+	address := models.NormalizeText(p.Address)
+	name := models.NormalizeText(p.Name)
+	return (address != "" && address == c.Address) || (name != "" && name == c.Name)
+}
+
+// upsertListing records that the property was listed at now.
+func upsertListing(c *models.ListingCluster, p models.Property, now time.Time) {
+	for i := range c.Listings {
+		if c.Listings[i].ID == p.ID {
+			c.Listings[i].Name = p.Name
+			c.Listings[i].URL = p.URL
+			c.Listings[i].TotalRent = p.TotalRent()
+			c.Listings[i].LastSeen = now
+			return
+		}
+	}
+	c.Listings = append(c.Listings, models.ClusterListing{
+		ID:        p.ID,
+		Name:      p.Name,
+		URL:       p.URL,
+		TotalRent: p.TotalRent(),
+		FirstSeen: now,
+		LastSeen:  now,
+	})
+}
+
+// extendEpisodes extends the current episode of the cluster to now, or starts
+// a new one if the unit was not listed for longer than the reappearance gap.
+func (a *Analyzer) extendEpisodes(c *models.ListingCluster, now time.Time) {
+	gap := a.reappearanceGap
+	if gap <= 0 {
+		gap = DefaultReappearanceGap
+	}
+
+	if n := len(c.Episodes); n > 0 && now.Sub(c.Episodes[n-1].End) <= gap {
+		c.Episodes[n-1].End = now
+		return
+	}
+	c.Episodes = append(c.Episodes, models.SeenPeriod{Start: now, End: now})
+}
+
+// FlagDuplicates adds duplicate listing information to scored properties.
+// A listing is suspected bait if it is a bargain according to the model and
+// at least BaitDiscount cheaper than the median of the other listings of
+// the same unit. Units that reappeared at least MinReappearances times are
+// flagged as well.
+func (a *Analyzer) FlagDuplicates(scored []notifier.PropertyWithScore, history models.ClusterHistory) {
+	byID := make(map[string]*models.ListingCluster)
+	for i := range history.Clusters {
+		for _, l := range history.Clusters[i].Listings {
+			byID[l.ID] = &history.Clusters[i]
+		}
+	}
+
+	for i := range scored {
+		p := scored[i].Property
+		c, ok := byID[p.ID]
+		if !ok {
+			continue
+		}
+
+		var others []float64
+		for _, l := range c.Listings {
+			if l.ID != p.ID && l.TotalRent > 0 {
+				others = append(others, l.TotalRent)
+			}
+		}
+
+		info := &notifier.DuplicateInfo{
+			Listings:      len(c.Listings),
+			Reappearances: c.Reappearances(),
+			Reappearing:   c.Reappearances() >= MinReappearances,
+		}
+		if len(others) > 0 {
+			info.OthersMedian = median(others)
+			info.SuspectedBait = scored[i].Label == notifier.ScoreLabelBargain &&
+				p.TotalRent() <= info.OthersMedian*(1-BaitDiscount)
+		}
+		if info.Listings <= 1 && !info.Reappearing {
+			continue
+		}
+		scored[i].Duplicates = info
+	}
+}
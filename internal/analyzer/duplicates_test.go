@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+func duplicateListing(id, name string, area, rent float64) models.Property {
+	return models.Property{
+		ID:      id,
+		Name:    name,
+		Address: "東京都中野区中野１丁目",
+		Floor:   3,
+		Layout:  "1K",
+		Area:    area,
+		Rent:    rent,
+	}
+}
+
+func TestUpdateClusters(t *testing.T) {
+	analyzer := NewAnalyzer()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	current := []models.Property{
+		duplicateListing("jnc_1", "パークハウス中野", 25.0, 90000),
+		duplicateListing("jnc_2", "パークハウス中野 3階", 25.4, 88000), // Same unit, other agency
+		duplicateListing("jnc_3", "パークハウス中野", 30.0, 99000),    // Different area
+	}
+	other := duplicateListing("jnc_4", "パークハウス中野", 25.0, 90000)
+	other.Floor = 4 // Different floor
+	current = append(current, other)
+
+	history := analyzer.UpdateClusters(models.ClusterHistory{}, current, now)
+	if len(history.Clusters) != 3 {
+		t.Fatalf("Expected 3 clusters, got %d", len(history.Clusters))
+	}
+	if n := len(history.Clusters[0].Listings); n != 2 {
+		t.Errorf("Expected 2 listings in the first cluster, got %d", n)
+	}
+
+	// Listed again within the gap: same episode
+	history = analyzer.UpdateClusters(history, current[:1], now.Add(24*time.Hour))
+	if r := history.Clusters[0].Reappearances(); r != 0 {
+		t.Errorf("Reappearances = %d, want 0", r)
+	}
+
+	// Listed again after the gap under a new ID: reappearance
+	relisted := duplicateListing("jnc_5", "パークハウス中野", 25.0, 90000)
+	history = analyzer.UpdateClusters(history, []models.Property{relisted}, now.Add(30*24*time.Hour))
+	c := history.Clusters[0]
+	if c.Reappearances() != 1 || len(c.Listings) != 3 {
+		t.Errorf("Cluster = (reappearances %d, listings %d), want (1, 3)", c.Reappearances(), len(c.Listings))
+	}
+
+	// Clusters not listed within the retention period are dropped
+	history = analyzer.UpdateClusters(history, nil, now.Add(2*ClusterRetention))
+	if len(history.Clusters) != 0 {
+		t.Errorf("Expected expired clusters to be dropped, got %d", len(history.Clusters))
+	}
+}
+
+func TestFlagDuplicates(t *testing.T) {
+	analyzer := NewAnalyzer()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	bait := duplicateListing("jnc_1", "パークハウス中野", 25.0, 75000)
+	current := []models.Property{
+		bait,
+		duplicateListing("jnc_2", "パークハウス中野", 25.0, 90000),
+		duplicateListing("jnc_3", "パークハウス中野", 25.2, 92000),
+	}
+	lonely := duplicateListing("jnc_9", "別の建物", 40.0, 120000)
+	lonely.Address = "東京都杉並区高円寺南"
+	current = append(current, lonely)
+
+	history := analyzer.UpdateClusters(models.ClusterHistory{}, current, now)
+	// Reappear twice
+	for i := 1; i <= 2; i++ {
+		history = analyzer.UpdateClusters(history, current[:1], now.Add(time.Duration(i)*30*24*time.Hour))
+	}
+
+	scored := []notifier.PropertyWithScore{
+		{Property: bait, Score: 15000, Label: notifier.ScoreLabelBargain},
+		{Property: current[1], Score: 0, Label: notifier.ScoreLabelStandard},
+		{Property: lonely, Score: 15000, Label: notifier.ScoreLabelBargain},
+	}
+	analyzer.FlagDuplicates(scored, history)
+
+	d := scored[0].Duplicates
+	if d == nil {
+		t.Fatal("Duplicates should be set for a listing with other listings")
+	}
+	if d.Listings != 3 || d.OthersMedian != 91000 {
+		t.Errorf("Duplicates = %+v, want 3 listings with median 91000", d)
+	}
+	if !d.SuspectedBait || !d.Reappearing || d.Reappearances != 2 {
+		t.Errorf("Duplicates = %+v, want suspected bait reappearing twice", d)
+	}
+
+	if scored[1].Duplicates == nil || scored[1].Duplicates.SuspectedBait {
+		t.Errorf("Standard listing should not be flagged as bait: %+v", scored[1].Duplicates)
+	}
+	if scored[2].Duplicates != nil {
+		t.Errorf("Unique listing should have no duplicates, got %+v", scored[2].Duplicates)
+	}
+}
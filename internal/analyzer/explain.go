@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+// Contribution names for grouped columns.
+const (
+	stationFeatureName  = "駅"  // All station dummies
+	seasonalFeatureName = "季節" // Month-of-year terms
+)
+
+// explain breaks the predicted rent of a property down into per-feature
+// contributions relative to the training data average.
+// For a linear model the contribution of feature j is β_j × (x_j − mean_j),
+// so the contributions sum to (predicted − average prediction).
+// Each registered feature (e.g., all station dummies or the columns of a
+// transformed numeric feature) and the month-of-year terms are combined into
+// a single contribution, named by the feature registry.
+// Contributions are ordered by absolute amount, largest first.
+func (a *Analyzer) explain(p models.Property, model *regressionModel) []notifier.Contribution {
+	if len(model.means) != len(model.coefficients) {
+		return nil
+	}
+
+	row := model.designRow(p)
+	contribution := func(from, to int) float64 {
+		sum := 0.0
+		for j := from; j < to; j++ {
+			sum += model.coefficients[j] * (row[j] - model.means[j])
+		}
+		return sum
+	}
+
+	spans := model.spans()
+	contributions := make([]notifier.Contribution, 0, len(spans)+1)
+	for _, span := range spans {
+		if span.to > span.from {
+			contributions = append(contributions, notifier.Contribution{
+				Name:   span.feature.Label,
+				Amount: contribution(span.from, span.to),
+			})
+		}
+	}
+
+	if model.seasonal {
+		contributions = append(contributions, notifier.Contribution{
+			Name:   seasonalFeatureName,
+			Amount: contribution(model.featureColumns(), model.numFeatures()),
+		})
+	}
+
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return math.Abs(contributions[i].Amount) > math.Abs(contributions[j].Amount)
+	})
+
+	return contributions
+}
@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestExplainSumsToDeviationFromAverage(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	properties := generateTestProperties(30)
+	stations := []string{"中野", "高円寺", "阿佐ヶ谷"}
+	for i := range properties {
+		properties[i].NearestStation = stations[i%len(stations)]
+		properties[i].Rent += float64(i%len(stations)) * 5000
+	}
+
+	model, err := analyzer.fitRegression(properties)
+	if err != nil {
+		t.Fatalf("fitRegression failed: %v", err)
+	}
+
+	averagePrediction := 0.0
+	for _, p := range properties {
+		averagePrediction += analyzer.predict(p, model)
+	}
+	averagePrediction /= float64(len(properties))
+
+	target := properties[7]
+	contributions := analyzer.explain(target, model)
+
+	if len(contributions) != BaseFeatureCount {
+		t.Fatalf("explain() returned %d contributions, want %d", len(contributions), BaseFeatureCount)
+	}
+
+	sum := 0.0
+	for i, c := range contributions {
+		sum += c.Amount
+		if i > 0 && math.Abs(c.Amount) > math.Abs(contributions[i-1].Amount) {
+			t.Errorf("Contributions should be ordered by absolute amount: %+v", contributions)
+		}
+	}
+
+	want := analyzer.predict(target, model) - averagePrediction
+	if math.Abs(sum-want) > 0.01 {
+		t.Errorf("Sum of contributions = %f, want %f", sum, want)
+	}
+}
+
+func TestExplainWithoutMeans(t *testing.T) {
+	analyzer := NewAnalyzer()
+	model := &regressionModel{
+		coefficients: []float64{50000, 2000, -500, 1000, -500},
+	}
+
+	if got := analyzer.explain(models.Property{Area: 25}, model); got != nil {
+		t.Errorf("explain() without means = %+v, want nil", got)
+	}
+}
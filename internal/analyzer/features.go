@@ -0,0 +1,272 @@
+package analyzer
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+// FeatureEncoding selects how a feature is encoded in the design matrix.
+type FeatureEncoding int
+
+const (
+	// EncodingNumeric is a numeric value, optionally expanded by a FeatureTransform.
+	EncodingNumeric FeatureEncoding = iota
+
+	// EncodingBinary is a 0/1 indicator.
+	EncodingBinary
+
+	// EncodingCategorical is one dummy column per level, with the first
+	// level (in sorted order) as the reference category.
+	EncodingCategorical
+)
+
+// MinFeatureCount is the minimum number of training listings for a binary
+// feature value or a categorical level to get its own column.
+// Rarer values are folded into the reference category to keep the fit stable.
+const MinFeatureCount = 3
+
+// Feature is a property attribute used by the linear model. Features are
+// declared once in the registry and used consistently in fitting,
+// prediction, explanations and the market index.
+type Feature struct {
+	Name     string // Name used in transforms and the feature schema
+	Label    string // Contribution name in explanations
+	Encoding FeatureEncoding
+
+	// Value extracts numeric and binary features (0 or 1 for binary).
+	Value func(p models.Property) float64
+
+	// Category extracts categorical features. Empty means unknown
+	// (treated as the reference category).
+	Category func(p models.Property) string
+
+	// MinCount overrides MinFeatureCount for the feature (e.g., 1 for stations).
+	MinCount int
+
+	// OptIn marks features only used when enabled on the Analyzer, as they
+	// change every score (e.g., the layout; see WithLayoutFeature).
+	OptIn bool
+
+	// Location marks features describing where the listing is. They are
+	// not adjusted away in the market index.
+	Location bool
+}
+
+// featureRegistry lists the features of the linear model in design matrix order.
+// Numeric features are always included; binary and categorical features only
+// when they vary in the training data, and opt-in features only when enabled.
+var featureRegistry = []Feature{
+	{Name: "area", Label: "面積", Encoding: EncodingNumeric, Value: func(p models.Property) float64 { return p.Area }},
+	{Name: "age", Label: "築年", Encoding: EncodingNumeric, Value: func(p models.Property) float64 { return float64(p.Age) }},
+	{Name: "floor", Label: "階数", Encoding: EncodingNumeric, Value: func(p models.Property) float64 { return float64(p.Floor) }},
+	{Name: "walk_minutes", Label: "徒歩", Encoding: EncodingNumeric, Value: func(p models.Property) float64 { return float64(p.WalkMinutes) }},
+	{Name: "auto_lock", Label: "オートロック", Encoding: EncodingBinary, Value: func(p models.Property) float64 { return indicator(p.AutoLock) }},
+	{Name: "separate_bath", Label: "バス・トイレ別", Encoding: EncodingBinary, Value: func(p models.Property) float64 { return indicator(p.SeparateBath) }},
+	{Name: "indoor_washer", Label: "室内洗濯機置場", Encoding: EncodingBinary, Value: func(p models.Property) float64 { return indicator(p.IndoorWasher) }},
+	{Name: "corner_unit", Label: "角部屋", Encoding: EncodingBinary, Value: func(p models.Property) float64 { return indicator(p.CornerUnit) }},
+	{Name: "structure", Label: "構造", Encoding: EncodingCategorical, Category: func(p models.Property) string { return structureClass(p.Structure) }},
+	{Name: "layout", Label: "間取り", Encoding: EncodingCategorical, Category: func(p models.Property) string { return strings.TrimSpace(p.Layout) }, OptIn: true},
+	{Name: "station", Label: stationFeatureName, Encoding: EncodingCategorical, Category: func(p models.Property) string { return p.NearestStation }, MinCount: 1, Location: true},
+}
+
+// lookupFeature returns the registered feature with the name, or nil.
+func lookupFeature(name string) *Feature {
+	for i := range featureRegistry {
+		if featureRegistry[i].Name == name {
+			return &featureRegistry[i]
+		}
+	}
+	return nil
+}
+
+// defaultFeatures are the features of a model that doesn't list them (numeric features only).
+var defaultFeatures = func() []string {
+	var names []string
+	for _, f := range featureRegistry {
+		if f.Encoding == EncodingNumeric {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}()
+
+// indicator converts a bool to a 0/1 value.
+func indicator(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// structureClass groups building structures as SUUMO lists them
+// ("鉄筋コン", "鉄骨鉄筋", "木造", "軽量鉄骨", ...) into RC, steel and wooden.
+func structureClass(structure string) string {
+	switch {
+	case strings.Contains(structure, "鉄筋"), strings.Contains(structure, "RC"):
+		return "RC"
+	case strings.Contains(structure, "鉄骨"):
+		return "鉄骨"
+	case strings.Contains(structure, "木"):
+		return "木造"
+	default:
+		return ""
+	}
+}
+
+// minCount returns the minimum number of listings for a value of the feature.
+func (f *Feature) minCount() int {
+	if f.MinCount > 0 {
+		return f.MinCount
+	}
+	return MinFeatureCount
+}
+
+// selectFeatures chooses the features of a model fitted on the properties and
+// the dummy levels of its categorical features. Opt-in features are only
+// considered if they are listed in optIn.
+func selectFeatures(properties []models.Property, optIn []string) (features []string, levels map[string][]string) {
+	levels = make(map[string][]string)
+
+	for i := range featureRegistry {
+		f := &featureRegistry[i]
+		if f.OptIn && !slices.Contains(optIn, f.Name) {
+			continue
+		}
+		switch f.Encoding {
+		case EncodingNumeric:
+			features = append(features, f.Name)
+
+		case EncodingBinary:
+			ones := 0
+			for _, p := range properties {
+				if f.Value(p) != 0 {
+					ones++
+				}
+			}
+			if ones >= f.minCount() && len(properties)-ones >= f.minCount() {
+				features = append(features, f.Name)
+			}
+
+		case EncodingCategorical:
+			counts := make(map[string]int)
+			for _, p := range properties {
+				if c := f.Category(p); c != "" {
+					counts[c]++
+				}
+			}
+			var frequent []string
+			for c, n := range counts {
+				if n >= f.minCount() {
+					frequent = append(frequent, c)
+				}
+			}
+			if len(frequent) <= 1 {
+				continue
+			}
+			// The first level is the reference category, excluded to avoid multicollinearity
+			sort.Strings(frequent)
+			features = append(features, f.Name)
+			levels[f.Name] = frequent[1:]
+		}
+	}
+
+	return features, levels
+}
+
+// featureSpan is the range of design matrix columns [from, to) of a feature.
+type featureSpan struct {
+	feature  *Feature
+	from, to int
+}
+
+// featureList returns the registered features of the model in design matrix order.
+func (m *regressionModel) featureList() []string {
+	if m.features == nil {
+		return defaultFeatures
+	}
+	return m.features
+}
+
+// spans returns the design matrix columns of each feature of the model, after the intercept.
+func (m *regressionModel) spans() []featureSpan {
+	names := m.featureList()
+	spans := make([]featureSpan, 0, len(names))
+	j := 1 // After the intercept
+	for _, name := range names {
+		f := lookupFeature(name)
+		width := 1
+		switch f.Encoding {
+		case EncodingNumeric:
+			width = m.transform(name).columns()
+		case EncodingCategorical:
+			width = len(m.levels[name])
+		}
+		spans = append(spans, featureSpan{feature: f, from: j, to: j + width})
+		j += width
+	}
+	return spans
+}
+
+// featureColumns returns the number of feature columns including the intercept
+// (the offset of the seasonal terms).
+func (m *regressionModel) featureColumns() int {
+	spans := m.spans()
+	if len(spans) == 0 {
+		return 1
+	}
+	return spans[len(spans)-1].to
+}
+
+// encode writes the columns of a feature for a property into dst.
+func (m *regressionModel) encode(f *Feature, p models.Property, dst []float64) {
+	switch f.Encoding {
+	case EncodingNumeric:
+		m.transform(f.Name).expand(f.Value(p), dst)
+	case EncodingBinary:
+		dst[0] = f.Value(p)
+	case EncodingCategorical:
+		// Reference, rare and unknown levels leave all dummies at 0
+		if idx, ok := m.levelIndex[f.Name][f.Category(p)]; ok {
+			dst[idx] = 1
+		}
+	}
+}
+
+// columnNames returns the design matrix column names of a feature.
+func (m *regressionModel) columnNames(f *Feature) []string {
+	switch f.Encoding {
+	case EncodingNumeric:
+		return m.transform(f.Name).names(f.Name)
+	case EncodingCategorical:
+		names := make([]string, len(m.levels[f.Name]))
+		for i, level := range m.levels[f.Name] {
+			names[i] = f.Name + ":" + level
+		}
+		return names
+	default:
+		return []string{f.Name}
+	}
+}
+
+// transform returns the transform of the named feature (linear if unset).
+func (m *regressionModel) transform(name string) FeatureTransform {
+	if t, ok := m.transforms[name]; ok {
+		return t
+	}
+	return FeatureTransform{Kind: TransformLinear}
+}
+
+// buildLevelIndex creates a mapping from each categorical level to its dummy column.
+func buildLevelIndex(levels map[string][]string) map[string]map[string]int {
+	index := make(map[string]map[string]int, len(levels))
+	for name, values := range levels {
+		index[name] = make(map[string]int, len(values))
+		for i, v := range values {
+			index[name][v] = i
+		}
+	}
+	return index
+}
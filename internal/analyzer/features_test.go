@@ -0,0 +1,191 @@
+package analyzer
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStructureClass(t *testing.T) {
+	tests := map[string]string{
+		"鉄筋コン": "RC",
+		"鉄骨鉄筋": "RC",
+		"RC造":  "RC",
+		"軽量鉄骨": "鉄骨",
+		"木造":   "木造",
+		"ブロック": "",
+		"":     "",
+	}
+	for structure, want := range tests {
+		if got := structureClass(structure); got != want {
+			t.Errorf("structureClass(%q) = %q, want %q", structure, got, want)
+		}
+	}
+}
+
+func TestSelectFeatures(t *testing.T) {
+	properties := generateTestProperties(20)
+	for i := range properties {
+		properties[i].AutoLock = i%2 == 0 // Varies: included
+		properties[i].CornerUnit = i == 0 // Only one listing: excluded
+		properties[i].Layout = []string{"1K", "1DK", "1LDK"}[i%3]
+		properties[i].NearestStation = "中野"
+	}
+	properties[1].Layout = "2LDK" // Rare layout folded into the reference
+
+	features, _ := selectFeatures(properties, nil)
+	want := []string{"area", "age", "floor", "walk_minutes", "auto_lock"}
+	if !slices.Equal(features, want) {
+		t.Errorf("selectFeatures() features = %v, want %v (layout is opt-in)", features, want)
+	}
+
+	features, levels := selectFeatures(properties, []string{"layout"})
+	want = append(want, "layout")
+	if !slices.Equal(features, want) {
+		t.Errorf("selectFeatures(layout) features = %v, want %v", features, want)
+	}
+	if !slices.Equal(levels["layout"], []string{"1K", "1LDK"}) {
+		t.Errorf("layout levels = %v, want [1K 1LDK] (1DK is the reference)", levels["layout"])
+	}
+	if _, ok := levels["station"]; ok {
+		t.Error("A single station should not get dummies")
+	}
+}
+
+func TestFacilityFeaturesFit(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	properties := generateTestProperties(60)
+	for i := range properties {
+		properties[i].AutoLock = (i/3)%2 == 0
+		properties[i].Structure = []string{"鉄筋コン", "木造"}[(i/7)%2]
+		if properties[i].AutoLock {
+			properties[i].Rent += 5000
+		}
+		if properties[i].Structure == "木造" {
+			properties[i].Rent -= 3000
+		}
+	}
+
+	model, err := analyzer.fitRegression(properties)
+	if err != nil {
+		t.Fatalf("fitRegression failed: %v", err)
+	}
+
+	names := model.featureNames()
+	coefficient := func(name string) float64 {
+		i := slices.Index(names, name)
+		if i < 0 {
+			t.Fatalf("Feature %q not in model: %v", name, names)
+		}
+		return model.coefficients[i]
+	}
+	if c := coefficient("auto_lock"); math.Abs(c-5000) > 1 {
+		t.Errorf("auto_lock coefficient = %.0f, want 5000", c)
+	}
+	if c := coefficient("structure:木造"); math.Abs(c+3000) > 1 {
+		t.Errorf("structure:木造 coefficient = %.0f, want -3000", c)
+	}
+
+	// Explanations use the registry labels
+	var labels []string
+	for _, c := range analyzer.explain(properties[0], model) {
+		labels = append(labels, c.Name)
+	}
+	for _, want := range []string{"オートロック", "構造"} {
+		if !slices.Contains(labels, want) {
+			t.Errorf("explain() labels = %v, should contain %q", labels, want)
+		}
+	}
+
+	// Facilities are adjusted away in the market index
+	withLock, withoutLock := properties[0], properties[0]
+	withoutLock.AutoLock = false
+	withoutLock.Rent -= 5000
+	if d := analyzer.adjustedRent(withLock, model) - analyzer.adjustedRent(withoutLock, model); math.Abs(d) > 1 {
+		t.Errorf("Adjusted rents differ by %.0f, want 0", d)
+	}
+
+	// Levels are persisted with the model
+	snapshot, err := NewSnapshot(model, time.Now())
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	restored, err := snapshot.Restore()
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	for _, p := range properties[:10] {
+		if restored.Predict(p) != model.Predict(p) {
+			t.Errorf("Restored prediction = %f, want %f", restored.Predict(p), model.Predict(p))
+		}
+	}
+}
+
+func TestCategoricalFeatureFit(t *testing.T) {
+	analyzer := NewAnalyzer(WithLayoutFeature(true))
+
+	properties := generateTestProperties(60)
+	for i := range properties {
+		properties[i].Layout = []string{"1K", "1LDK"}[(i/3)%2]
+		if properties[i].Layout == "1LDK" {
+			properties[i].Rent += 5000
+		}
+	}
+
+	model, err := analyzer.fitRegression(properties)
+	if err != nil {
+		t.Fatalf("fitRegression failed: %v", err)
+	}
+
+	names := model.featureNames()
+	i := slices.Index(names, "layout:1LDK")
+	if i < 0 {
+		t.Fatalf("layout:1LDK not in model: %v", names)
+	}
+	if c := model.coefficients[i]; math.Abs(c-5000) > 1 {
+		t.Errorf("layout:1LDK coefficient = %.0f, want 5000", c)
+	}
+
+	// Explanations use the registry labels
+	var labels []string
+	for _, c := range analyzer.explain(properties[0], model) {
+		labels = append(labels, c.Name)
+	}
+	if !slices.Contains(labels, "間取り") {
+		t.Errorf("explain() labels = %v, should contain 間取り", labels)
+	}
+
+	// Layouts are adjusted away in the market index
+	larger, smaller := properties[0], properties[0]
+	larger.Layout = "1LDK"
+	larger.Rent += 5000
+	smaller.Layout = "1K"
+	if d := analyzer.adjustedRent(larger, model) - analyzer.adjustedRent(smaller, model); math.Abs(d) > 1 {
+		t.Errorf("Adjusted rents differ by %.0f, want 0", d)
+	}
+
+	// Levels are persisted with the model
+	snapshot, err := NewSnapshot(model, time.Now())
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	restored, err := snapshot.Restore()
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	for _, p := range properties[:10] {
+		if restored.Predict(p) != model.Predict(p) {
+			t.Errorf("Restored prediction = %f, want %f", restored.Predict(p), model.Predict(p))
+		}
+	}
+}
+
+func TestRestoreUnknownFeature(t *testing.T) {
+	_, snapshot := fitTestSnapshot(t, time.Now())
+	snapshot.Inputs = append(slices.Clone(snapshot.Inputs), "balcony")
+	if _, err := snapshot.Restore(); err == nil {
+		t.Error("Restore() with an unknown feature should return an error")
+	}
+}
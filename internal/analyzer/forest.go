@@ -0,0 +1,338 @@
+package analyzer
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+const (
+	// DefaultForestTrees is the default number of trees in the random forest.
+	DefaultForestTrees = 100
+
+	// ForestMinLeaf is the minimum number of samples in a leaf.
+	ForestMinLeaf = 5
+
+	// ForestMaxDepth is the maximum depth of each tree.
+	ForestMaxDepth = 12
+
+	// forestSeed seeds the random number generator so that fits are reproducible.
+	forestSeed = 1
+)
+
+// forestFeatureCount is the number of features used by the random forest
+// (area, age, floor, walkMinutes, station rent per m²).
+const forestFeatureCount = 5
+
+// forestStationFeature is the index of the station rent per m² feature.
+const forestStationFeature = 4
+
+// forestMtry is the number of features considered at each split.
+// With only a few features, p/3 would reduce to a single random feature per split.
+const forestMtry = (forestFeatureCount + 1) / 2
+
+// WithForestTrees sets the number of trees in the random forest model.
+func WithForestTrees(n int) Option {
+	return func(a *Analyzer) {
+		a.forestTrees = n
+	}
+}
+
+// forestModel is a random forest of regression trees.
+// Unlike the additive linear model, trees capture interactions such as
+// "new building AND near station".
+type forestModel struct {
+	trees   []regressionTree
+	metrics ModelMetrics
+}
+
+// treeNode is a node of a regression tree. Leaves have left == -1.
+type treeNode struct {
+	feature   int
+	threshold float64
+	left      int
+	right     int
+	value     float64
+}
+
+// regressionTree is a binary regression tree stored as a flat node slice (root at 0).
+type regressionTree struct {
+	nodes    []treeNode
+	stations stationEncoding // Encoding of the station feature, from the tree's in-bag sample
+}
+
+// predict returns the leaf value for the feature vector x.
+func (t *regressionTree) predict(x []float64) float64 {
+	i := 0
+	for t.nodes[i].left >= 0 {
+		if x[t.nodes[i].feature] <= t.nodes[i].threshold {
+			i = t.nodes[i].left
+		} else {
+			i = t.nodes[i].right
+		}
+	}
+	return t.nodes[i].value
+}
+
+// forestFeatures builds the feature vector for a property, with the station
+// feature left at zero: it is set by each tree's station encoding.
+func forestFeatures(p models.Property) []float64 {
+	return []float64{p.Area, float64(p.Age), float64(p.Floor), float64(p.WalkMinutes), 0}
+}
+
+// predictProperty returns the leaf value for a property, given its feature
+// vector from forestFeatures. The station feature of x is overwritten.
+func (t *regressionTree) predictProperty(p models.Property, x []float64) float64 {
+	x[forestStationFeature] = t.stations.value(p.NearestStation)
+	return t.predict(x)
+}
+
+// Predict calculates the predicted total rent as the average over all trees.
+func (m *forestModel) Predict(p models.Property) float64 {
+	x := forestFeatures(p)
+	sum := 0.0
+	for i := range m.trees {
+		sum += m.trees[i].predictProperty(p, x)
+	}
+	return sum / float64(len(m.trees))
+}
+
+// Metrics returns the fit metrics of the model.
+func (m *forestModel) Metrics() ModelMetrics {
+	return m.metrics
+}
+
+// fitForest fits a random forest on the properties.
+// Each tree is trained on a bootstrap sample drawn with probability
+// proportional to the recency weight, so time decay and the lookback window
+// apply as for the linear model. Samples not drawn for a tree are used to
+// compute the out-of-bag error. The station target encoding of each tree is
+// computed from its in-bag sample only, so out-of-bag rents don't leak into
+// the features of the trees that predict them.
+func (a *Analyzer) fitForest(properties []models.Property) (*forestModel, error) {
+	reference := latestFirstSeen(properties)
+	properties = a.withinLookback(properties, reference)
+
+	n := len(properties)
+	if n < a.minSamples {
+		return nil, ErrInsufficientSamples
+	}
+
+	model := &forestModel{}
+
+	x := make([][]float64, n)
+	y := make([]float64, n)
+	for i, p := range properties {
+		x[i] = forestFeatures(p)
+		y[i] = p.TotalRent()
+	}
+
+	// Cumulative weights for weighted bootstrap sampling
+	weights := a.timeWeights(properties, reference)
+	cumulative := make([]float64, n)
+	total := 0.0
+	for i, w := range weights {
+		total += w
+		cumulative[i] = total
+	}
+
+	numTrees := a.forestTrees
+	if numTrees <= 0 {
+		numTrees = DefaultForestTrees
+	}
+
+	rng := rand.New(rand.NewSource(forestSeed))
+	oobSum := make([]float64, n)
+	oobCount := make([]int, n)
+	builder := &treeBuilder{
+		x:        x,
+		y:        y,
+		rng:      rng,
+		mtry:     forestMtry,
+		minLeaf:  ForestMinLeaf,
+		maxDepth: ForestMaxDepth,
+	}
+
+	model.trees = make([]regressionTree, numTrees)
+	for t := range model.trees {
+		inBag := make([]bool, n)
+		sample := make([]int, n)
+		for i := range sample {
+			j := sort.SearchFloat64s(cumulative, rng.Float64()*total)
+			if j >= n {
+				j = n - 1
+			}
+			sample[i] = j
+			inBag[j] = true
+		}
+
+		// Features of this tree, with the station encoding of its sample
+		stations := newStationEncoding(properties, sample)
+		for i, p := range properties {
+			x[i][forestStationFeature] = stations.value(p.NearestStation)
+		}
+
+		model.trees[t] = builder.build(sample)
+		model.trees[t].stations = stations
+
+		for i := range properties {
+			if !inBag[i] {
+				oobSum[i] += model.trees[t].predict(x[i])
+				oobCount[i]++
+			}
+		}
+	}
+
+	var train, oob errorStats
+	for i, p := range properties {
+		train.add(model.Predict(p), y[i])
+		if oobCount[i] > 0 {
+			oob.add(oobSum[i]/float64(oobCount[i]), y[i])
+		}
+	}
+	model.metrics = ModelMetrics{
+		Type:           ModelTypeForest,
+		Samples:        n,
+		TrainRMSE:      train.rmse(),
+		ValidationRMSE: oob.rmse(),
+		ValidationMAE:  oob.mae(),
+	}
+
+	return model, nil
+}
+
+// stationEncoding encodes the station as its median total rent per m², so
+// that trees can split stations by price level.
+type stationEncoding struct {
+	rent     map[string]float64 // Median total rent per m² of each station
+	fallback float64            // Median total rent per m² of all listings (unknown stations)
+}
+
+// newStationEncoding computes the station encoding from the properties at the
+// sample indices (duplicates allowed).
+func newStationEncoding(properties []models.Property, sample []int) stationEncoding {
+	byStation := make(map[string][]float64)
+	var all []float64
+	for _, i := range sample {
+		p := properties[i]
+		if p.Area <= 0 {
+			continue
+		}
+		v := p.TotalRentPerArea()
+		byStation[p.NearestStation] = append(byStation[p.NearestStation], v)
+		all = append(all, v)
+	}
+
+	e := stationEncoding{rent: make(map[string]float64, len(byStation)), fallback: median(all)}
+	for station, values := range byStation {
+		e.rent[station] = median(values)
+	}
+	return e
+}
+
+// value returns the encoded station, or the fallback for unknown stations.
+func (e stationEncoding) value(station string) float64 {
+	if v, ok := e.rent[station]; ok {
+		return v
+	}
+	return e.fallback
+}
+
+// treeBuilder grows regression trees by recursive variance-reducing splits,
+// considering a random subset of mtry features at each node.
+type treeBuilder struct {
+	x        [][]float64
+	y        []float64
+	rng      *rand.Rand
+	mtry     int
+	minLeaf  int
+	maxDepth int
+	nodes    []treeNode
+}
+
+// build grows a tree on the given sample indices (duplicates allowed).
+func (b *treeBuilder) build(sample []int) regressionTree {
+	b.nodes = nil
+	b.grow(sample, 0)
+	return regressionTree{nodes: b.nodes}
+}
+
+// grow adds a node for the samples and returns its index.
+func (b *treeBuilder) grow(sample []int, depth int) int {
+	sum := 0.0
+	for _, i := range sample {
+		sum += b.y[i]
+	}
+
+	index := len(b.nodes)
+	b.nodes = append(b.nodes, treeNode{left: -1, right: -1, value: sum / float64(len(sample))})
+
+	if len(sample) < 2*b.minLeaf || depth >= b.maxDepth {
+		return index
+	}
+
+	feature, threshold, ok := b.bestSplit(sample, sum)
+	if !ok {
+		return index
+	}
+
+	var left, right []int
+	for _, i := range sample {
+		if b.x[i][feature] <= threshold {
+			left = append(left, i)
+		} else {
+			right = append(right, i)
+		}
+	}
+
+	leftIndex := b.grow(left, depth+1)
+	rightIndex := b.grow(right, depth+1)
+	b.nodes[index].feature = feature
+	b.nodes[index].threshold = threshold
+	b.nodes[index].left = leftIndex
+	b.nodes[index].right = rightIndex
+
+	return index
+}
+
+// bestSplit finds the split among mtry random features that minimizes the
+// sum of squared errors of the children. Returns false if no split leaves at
+// least minLeaf samples on both sides or none improves on the parent.
+func (b *treeBuilder) bestSplit(sample []int, sum float64) (feature int, threshold float64, ok bool) {
+	n := len(sample)
+	bestGain := sum * sum / float64(n) // Parent score; a split must exceed it
+
+	sorted := make([]int, n)
+	for _, f := range b.rng.Perm(forestFeatureCount)[:b.mtry] {
+		copy(sorted, sample)
+		sort.Slice(sorted, func(i, j int) bool {
+			return b.x[sorted[i]][f] < b.x[sorted[j]][f]
+		})
+
+		leftSum := 0.0
+		for k := 0; k < n-1; k++ {
+			leftSum += b.y[sorted[k]]
+			nl := k + 1
+			if nl < b.minLeaf || n-nl < b.minLeaf {
+				continue
+			}
+			lo, hi := b.x[sorted[k]][f], b.x[sorted[k+1]][f]
+			if lo == hi {
+				continue
+			}
+
+			rightSum := sum - leftSum
+			// Maximizing this is equivalent to minimizing the children's SSE
+			gain := leftSum*leftSum/float64(nl) + rightSum*rightSum/float64(n-nl)
+			if gain > bestGain+1e-9 {
+				bestGain = gain
+				feature = f
+				threshold = (lo + hi) / 2
+				ok = true
+			}
+		}
+	}
+
+	return feature, threshold, ok
+}
@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+// generateInteractionProperties creates properties with a premium of 30000 yen
+// only for new buildings (age < 5) near the station (walk <= 5 minutes),
+// which the additive linear model cannot represent.
+func generateInteractionProperties(n int) []models.Property {
+	properties := generateTestProperties(n)
+	for i := range properties {
+		properties[i].Age = (i * 7) % 20
+		properties[i].WalkMinutes = (i*11)%15 + 1
+		properties[i].Rent = 50000 + 2000*properties[i].Area
+		if properties[i].Age < 5 && properties[i].WalkMinutes <= 5 {
+			properties[i].Rent += 30000
+		}
+	}
+	return properties
+}
+
+func TestForestCapturesInteractions(t *testing.T) {
+	properties := generateInteractionProperties(200)
+
+	forest, err := NewAnalyzer(WithModelType(ModelTypeForest)).Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit(forest) error = %v", err)
+	}
+	ols, err := NewAnalyzer().Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit(ols) error = %v", err)
+	}
+
+	fm, om := forest.Metrics(), ols.Metrics()
+	if fm.Type != ModelTypeForest || om.Type != ModelTypeOLS {
+		t.Errorf("Metrics types = (%s, %s), want (forest, ols)", fm.Type, om.Type)
+	}
+	if fm.Samples != 200 {
+		t.Errorf("Forest samples = %d, want 200", fm.Samples)
+	}
+	if fm.ValidationRMSE <= 0 {
+		t.Error("Forest out-of-bag RMSE should be positive")
+	}
+	if fm.ValidationRMSE >= om.ValidationRMSE {
+		t.Errorf("Forest OOB RMSE %f should beat OLS LOO RMSE %f on interaction data",
+			fm.ValidationRMSE, om.ValidationRMSE)
+	}
+
+	premium := models.Property{Area: 30, Age: 1, Floor: 3, WalkMinutes: 3}
+	plain := models.Property{Area: 30, Age: 15, Floor: 3, WalkMinutes: 12}
+	if diff := forest.Predict(premium) - forest.Predict(plain); diff < 15000 {
+		t.Errorf("Forest premium difference = %f, want > 15000", diff)
+	}
+}
+
+func TestForestIsDeterministic(t *testing.T) {
+	properties := generateInteractionProperties(60)
+	target := models.Property{Area: 30, Age: 3, Floor: 2, WalkMinutes: 4}
+
+	a := NewAnalyzer(WithModelType(ModelTypeForest), WithForestTrees(20))
+	m1, err := a.Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	m2, _ := a.Fit(properties)
+
+	if m1.Predict(target) != m2.Predict(target) {
+		t.Error("Forest fits on the same data should be identical")
+	}
+}
+
+func TestFitUnknownModelType(t *testing.T) {
+	_, err := NewAnalyzer(WithModelType("unknown")).Fit(generateTestProperties(20))
+	if err == nil {
+		t.Error("Fit() with unknown model type should return an error")
+	}
+}
+
+func TestAnalyzeNewPropertiesWithForest(t *testing.T) {
+	analyzer := NewAnalyzer(WithModelType(ModelTypeForest), WithForestTrees(20))
+	results := analyzer.AnalyzeNewProperties(generateTestProperties(40), generateTestProperties(3))
+
+	for i, r := range results {
+		if r.Label == "" || r.Explanation != nil {
+			t.Errorf("Result[%d] = %+v, want label without explanation", i, r)
+		}
+	}
+}
+
+func TestForestOutOfBagStationEncoding(t *testing.T) {
+	// Each listing has its own station and a rent unrelated to the other
+	// features: encoding a station with its own rent would leak the target
+	// into the out-of-bag predictions.
+	properties := generateTestProperties(100)
+	var mean float64
+	for i := range properties {
+		properties[i].NearestStation = fmt.Sprintf("駅%d", i)
+		properties[i].Rent = 60000 + float64((i*7919)%40)*1000
+		mean += properties[i].TotalRent() / float64(len(properties))
+	}
+	var std float64
+	for _, p := range properties {
+		std += (p.TotalRent() - mean) * (p.TotalRent() - mean) / float64(len(properties))
+	}
+	std = math.Sqrt(std)
+
+	model, err := NewAnalyzer(WithModelType(ModelTypeForest), WithForestTrees(50)).Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if rmse := model.Metrics().ValidationRMSE; rmse < 0.8*std {
+		t.Errorf("OOB RMSE = %.0f on noise with std %.0f, the station encoding leaks the target", rmse, std)
+	}
+}
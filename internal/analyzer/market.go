@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+// MinIndexSamples is the minimum number of listings in a period for an index point.
+const MinIndexSamples = 3
+
+// MarketIndex computes a hedonic rent index per station and per ward.
+//
+// The regression is used to adjust each listing's total rent to the average
+// characteristics of the dataset (area, age, floor, walk minutes, facilities,
+// structure and, if enabled, layout):
+//
+//	adjusted = actual − Σ βⱼ × (xⱼ − meanⱼ)
+//
+// Location features (stations) and seasonal terms are not adjusted, so location and time effects
+// remain in the index. Each index point is the median adjusted rent of the
+// listings first seen in the period. Listings without FirstSeen are skipped.
+func (a *Analyzer) MarketIndex(properties []models.Property, period models.IndexPeriod) (models.MarketIndex, error) {
+	index := models.MarketIndex{Period: period}
+
+	model, err := a.fitRegression(properties)
+	if err != nil {
+		return index, err
+	}
+
+	stations := make(map[string]map[time.Time][]float64)
+	wards := make(map[string]map[time.Time][]float64)
+	add := func(groups map[string]map[time.Time][]float64, name string, start time.Time, value float64) {
+		if name == "" {
+			return
+		}
+		if groups[name] == nil {
+			groups[name] = make(map[time.Time][]float64)
+		}
+		groups[name][start] = append(groups[name][start], value)
+	}
+
+	for _, p := range properties {
+		if p.FirstSeen.IsZero() || p.TotalRent() <= 0 {
+			continue
+		}
+		adjusted := a.adjustedRent(p, model)
+		start := period.Start(p.FirstSeen)
+		add(stations, p.NearestStation, start, adjusted)
+		add(wards, models.ParseWard(p.Address), start, adjusted)
+	}
+
+	index.Stations = buildIndexSeries(stations)
+	index.Wards = buildIndexSeries(wards)
+
+	return index, nil
+}
+
+// adjustedRent returns the total rent adjusted to the average non-location features of the model.
+func (a *Analyzer) adjustedRent(p models.Property, model *regressionModel) float64 {
+	row := model.designRow(p)
+	adjusted := p.TotalRent()
+	for _, span := range model.spans() {
+		if span.feature.Location {
+			continue
+		}
+		for j := span.from; j < span.to; j++ {
+			adjusted -= model.coefficients[j] * (row[j] - model.means[j])
+		}
+	}
+	return adjusted
+}
+
+// buildIndexSeries converts grouped adjusted rents to index series.
+// Periods with fewer than MinIndexSamples listings are omitted.
+// Series are ordered by number of listings (descending), then by name.
+func buildIndexSeries(groups map[string]map[time.Time][]float64) []models.IndexSeries {
+	series := make([]models.IndexSeries, 0, len(groups))
+	for name, periods := range groups {
+		s := models.IndexSeries{Name: name}
+		for start, values := range periods {
+			if len(values) < MinIndexSamples {
+				continue
+			}
+			s.Points = append(s.Points, models.IndexPoint{
+				Start: start,
+				Value: median(values),
+				Count: len(values),
+			})
+		}
+		if len(s.Points) == 0 {
+			continue
+		}
+		sort.Slice(s.Points, func(i, j int) bool {
+			return s.Points[i].Start.Before(s.Points[j].Start)
+		})
+		series = append(series, s)
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		if ti, tj := series[i].Total(), series[j].Total(); ti != tj {
+			return ti > tj
+		}
+		return series[i].Name < series[j].Name
+	})
+
+	return series
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestMarketIndex(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 中野 rents rise by 2000 yen per month; 高円寺 stays flat
+	var properties []models.Property
+	for month := 0; month < 3; month++ {
+		for i, p := range generateTestProperties(20) {
+			p.FirstSeen = start.AddDate(0, month, i%28)
+			if i%2 == 0 {
+				p.NearestStation = "中野"
+				p.Address = "東京都中野区中野"
+				p.Rent += float64(month) * 2000
+			} else {
+				p.NearestStation = "高円寺"
+				p.Address = "東京都杉並区高円寺"
+			}
+			properties = append(properties, p)
+		}
+	}
+
+	index, err := NewAnalyzer().MarketIndex(properties, models.IndexPeriodMonth)
+	if err != nil {
+		t.Fatalf("MarketIndex() error = %v", err)
+	}
+
+	if len(index.Stations) != 2 || len(index.Wards) != 2 {
+		t.Fatalf("MarketIndex() returned %d stations and %d wards, want 2 and 2", len(index.Stations), len(index.Wards))
+	}
+
+	for _, s := range index.Stations {
+		if len(s.Points) != 3 {
+			t.Fatalf("Series %s has %d points, want 3", s.Name, len(s.Points))
+		}
+		change, _ := s.Change(index.Period)
+		rise := s.Points[2].Value - s.Points[1].Value
+		switch s.Name {
+		case "中野":
+			if math.Abs(rise-2000) > 500 || change <= 0 {
+				t.Errorf("中野 index rise = %f (change %f), want ~2000", rise, change)
+			}
+		case "高円寺":
+			if math.Abs(rise) > 500 {
+				t.Errorf("高円寺 index rise = %f, want ~0", rise)
+			}
+		}
+	}
+}
+
+func TestMarketIndexInsufficientData(t *testing.T) {
+	_, err := NewAnalyzer().MarketIndex(generateTestProperties(5), models.IndexPeriodMonth)
+	if err == nil {
+		t.Error("MarketIndex() with insufficient data should return an error")
+	}
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/metrics"
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+// ModelType selects the rent model used for scoring.
+type ModelType string
+
+const (
+	// ModelTypeOLS is the multiple linear regression model.
+	ModelTypeOLS ModelType = "ols"
+
+	// ModelTypeForest is the random forest model.
+	ModelTypeForest ModelType = "forest"
+)
+
+// RentModel is a fitted model that predicts the total rent of a property.
+type RentModel interface {
+	// Predict returns the predicted total rent (rent + management fee) in yen.
+	Predict(p models.Property) float64
+
+	// Metrics returns the fit metrics of the model.
+	Metrics() ModelMetrics
+}
+
+// ModelMetrics describes the fit quality of a rent model.
+// The validation error is leave-one-out for OLS and out-of-bag for the
+// random forest, so both estimate the error on unseen listings.
+type ModelMetrics struct {
+	Type           ModelType `json:"type"`
+	Samples        int       `json:"samples"`
+	TrainRMSE      float64   `json:"train_rmse"`
+	ValidationRMSE float64   `json:"validation_rmse"`
+	ValidationMAE  float64   `json:"validation_mae"`
+}
+
+// WithModelType sets the rent model used for scoring.
+func WithModelType(t ModelType) Option {
+	return func(a *Analyzer) {
+		a.modelType = t
+	}
+}
+
+// Fit fits the configured rent model on the properties.
+// Returns ErrInsufficientSamples if there are fewer than MinSamples properties.
+func (a *Analyzer) Fit(properties []models.Property) (RentModel, error) {
+	if len(properties) < a.minSamples {
+		return nil, ErrInsufficientSamples
+	}
+
+	start := time.Now()
+	var model RentModel
+	var err error
+	switch a.modelType {
+	case ModelTypeOLS, "":
+		model, err = a.fitRegression(properties)
+	case ModelTypeForest:
+		model, err = a.fitForest(properties)
+	default:
+		return nil, fmt.Errorf("unknown model type: %q", a.modelType)
+	}
+	label := metrics.L("model", string(a.modelType))
+	a.metrics.Count("model_fits_total", 1, label, metrics.Result(err))
+	if err != nil {
+		return nil, err
+	}
+
+	m := model.Metrics()
+	a.metrics.Timing("model_fit_duration_seconds", time.Since(start), label)
+	a.metrics.Gauge("model_samples", float64(m.Samples), label)
+	a.metrics.Gauge("model_validation_rmse", m.ValidationRMSE, label)
+	a.logger.Debug("Fitted rent model", "type", m.Type, "samples", m.Samples,
+		"train_rmse", m.TrainRMSE, "validation_rmse", m.ValidationRMSE, "duration", time.Since(start))
+	return model, nil
+}
+
+// Score calculates bargain scores for the targets using a fitted model.
+// The pool is the dataset searched for comparable listings. Without a model
+// (nil), the targets keep the analyzing label but still get comparables.
+func (a *Analyzer) Score(model RentModel, pool, targets []models.Property) []notifier.PropertyWithScore {
+	if model == nil {
+		result := notifier.ConvertToPropertyWithScore(targets)
+		for i := range result {
+			result[i].Comparables = a.findComparables(result[i].Property, pool)
+		}
+		return result
+	}
+
+	result := make([]notifier.PropertyWithScore, len(targets))
+	for i, p := range targets {
+		predicted := model.Predict(p)
+		actual := p.TotalRent()
+		score := predicted - actual // Positive = cheaper than expected (bargain)
+
+		result[i] = notifier.PropertyWithScore{
+			Property:    p,
+			Score:       score,
+			Label:       notifier.CalculateScoreLabel(score),
+			Comparables: a.findComparables(p, pool),
+		}
+
+		// Per-feature explanations and rent bands are only available for the linear model
+		if m, ok := model.(*regressionModel); ok {
+			result[i].Explanation = a.explain(p, m)
+			result[i].Band = m.band(p)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// QuantileLevels are the quantiles of the rent band fitted by quantile regression.
+var QuantileLevels = []float64{0.1, 0.5, 0.9}
+
+const (
+	// quantileIterations is the maximum number of IRLS iterations per quantile.
+	quantileIterations = 100
+
+	// quantileTolerance stops IRLS when no coefficient changes by more than this (yen).
+	quantileTolerance = 0.01
+
+	// quantileMinResidual bounds residuals away from zero in the IRLS weights (yen).
+	quantileMinResidual = 1.0
+)
+
+// WithQuantiles enables quantile regression, which adds a rent band
+// (10th/50th/90th percentiles) and a percentile rank to scored properties.
+// Only the linear model supports quantiles.
+func WithQuantiles(enabled bool) Option {
+	return func(a *Analyzer) {
+		a.quantiles = enabled
+	}
+}
+
+// fitQuantiles fits one linear quantile regression per QuantileLevels on the
+// same design matrix as the model. The pinball loss is minimized by
+// iteratively reweighted least squares starting from the OLS coefficients:
+// a residual r is weighted by τ/|r| above the fit and (1−τ)/|r| below it,
+// multiplied by the recency weight.
+func fitQuantiles(properties []models.Property, model *regressionModel, weights []float64) ([][]float64, error) {
+	n := len(properties)
+	k := model.numFeatures()
+
+	rows := make([][]float64, n)
+	y := make([]float64, n)
+	for i, p := range properties {
+		rows[i] = model.designRow(p)
+		y[i] = p.TotalRent()
+	}
+
+	result := make([][]float64, len(QuantileLevels))
+	for q, tau := range QuantileLevels {
+		beta := append([]float64(nil), model.coefficients...)
+		for iter := 0; iter < quantileIterations; iter++ {
+			w := make([]float64, n)
+			for i := range rows {
+				r := y[i] - dot(rows[i], beta)
+				side := tau
+				if r < 0 {
+					side = 1 - tau
+				}
+				w[i] = weights[i] * side / math.Max(math.Abs(r), quantileMinResidual)
+			}
+
+			next, err := solveWeighted(rows, y, w, k)
+			if err != nil {
+				return nil, err
+			}
+
+			change := 0.0
+			for j := range next {
+				change = math.Max(change, math.Abs(next[j]-beta[j]))
+			}
+			beta = next
+			if change < quantileTolerance {
+				break
+			}
+		}
+		result[q] = beta
+	}
+
+	return result, nil
+}
+
+// solveWeighted solves the weighted least squares problem via the normal equation.
+func solveWeighted(rows [][]float64, y, w []float64, k int) ([]float64, error) {
+	xData := make([]float64, len(rows)*k)
+	yData := make([]float64, len(rows))
+	for i, row := range rows {
+		s := math.Sqrt(w[i])
+		for j, v := range row {
+			xData[i*k+j] = v * s
+		}
+		yData[i] = y[i] * s
+	}
+	X := mat.NewDense(len(rows), k, xData)
+
+	var XtX mat.Dense
+	XtX.Mul(X.T(), X)
+	var Xty mat.VecDense
+	Xty.MulVec(X.T(), mat.NewVecDense(len(rows), yData))
+
+	var beta mat.VecDense
+	if err := beta.SolveVec(&XtX, &Xty); err != nil {
+		return nil, err
+	}
+
+	result := make([]float64, k)
+	for j := range result {
+		result[j] = beta.AtVec(j)
+	}
+	return result, nil
+}
+
+// dot returns the dot product of a and b.
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// band predicts the rent band of a property and the percentile rank of its
+// actual rent. Returns nil if the model has no quantile coefficients.
+func (m *regressionModel) band(p models.Property) *notifier.RentBand {
+	if len(m.quantiles) != len(QuantileLevels) {
+		return nil
+	}
+
+	row := m.designRow(p)
+	values := make([]float64, len(m.quantiles))
+	for q, beta := range m.quantiles {
+		values[q] = dot(row, beta)
+	}
+	// Separately fitted quantiles can cross; sorting restores monotonicity
+	sort.Float64s(values)
+
+	return &notifier.RentBand{
+		Lower:          values[0],
+		Median:         values[1],
+		Upper:          values[2],
+		PercentileRank: percentileRank(p.TotalRent(), values, QuantileLevels),
+	}
+}
+
+// percentileRank returns the percentile (0–100) of value among listings with
+// the same features, by linear interpolation between the predicted quantiles.
+// Outside the band the nearest segment is extrapolated; the result is clamped
+// to [1, 99] since the tails are not fitted.
+func percentileRank(value float64, quantiles, levels []float64) float64 {
+	last := len(quantiles) - 1
+
+	// Segment to interpolate (or extrapolate) on
+	i := sort.SearchFloat64s(quantiles, value) - 1
+	i = max(0, min(i, last-1))
+
+	var rank float64
+	if width := quantiles[i+1] - quantiles[i]; width > 0 {
+		rank = levels[i] + (levels[i+1]-levels[i])*(value-quantiles[i])/width
+	} else if value <= quantiles[i] {
+		rank = levels[i]
+	} else {
+		rank = levels[i+1]
+	}
+
+	return math.Max(1, math.Min(99, rank*100))
+}
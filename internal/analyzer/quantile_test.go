@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestPercentileRank(t *testing.T) {
+	quantiles := []float64{80000, 90000, 100000}
+
+	tests := []struct {
+		name  string
+		value float64
+		want  float64
+	}{
+		{name: "at median", value: 90000, want: 50},
+		{name: "at lower", value: 80000, want: 10},
+		{name: "within lower segment", value: 85000, want: 30},
+		{name: "within upper segment", value: 95000, want: 70},
+		{name: "below band", value: 79500, want: 8},
+		{name: "far below band", value: 50000, want: 1},
+		{name: "far above band", value: 200000, want: 99},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := percentileRank(tt.value, quantiles, QuantileLevels)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("percentileRank(%f) = %f, want %f", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantileBand(t *testing.T) {
+	properties := generateTestProperties(100)
+	for i := range properties {
+		// Uniform noise between -10,000 and +10,000 yen
+		properties[i].Rent += float64((i*7)%21-10) * 1000
+	}
+
+	analyzer := NewAnalyzer(WithQuantiles(true))
+	model, err := analyzer.Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+
+	target := properties[0]
+	target.Rent = 50000 + 2000*target.Area - 500*float64(target.Age) + 1000*float64(target.Floor) - 500*float64(target.WalkMinutes) - 9500
+
+	scored := analyzer.Score(model, properties, []models.Property{target})
+	band := scored[0].Band
+	if band == nil {
+		t.Fatal("Band should be set when quantiles are enabled")
+	}
+	if !(band.Lower < band.Median && band.Median < band.Upper) {
+		t.Errorf("Band not ordered: %+v", band)
+	}
+	// The 10th–90th percentile range of the noise is about 16,000 yen
+	if width := band.Upper - band.Lower; width < 12000 || width > 20000 {
+		t.Errorf("Band width = %.0f, want about 16000", width)
+	}
+	if band.PercentileRank > 20 {
+		t.Errorf("PercentileRank = %.1f, want <= 20 for a rent near the bottom of the noise", band.PercentileRank)
+	}
+
+	// Bands are not computed unless enabled
+	model, err = NewAnalyzer().Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if NewAnalyzer().Score(model, properties, properties[:1])[0].Band != nil {
+		t.Error("Band should be nil when quantiles are disabled")
+	}
+}
@@ -2,8 +2,12 @@
 package analyzer
 
 import (
-	"sort"
+	"errors"
+	"log/slog"
+	"math"
+	"time"
 
+	"github.com/alp/suumo-hunter/internal/metrics"
 	"github.com/alp/suumo-hunter/internal/models"
 	"github.com/alp/suumo-hunter/internal/notifier"
 
@@ -14,155 +18,213 @@ const (
 	// MinSamples is the minimum number of samples required for regression analysis.
 	MinSamples = 10
 
-	// BaseFeatureCount is the number of base features (intercept, area, age, floor, walkMinutes).
+	// BaseFeatureCount is the number of base features (intercept, area, age, floor, walkMinutes)
+	// without feature transforms.
 	BaseFeatureCount = 5
 )
 
+// ErrInsufficientSamples is returned when there are too few samples to fit a model.
+var ErrInsufficientSamples = errors.New("insufficient samples for regression")
+
 // Analyzer performs regression analysis on property data.
 type Analyzer struct {
-	minSamples int
+	minSamples       int
+	comparablesK     int
+	neighborStations map[string][]string
+	halfLife         time.Duration
+	lookback         time.Duration
+	seasonality      bool
+	layoutFeature    bool
+	modelType        ModelType
+	forestTrees      int
+	quantiles        bool
+	transforms       map[string]FeatureTransform
+
+	duplicateAreaTolerance float64
+	reappearanceGap        time.Duration
+
+	logger  *slog.Logger
+	metrics metrics.Recorder
+}
+
+// Option is a function that configures an Analyzer.
+type Option func(*Analyzer)
+
+// WithComparablesK sets the number of comparable listings used for the
+// comparables-based estimate. Zero disables the estimate.
+func WithComparablesK(k int) Option {
+	return func(a *Analyzer) {
+		a.comparablesK = k
+	}
 }
 
-// regressionModel holds the fitted regression coefficients and station mappings.
+// WithNeighborStations sets stations treated as neighbors when searching for
+// comparable listings. Neighbor relations are symmetric.
+func WithNeighborStations(neighbors map[string][]string) Option {
+	return func(a *Analyzer) {
+		a.neighborStations = make(map[string][]string)
+		for station, list := range neighbors {
+			for _, neighbor := range list {
+				a.neighborStations[station] = append(a.neighborStations[station], neighbor)
+				a.neighborStations[neighbor] = append(a.neighborStations[neighbor], station)
+			}
+		}
+	}
+}
+
+// regressionModel holds the fitted regression coefficients and feature encodings.
 type regressionModel struct {
 	coefficients []float64
-	stations     []string // Sorted list of station names (excluding reference station)
-	stationIndex map[string]int
+	features     []string                    // Registered features in design matrix order (nil = numeric features only)
+	levels       map[string][]string         // Dummy levels of categorical features (excluding the reference level)
+	levelIndex   map[string]map[string]int   // Level to dummy column of categorical features
+	seasonal     bool                        // Whether month-of-year terms are included
+	reference    time.Time                   // Reference time of the training data (latest FirstSeen)
+	means        []float64                   // Mean of each design matrix column over the training data
+	stds         []float64                   // Standard deviation of each design matrix column over the training data
+	quantiles    [][]float64                 // Quantile regression coefficients per QuantileLevels (nil if disabled)
+	transforms   map[string]FeatureTransform // Numeric feature transforms (linear if absent)
+	config       FitConfig                   // Configuration the model was fitted with
+	metrics      ModelMetrics
 }
 
-// NewAnalyzer creates a new Analyzer instance.
-func NewAnalyzer() *Analyzer {
-	return &Analyzer{
-		minSamples: MinSamples,
+// WithTimeDecay weights observations by recency using the given half-life:
+// a listing first seen one half-life before the latest listing counts half.
+// Zero disables time decay.
+func WithTimeDecay(halfLife time.Duration) Option {
+	return func(a *Analyzer) {
+		a.halfLife = halfLife
 	}
 }
 
-// extractStations extracts unique station names from properties and returns them sorted.
-// The first station in the sorted list is used as the reference category (excluded from dummies).
-func extractStations(properties []models.Property) []string {
-	stationSet := make(map[string]bool)
-	for _, p := range properties {
-		if p.NearestStation != "" {
-			stationSet[p.NearestStation] = true
-		}
+// WithLookback restricts the regression to listings first seen within the
+// given window before the latest listing. Zero uses all listings.
+func WithLookback(d time.Duration) Option {
+	return func(a *Analyzer) {
+		a.lookback = d
+	}
+}
+
+// WithSeasonality adds month-of-year terms to the regression.
+func WithSeasonality(enabled bool) Option {
+	return func(a *Analyzer) {
+		a.seasonality = enabled
+	}
+}
+
+// WithLayoutFeature adds layout dummies to the regression.
+func WithLayoutFeature(enabled bool) Option {
+	return func(a *Analyzer) {
+		a.layoutFeature = enabled
 	}
+}
 
-	stations := make([]string, 0, len(stationSet))
-	for station := range stationSet {
-		stations = append(stations, station)
+// WithLogger sets the logger. Defaults to slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(a *Analyzer) {
+		a.logger = l
 	}
-	sort.Strings(stations)
+}
 
-	return stations
+// WithMetrics sets the recorder of model fit durations, outcomes and metrics.
+func WithMetrics(m metrics.Recorder) Option {
+	return func(a *Analyzer) {
+		a.metrics = m
+	}
 }
 
-// buildStationIndex creates a mapping from station name to dummy variable index.
-// The first station is excluded as the reference category to avoid multicollinearity.
-func buildStationIndex(stations []string) (dummyStations []string, index map[string]int) {
-	if len(stations) <= 1 {
-		return nil, nil
+// NewAnalyzer creates a new Analyzer instance with the given options.
+func NewAnalyzer(opts ...Option) *Analyzer {
+	a := &Analyzer{
+		minSamples:   MinSamples,
+		comparablesK: DefaultComparablesK,
+		modelType:    ModelTypeOLS,
+		forestTrees:  DefaultForestTrees,
+
+		duplicateAreaTolerance: DefaultDuplicateAreaTolerance,
+		reappearanceGap:        DefaultReappearanceGap,
+		logger:                 slog.Default(),
+		metrics:                metrics.Nop{},
 	}
 
-	// Skip the first station (reference category)
-	dummyStations = stations[1:]
-	index = make(map[string]int)
-	for i, station := range dummyStations {
-		index[station] = i
+	for _, opt := range opts {
+		opt(a)
 	}
 
-	return dummyStations, index
+	return a
 }
 
 // Analyze performs multiple regression analysis and calculates bargain scores.
 // Returns PropertyWithScore for each input property.
 // If there are fewer than MinSamples properties, returns properties with "analyzing" label.
 func (a *Analyzer) Analyze(properties []models.Property) []notifier.PropertyWithScore {
-	result := make([]notifier.PropertyWithScore, len(properties))
-
-	// Check minimum samples
-	if len(properties) < a.minSamples {
-		// Not enough data for regression, return with analyzing label
-		for i, p := range properties {
-			result[i] = notifier.PropertyWithScore{
-				Property: p,
-				Score:    0,
-				Label:    notifier.ScoreLabelAnalyzing,
-			}
-		}
-		return result
-	}
-
-	// Perform regression analysis
-	model, err := a.fitRegression(properties)
-	if err != nil {
-		// Regression failed, return with analyzing label
-		for i, p := range properties {
-			result[i] = notifier.PropertyWithScore{
-				Property: p,
-				Score:    0,
-				Label:    notifier.ScoreLabelAnalyzing,
-			}
-		}
-		return result
-	}
-
-	// Calculate scores for each property
-	for i, p := range properties {
-		predicted := a.predict(p, model)
-		actual := p.TotalRent()
-		score := predicted - actual // Positive = cheaper than expected (bargain)
+	return a.AnalyzeNewProperties(properties, properties)
+}
 
-		result[i] = notifier.PropertyWithScore{
-			Property: p,
-			Score:    score,
-			Label:    notifier.CalculateScoreLabel(score),
-		}
+// optInFeatures returns the names of the opt-in features enabled on the Analyzer.
+func (a *Analyzer) optInFeatures() []string {
+	var names []string
+	if a.layoutFeature {
+		names = append(names, "layout")
 	}
-
-	return result
+	return names
 }
 
 // fitRegression performs multiple linear regression.
 // Target variable: Total rent (rent + management_fee)
-// Features: Area, Age, Floor, WalkMinutes, Station dummy variables
-// Returns regressionModel containing coefficients and station mappings.
+// Features: the registered features (area, age, floor, walk minutes,
+// facilities, structure and station dummies, plus layout dummies if enabled)
+// that vary in the data, and optionally month-of-year terms.
+// Observations outside the lookback window are dropped and the rest are
+// weighted by recency when time decay is enabled (weighted least squares).
+// Returns regressionModel containing coefficients and feature encodings.
 func (a *Analyzer) fitRegression(properties []models.Property) (*regressionModel, error) {
+	reference := latestFirstSeen(properties)
+	properties = a.withinLookback(properties, reference)
+
 	n := len(properties)
+	if n < a.minSamples {
+		return nil, ErrInsufficientSamples
+	}
 
-	// Extract unique stations and build dummy variable mapping
-	allStations := extractStations(properties)
-	dummyStations, stationIndex := buildStationIndex(allStations)
-	numDummies := len(dummyStations)
+	// Select features and build dummy variable mappings
+	features, levels := selectFeatures(properties, a.optInFeatures())
+
+	model := &regressionModel{
+		features:   features,
+		levels:     levels,
+		levelIndex: buildLevelIndex(levels),
+		seasonal:   a.seasonality && distinctMonths(properties) >= MinSeasonalMonths,
+		reference:  reference,
+		transforms: a.transforms,
+		config:     a.fitConfig(),
+	}
 
-	// Total features = base features + station dummies
-	numFeatures := BaseFeatureCount + numDummies
+	// Total features = intercept + feature columns (+ seasonal terms)
+	numFeatures := model.numFeatures()
 
-	// Build feature matrix X (n x numFeatures) with intercept column
-	// Columns: [1, area, age, floor, walkMinutes, station_dummy_1, station_dummy_2, ...]
+	// Build feature matrix X (n x numFeatures) with intercept column.
+	// Rows are scaled by sqrt(weight) so that ordinary least squares on the
+	// scaled data solves the weighted problem.
 	xData := make([]float64, n*numFeatures)
 	yData := make([]float64, n)
+	means := make([]float64, numFeatures)
+	weights := a.timeWeights(properties, reference)
 
 	for i, p := range properties {
-		offset := i * numFeatures
-		xData[offset+0] = 1                      // Intercept
-		xData[offset+1] = p.Area                 // Area (m²)
-		xData[offset+2] = float64(p.Age)         // Age (years)
-		xData[offset+3] = float64(p.Floor)       // Floor
-		xData[offset+4] = float64(p.WalkMinutes) // Walk minutes
-
-		// Station dummy variables
-		if idx, ok := stationIndex[p.NearestStation]; ok {
-			xData[offset+BaseFeatureCount+idx] = 1
+		row := model.designRow(p)
+		w := math.Sqrt(weights[i])
+		for j, v := range row {
+			xData[i*numFeatures+j] = v * w
+			means[j] += v / float64(n)
 		}
-		// If station is the reference category or unknown, all dummies remain 0
-
-		yData[i] = p.TotalRent() // Target: total rent
+		yData[i] = p.TotalRent() * w // Target: total rent
 	}
 
 	X := mat.NewDense(n, numFeatures, xData)
 	y := mat.NewVecDense(n, yData)
 
-	// Solve using normal equation: β = (X'X)^(-1) X'y
+	// Solve using normal equation: β = (X'WX)^(-1) X'Wy
 	var XtX mat.Dense
 	XtX.Mul(X.T(), X)
 
@@ -184,74 +246,124 @@ func (a *Analyzer) fitRegression(properties []models.Property) (*regressionModel
 		coefficients[i] = beta.AtVec(i)
 	}
 
-	return &regressionModel{
-		coefficients: coefficients,
-		stations:     dummyStations,
-		stationIndex: stationIndex,
-	}, nil
+	model.coefficients = coefficients
+	// Column means (unweighted), used to explain predictions relative to the dataset average
+	model.means = means
+	model.stds = columnStds(properties, model, means)
+
+	// A failed quantile fit only drops the rent bands, not the model
+	if a.quantiles {
+		if model.quantiles, err = fitQuantiles(properties, model, weights); err != nil {
+			a.logger.Warn("Failed to fit rent quantiles, rent bands are not available", "error", err)
+		}
+	}
+
+	// Training and leave-one-out errors. The leave-one-out residual is
+	// e_i / (1 − h_ii), where h_ii is the leverage of observation i.
+	var train, loo errorStats
+	for i, p := range properties {
+		actual := p.TotalRent()
+		predicted := model.Predict(p)
+		train.add(predicted, actual)
+
+		xi := X.RowView(i)
+		var h mat.VecDense
+		h.MulVec(&XtXInv, xi)
+		leverage := mat.Dot(xi, &h)
+		if leverage < 1 {
+			loo.add(actual+(predicted-actual)/(1-leverage), actual)
+		}
+	}
+	model.metrics = ModelMetrics{
+		Type:           ModelTypeOLS,
+		Samples:        n,
+		TrainRMSE:      train.rmse(),
+		ValidationRMSE: loo.rmse(),
+		ValidationMAE:  loo.mae(),
+	}
+
+	return model, nil
+}
+
+// columnStds returns the (unweighted) standard deviation of each design matrix column.
+func columnStds(properties []models.Property, model *regressionModel, means []float64) []float64 {
+	stds := make([]float64, len(means))
+	for _, p := range properties {
+		for j, v := range model.designRow(p) {
+			stds[j] += (v - means[j]) * (v - means[j])
+		}
+	}
+	for j := range stds {
+		stds[j] = math.Sqrt(stds[j] / float64(len(properties)))
+	}
+	return stds
+}
+
+// numFeatures returns the number of design matrix columns of the model.
+func (m *regressionModel) numFeatures() int {
+	n := m.featureColumns()
+	if m.seasonal {
+		n += SeasonalFeatureCount
+	}
+	return n
+}
+
+// designRow builds the feature vector for a property.
+// Columns: [1, area, age, floor, walkMinutes, facilities..., dummies..., month_sin, month_cos],
+// where each feature expands to its columns as declared in the feature registry.
+func (m *regressionModel) designRow(p models.Property) []float64 {
+	row := make([]float64, m.numFeatures())
+	row[0] = 1 // Intercept
+
+	offset := 1
+	for _, span := range m.spans() {
+		m.encode(span.feature, p, row[span.from:span.to])
+		offset = span.to
+	}
+
+	// Month-of-year terms; listings without a date are treated as seen at the reference time
+	if m.seasonal {
+		seen := p.FirstSeen
+		if seen.IsZero() {
+			seen = m.reference
+		}
+		row[offset], row[offset+1] = seasonalTerms(seen)
+	}
+
+	return row
 }
 
 // predict calculates the predicted rent for a property.
 func (a *Analyzer) predict(p models.Property, model *regressionModel) float64 {
-	// Base features: intercept, area, age, floor, walkMinutes
-	predicted := model.coefficients[0] +
-		model.coefficients[1]*p.Area +
-		model.coefficients[2]*float64(p.Age) +
-		model.coefficients[3]*float64(p.Floor) +
-		model.coefficients[4]*float64(p.WalkMinutes)
-
-	// Add station dummy variable contribution
-	if idx, ok := model.stationIndex[p.NearestStation]; ok {
-		predicted += model.coefficients[BaseFeatureCount+idx]
+	return model.Predict(p)
+}
+
+// Predict calculates the predicted total rent for a property.
+func (m *regressionModel) Predict(p models.Property) float64 {
+	row := m.designRow(p)
+
+	predicted := 0.0
+	for j, v := range row {
+		predicted += m.coefficients[j] * v
 	}
-	// If station is the reference category or unknown, no additional contribution
 
 	return predicted
 }
 
+// Metrics returns the fit metrics of the model.
+func (m *regressionModel) Metrics() ModelMetrics {
+	return m.metrics
+}
+
 // AnalyzeNewProperties analyzes only new properties using all properties for regression.
 // This is useful when you want to calculate scores only for new properties
 // but use the full dataset for more accurate regression.
 func (a *Analyzer) AnalyzeNewProperties(allProperties, newProperties []models.Property) []notifier.PropertyWithScore {
-	result := make([]notifier.PropertyWithScore, len(newProperties))
-
-	// Check minimum samples
-	if len(allProperties) < a.minSamples {
-		for i, p := range newProperties {
-			result[i] = notifier.PropertyWithScore{
-				Property: p,
-				Score:    0,
-				Label:    notifier.ScoreLabelAnalyzing,
-			}
-		}
-		return result
-	}
-
-	// Perform regression on all properties
-	model, err := a.fitRegression(allProperties)
-	if err != nil {
-		for i, p := range newProperties {
-			result[i] = notifier.PropertyWithScore{
-				Property: p,
-				Score:    0,
-				Label:    notifier.ScoreLabelAnalyzing,
-			}
-		}
-		return result
-	}
+	// Fit the rent model on all properties. Without a model (not enough data
+	// or the fit failed), new properties keep the analyzing label and only
+	// get comparables.
+	model, _ := a.Fit(allProperties)
 
 	// Calculate scores only for new properties
-	for i, p := range newProperties {
-		predicted := a.predict(p, model)
-		actual := p.TotalRent()
-		score := predicted - actual
-
-		result[i] = notifier.PropertyWithScore{
-			Property: p,
-			Score:    score,
-			Label:    notifier.CalculateScoreLabel(score),
-		}
-	}
-
-	return result
+	return a.Score(model, allProperties, newProperties)
 }
@@ -78,6 +78,10 @@ func TestAnalyzeWithInsufficientData(t *testing.T) {
 		if r.Score != 0 {
 			t.Errorf("Result[%d] should have score 0, got %f", i, r.Score)
 		}
+		// Comparables don't need a model
+		if r.Comparables == nil || len(r.Comparables.Properties) != 4 {
+			t.Errorf("Result[%d] comparables = %+v, want the other 4 listings", i, r.Comparables)
+		}
 	}
 }
 
@@ -254,8 +258,6 @@ func TestPrediction(t *testing.T) {
 	analyzer := NewAnalyzer()
 	model := &regressionModel{
 		coefficients: []float64{50000, 2000, -500, 1000, -500},
-		stations:     nil,
-		stationIndex: nil,
 	}
 
 	property := models.Property{
@@ -285,3 +287,26 @@ func TestAnalyzeEmptyInput(t *testing.T) {
 		t.Errorf("Expected empty results for empty input, got %d", len(results))
 	}
 }
+
+func TestRegressionMetrics(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	properties := generateTestProperties(40)
+	for i := range properties {
+		// Deterministic noise
+		properties[i].Rent += float64((i*37)%11-5) * 1000
+	}
+
+	model, err := analyzer.fitRegression(properties)
+	if err != nil {
+		t.Fatalf("fitRegression failed: %v", err)
+	}
+
+	m := model.Metrics()
+	if m.Type != ModelTypeOLS || m.Samples != 40 {
+		t.Errorf("Metrics() = %+v, want type ols with 40 samples", m)
+	}
+	if m.TrainRMSE <= 0 || m.ValidationRMSE <= m.TrainRMSE {
+		t.Errorf("Leave-one-out RMSE %f should exceed training RMSE %f", m.ValidationRMSE, m.TrainRMSE)
+	}
+}
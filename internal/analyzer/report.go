@@ -0,0 +1,30 @@
+package analyzer
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteModelReport writes the fit metrics of a rent model and, for the
+// linear model, its coefficients and training means per design matrix column.
+func WriteModelReport(w io.Writer, model RentModel) error {
+	m := model.Metrics()
+	fmt.Fprintf(w, "type: %s\nsamples: %d\ntrain_rmse: %.0f\nvalidation_rmse: %.0f\nvalidation_mae: %.0f\n",
+		m.Type, m.Samples, m.TrainRMSE, m.ValidationRMSE, m.ValidationMAE)
+
+	snapshot, err := NewSnapshot(model, time.Time{})
+	if err != nil {
+		// Only the linear model has coefficients
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "FEATURE\tCOEFFICIENT\tMEAN\t")
+	for i, name := range snapshot.Features {
+		fmt.Fprintf(tw, "%s\t%.1f\t%.2f\t\n", name, snapshot.Coefficients[i], snapshot.Means[i])
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteModelReport(t *testing.T) {
+	properties := generateTestProperties(50)
+
+	model, err := NewAnalyzer().Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteModelReport(&buf, model); err != nil {
+		t.Fatalf("WriteModelReport() error = %v", err)
+	}
+	for _, want := range []string{"type: ols", "samples: 50", "FEATURE", "intercept", "walk_minutes"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Report should contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	forest, err := NewAnalyzer(WithModelType(ModelTypeForest), WithForestTrees(5)).Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	buf.Reset()
+	if err := WriteModelReport(&buf, forest); err != nil {
+		t.Fatalf("WriteModelReport() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "type: forest") || strings.Contains(buf.String(), "FEATURE") {
+		t.Errorf("Forest report should only contain metrics, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,191 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+// SnapshotVersion is the version of the ModelSnapshot format.
+// Version 2 replaced the station list with the registered features and their levels.
+const SnapshotVersion = 2
+
+// ErrNotSerializable is returned when a rent model cannot be saved as a snapshot.
+var ErrNotSerializable = errors.New("rent model is not serializable")
+
+// ModelSnapshot is the serialized form of a fitted linear rent model,
+// persisted so that it can be reused across runs.
+type ModelSnapshot struct {
+	Version      int                         `json:"version"`
+	TrainedAt    time.Time                   `json:"trained_at"`
+	Features     []string                    `json:"features"` // Design matrix column names (feature schema)
+	Coefficients []float64                   `json:"coefficients"`
+	Means        []float64                   `json:"means"`
+	Stds         []float64                   `json:"stds,omitempty"`      // Used for drift detection; absent in older snapshots
+	Quantiles    [][]float64                 `json:"quantiles,omitempty"` // Quantile regression coefficients per QuantileLevels
+	Transforms   map[string]FeatureTransform `json:"transforms,omitempty"`
+	Inputs       []string                    `json:"inputs"`           // Registered features in design matrix order
+	Levels       map[string][]string         `json:"levels,omitempty"` // Dummy levels of categorical features in column order
+	Seasonal     bool                        `json:"seasonal"`
+	Reference    time.Time                   `json:"reference"`
+	Config       *FitConfig                  `json:"config,omitempty"` // Absent in older snapshots
+	Metrics      ModelMetrics                `json:"metrics"`
+}
+
+// FitConfig is the Analyzer configuration a linear model was fitted with.
+// A persisted model fitted with another configuration is not reused.
+type FitConfig struct {
+	Transforms  map[string]FeatureTransform `json:"transforms,omitempty"`
+	OptIn       []string                    `json:"opt_in,omitempty"` // Enabled opt-in features
+	Seasonality bool                        `json:"seasonality"`
+	HalfLife    time.Duration               `json:"half_life"`
+	Lookback    time.Duration               `json:"lookback"`
+	Quantiles   bool                        `json:"quantiles"`
+}
+
+// fitConfig returns the configuration models are fitted with.
+func (a *Analyzer) fitConfig() FitConfig {
+	return FitConfig{
+		Transforms:  a.transforms,
+		OptIn:       a.optInFeatures(),
+		Seasonality: a.seasonality,
+		HalfLife:    a.halfLife,
+		Lookback:    a.lookback,
+		Quantiles:   a.quantiles,
+	}
+}
+
+// Equal reports whether the configurations are the same.
+func (c FitConfig) Equal(other FitConfig) bool {
+	return maps.EqualFunc(c.Transforms, other.Transforms, func(a, b FeatureTransform) bool {
+		return a.Kind == b.Kind && slices.Equal(a.Knots, b.Knots)
+	}) &&
+		slices.Equal(c.OptIn, other.OptIn) &&
+		c.Seasonality == other.Seasonality &&
+		c.HalfLife == other.HalfLife &&
+		c.Lookback == other.Lookback &&
+		c.Quantiles == other.Quantiles
+}
+
+// featureNames returns the design matrix column names of the model.
+func (m *regressionModel) featureNames() []string {
+	names := []string{"intercept"}
+	for _, span := range m.spans() {
+		names = append(names, m.columnNames(span.feature)...)
+	}
+	if m.seasonal {
+		names = append(names, "month_sin", "month_cos")
+	}
+	return names
+}
+
+// NewSnapshot serializes a fitted rent model trained at trainedAt.
+// Only the linear model can be serialized; other models return ErrNotSerializable.
+func NewSnapshot(model RentModel, trainedAt time.Time) (*ModelSnapshot, error) {
+	m, ok := model.(*regressionModel)
+	if !ok {
+		return nil, ErrNotSerializable
+	}
+
+	return &ModelSnapshot{
+		Version:      SnapshotVersion,
+		TrainedAt:    trainedAt,
+		Features:     m.featureNames(),
+		Coefficients: m.coefficients,
+		Means:        m.means,
+		Stds:         m.stds,
+		Quantiles:    m.quantiles,
+		Transforms:   m.transforms,
+		Inputs:       m.featureList(),
+		Levels:       m.levels,
+		Seasonal:     m.seasonal,
+		Reference:    m.reference,
+		Config:       &m.config,
+		Metrics:      m.metrics,
+	}, nil
+}
+
+// Restore rebuilds the rent model from the snapshot.
+// Returns an error if the snapshot doesn't match the current feature schema.
+func (s *ModelSnapshot) Restore() (RentModel, error) {
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", s.Version)
+	}
+
+	m := &regressionModel{
+		coefficients: s.Coefficients,
+		features:     s.Inputs,
+		levels:       s.Levels,
+		levelIndex:   buildLevelIndex(s.Levels),
+		seasonal:     s.Seasonal,
+		reference:    s.Reference,
+		means:        s.Means,
+		stds:         s.Stds,
+		quantiles:    s.Quantiles,
+		transforms:   s.Transforms,
+		metrics:      s.Metrics,
+	}
+	if s.Config != nil {
+		m.config = *s.Config
+	}
+	for _, name := range s.Inputs {
+		if lookupFeature(name) == nil {
+			return nil, fmt.Errorf("snapshot has unknown feature: %q", name)
+		}
+	}
+	if s.Inputs == nil {
+		return nil, errors.New("snapshot has no features")
+	}
+
+	if !slices.Equal(s.Features, m.featureNames()) {
+		return nil, fmt.Errorf("snapshot feature schema mismatch: %v", s.Features)
+	}
+	if len(s.Coefficients) != m.numFeatures() || len(s.Means) != m.numFeatures() {
+		return nil, fmt.Errorf("snapshot has %d coefficients and %d means, want %d",
+			len(s.Coefficients), len(s.Means), m.numFeatures())
+	}
+	for _, q := range s.Quantiles {
+		if len(q) != m.numFeatures() {
+			return nil, fmt.Errorf("snapshot has %d quantile coefficients, want %d", len(q), m.numFeatures())
+		}
+	}
+
+	return m, nil
+}
+
+// FitOrReuse returns the rent model to score with, reusing the persisted
+// snapshot (if any) when possible:
+//
+//   - A new model is fitted when there is no usable snapshot of the configured
+//     model type and fit configuration (see FitConfig), or refitInterval has
+//     elapsed since it was trained (zero refitInterval refits on every run).
+//   - If fitting fails (e.g., too few samples or a singular matrix), the
+//     persisted model is used instead.
+//
+// fitted reports whether the returned model was newly fitted (and should be persisted).
+func (a *Analyzer) FitOrReuse(properties []models.Property, previous *ModelSnapshot, now time.Time, refitInterval time.Duration) (model RentModel, fitted bool, err error) {
+	var persisted RentModel
+	if previous != nil && previous.Metrics.Type == a.modelType && previous.Config != nil && previous.Config.Equal(a.fitConfig()) {
+		persisted, _ = previous.Restore()
+	}
+
+	if persisted != nil && refitInterval > 0 && now.Sub(previous.TrainedAt) < refitInterval {
+		a.logger.Debug("Reusing persisted model, refit not due", "trained_at", previous.TrainedAt, "refit_interval", refitInterval)
+		return persisted, false, nil
+	}
+
+	model, err = a.Fit(properties)
+	if err != nil {
+		if persisted != nil {
+			a.logger.Debug("Reusing persisted model, fit failed", "trained_at", previous.TrainedAt, "error", err)
+			return persisted, false, nil
+		}
+		return nil, false, err
+	}
+
+	return model, true, nil
+}
@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func fitTestSnapshot(t *testing.T, trainedAt time.Time) (RentModel, *ModelSnapshot) {
+	t.Helper()
+
+	properties := generateTestProperties(30)
+	for i := range properties {
+		properties[i].NearestStation = []string{"中野", "高円寺"}[i%2]
+	}
+
+	model, err := NewAnalyzer().Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	snapshot, err := NewSnapshot(model, trainedAt)
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	return model, snapshot
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	trainedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	model, snapshot := fitTestSnapshot(t, trainedAt)
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var loaded ModelSnapshot
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	restored, err := loaded.Restore()
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	p := models.Property{Area: 30, Age: 5, Floor: 2, WalkMinutes: 7, NearestStation: "高円寺"}
+	if restored.Predict(p) != model.Predict(p) {
+		t.Errorf("Restored prediction = %f, want %f", restored.Predict(p), model.Predict(p))
+	}
+	if restored.Metrics() != model.Metrics() {
+		t.Errorf("Restored metrics = %+v, want %+v", restored.Metrics(), model.Metrics())
+	}
+	if !loaded.TrainedAt.Equal(trainedAt) {
+		t.Errorf("TrainedAt = %v, want %v", loaded.TrainedAt, trainedAt)
+	}
+}
+
+func TestSnapshotSchemaMismatch(t *testing.T) {
+	_, snapshot := fitTestSnapshot(t, time.Now())
+
+	snapshot.Features = append([]string{}, snapshot.Features...)
+	snapshot.Features[1] = "area_m2"
+	if _, err := snapshot.Restore(); err == nil {
+		t.Error("Restore() with mismatched schema should return an error")
+	}
+}
+
+func TestNewSnapshotForest(t *testing.T) {
+	model, err := NewAnalyzer(WithModelType(ModelTypeForest), WithForestTrees(5)).Fit(generateTestProperties(20))
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if _, err := NewSnapshot(model, time.Now()); !errors.Is(err, ErrNotSerializable) {
+		t.Errorf("NewSnapshot(forest) error = %v, want ErrNotSerializable", err)
+	}
+}
+
+func TestFitOrReuse(t *testing.T) {
+	trainedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, snapshot := fitTestSnapshot(t, trainedAt)
+	analyzer := NewAnalyzer()
+	properties := generateTestProperties(20)
+
+	// Refit not due: reuse
+	_, fitted, err := analyzer.FitOrReuse(properties, snapshot, trainedAt.Add(time.Hour), 24*time.Hour)
+	if err != nil || fitted {
+		t.Errorf("FitOrReuse() before interval = (fitted=%v, err=%v), want reuse", fitted, err)
+	}
+
+	// Refit due: fit
+	_, fitted, err = analyzer.FitOrReuse(properties, snapshot, trainedAt.Add(48*time.Hour), 24*time.Hour)
+	if err != nil || !fitted {
+		t.Errorf("FitOrReuse() after interval = (fitted=%v, err=%v), want fit", fitted, err)
+	}
+
+	// Fit fails: fall back to the persisted model
+	model, fitted, err := analyzer.FitOrReuse(generateTestProperties(3), snapshot, trainedAt.Add(48*time.Hour), 0)
+	if err != nil || fitted || model == nil {
+		t.Errorf("FitOrReuse() with too few samples = (%v, fitted=%v, err=%v), want persisted model", model, fitted, err)
+	}
+
+	// Fit fails without a snapshot: error
+	if _, _, err := analyzer.FitOrReuse(generateTestProperties(3), nil, trainedAt, 0); !errors.Is(err, ErrInsufficientSamples) {
+		t.Errorf("FitOrReuse() without snapshot error = %v, want ErrInsufficientSamples", err)
+	}
+
+	// Snapshot of another model type is not reused
+	forest := NewAnalyzer(WithModelType(ModelTypeForest))
+	if _, _, err := forest.FitOrReuse(generateTestProperties(3), snapshot, trainedAt, 0); err == nil {
+		t.Error("FitOrReuse() should not reuse a snapshot of another model type")
+	}
+
+	// Snapshots fitted with another configuration, or without one, are not reused
+	reconfigured := []*Analyzer{
+		NewAnalyzer(WithFeatureTransforms(map[string]FeatureTransform{"walk_minutes": {Kind: TransformSpline, Knots: []float64{5, 10}}})),
+		NewAnalyzer(WithLayoutFeature(true)),
+		NewAnalyzer(WithSeasonality(true)),
+		NewAnalyzer(WithTimeDecay(30 * 24 * time.Hour)),
+		NewAnalyzer(WithLookback(90 * 24 * time.Hour)),
+		NewAnalyzer(WithQuantiles(true)),
+	}
+	for i, a := range reconfigured {
+		if _, fitted, _ := a.FitOrReuse(properties, snapshot, trainedAt.Add(time.Hour), 24*time.Hour); !fitted {
+			t.Errorf("Analyzer %d: FitOrReuse() reused a snapshot fitted with another configuration", i)
+		}
+	}
+	// The configuration survives persistence
+	transforms := map[string]FeatureTransform{"walk_minutes": {Kind: TransformSpline, Knots: []float64{5, 10}}}
+	configured := NewAnalyzer(WithFeatureTransforms(transforms), WithLayoutFeature(true), WithTimeDecay(time.Hour))
+	model, err = configured.Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	persisted, err := NewSnapshot(model, trainedAt)
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	data, err := json.Marshal(persisted)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var loaded ModelSnapshot
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if _, fitted, _ := configured.FitOrReuse(properties, &loaded, trainedAt.Add(time.Hour), 24*time.Hour); fitted {
+		t.Error("FitOrReuse() should reuse a loaded snapshot fitted with the same configuration")
+	}
+
+	legacy := *snapshot
+	legacy.Config = nil
+	if _, fitted, _ := analyzer.FitOrReuse(properties, &legacy, trainedAt.Add(time.Hour), 24*time.Hour); !fitted {
+		t.Error("FitOrReuse() reused a snapshot without a fit configuration")
+	}
+}
+
+func TestSnapshotQuantiles(t *testing.T) {
+	properties := generateTestProperties(30)
+	for i := range properties {
+		properties[i].Rent += float64((i*7)%21-10) * 1000
+	}
+
+	model, err := NewAnalyzer(WithQuantiles(true)).Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	snapshot, err := NewSnapshot(model, time.Now())
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	restored, err := snapshot.Restore()
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	p := properties[3]
+	want := model.(*regressionModel).band(p)
+	got := restored.(*regressionModel).band(p)
+	if got == nil || *got != *want {
+		t.Errorf("Restored band = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"math"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+const (
+	// SeasonalFeatureCount is the number of month-of-year terms (sine and cosine).
+	SeasonalFeatureCount = 2
+
+	// MinSeasonalMonths is the minimum number of distinct months in the training
+	// data required to include month-of-year terms. With fewer months the terms
+	// are collinear with the intercept.
+	MinSeasonalMonths = 3
+)
+
+// latestFirstSeen returns the latest FirstSeen among the properties.
+// This is the reference time for time decay and the lookback window, so that
+// the same data always yields the same model (e.g., in backtests).
+func latestFirstSeen(properties []models.Property) time.Time {
+	var latest time.Time
+	for _, p := range properties {
+		if p.FirstSeen.After(latest) {
+			latest = p.FirstSeen
+		}
+	}
+	return latest
+}
+
+// withinLookback returns the properties first seen within the lookback window
+// before reference. Properties without FirstSeen are always kept.
+func (a *Analyzer) withinLookback(properties []models.Property, reference time.Time) []models.Property {
+	if a.lookback <= 0 {
+		return properties
+	}
+
+	from := reference.Add(-a.lookback)
+	result := make([]models.Property, 0, len(properties))
+	for _, p := range properties {
+		if p.FirstSeen.IsZero() || !p.FirstSeen.Before(from) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// timeWeights returns the regression weight of each property.
+// With time decay enabled the weight halves every half-life before reference:
+// w = 0.5^(age / halfLife). Properties without FirstSeen get weight 1.
+func (a *Analyzer) timeWeights(properties []models.Property, reference time.Time) []float64 {
+	weights := make([]float64, len(properties))
+	for i, p := range properties {
+		weights[i] = 1
+		if a.halfLife <= 0 || p.FirstSeen.IsZero() {
+			continue
+		}
+		age := reference.Sub(p.FirstSeen)
+		if age > 0 {
+			weights[i] = math.Pow(0.5, float64(age)/float64(a.halfLife))
+		}
+	}
+	return weights
+}
+
+// distinctMonths returns the number of distinct months of year in which
+// the properties were first seen.
+func distinctMonths(properties []models.Property) int {
+	months := make(map[time.Month]bool)
+	for _, p := range properties {
+		if !p.FirstSeen.IsZero() {
+			months[p.FirstSeen.Month()] = true
+		}
+	}
+	return len(months)
+}
+
+// seasonalTerms encodes the month of year as a point on the unit circle,
+// so that December and January are adjacent.
+func seasonalTerms(t time.Time) (sin, cos float64) {
+	angle := 2 * math.Pi * float64(t.Month()-1) / 12
+	return math.Sin(angle), math.Cos(angle)
+}
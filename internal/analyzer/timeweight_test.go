@@ -0,0 +1,127 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestTimeWeights(t *testing.T) {
+	reference := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	properties := []models.Property{
+		{FirstSeen: reference},
+		{FirstSeen: reference.Add(-30 * 24 * time.Hour)},
+		{FirstSeen: reference.Add(-60 * 24 * time.Hour)},
+		{}, // No FirstSeen
+	}
+
+	analyzer := NewAnalyzer(WithTimeDecay(30 * 24 * time.Hour))
+	weights := analyzer.timeWeights(properties, reference)
+
+	want := []float64{1, 0.5, 0.25, 1}
+	for i := range want {
+		if math.Abs(weights[i]-want[i]) > 1e-9 {
+			t.Errorf("weights[%d] = %f, want %f", i, weights[i], want[i])
+		}
+	}
+
+	for i, w := range NewAnalyzer().timeWeights(properties, reference) {
+		if w != 1 {
+			t.Errorf("weights[%d] without decay = %f, want 1", i, w)
+		}
+	}
+}
+
+func TestWithinLookback(t *testing.T) {
+	reference := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	properties := []models.Property{
+		{ID: "recent", FirstSeen: reference.Add(-24 * time.Hour)},
+		{ID: "old", FirstSeen: reference.Add(-400 * 24 * time.Hour)},
+		{ID: "undated"},
+	}
+
+	analyzer := NewAnalyzer(WithLookback(90 * 24 * time.Hour))
+	got := analyzer.withinLookback(properties, reference)
+
+	if len(got) != 2 || got[0].ID != "recent" || got[1].ID != "undated" {
+		t.Errorf("withinLookback() = %+v, want recent and undated", got)
+	}
+}
+
+func TestTimeDecayFollowsRecentPrices(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Same listings a year apart; rents rose by 10000 yen
+	old := generateTestProperties(30)
+	recent := generateTestProperties(30)
+	for i := range old {
+		old[i].FirstSeen = start.AddDate(0, 0, i)
+		recent[i].FirstSeen = start.AddDate(1, 0, i)
+		recent[i].Rent += 10000
+	}
+	properties := append(old, recent...)
+
+	target := recent[0]
+	actual := target.TotalRent()
+
+	plain, err := NewAnalyzer().fitRegression(properties)
+	if err != nil {
+		t.Fatalf("fitRegression failed: %v", err)
+	}
+	decayed, err := NewAnalyzer(WithTimeDecay(30 * 24 * time.Hour)).fitRegression(properties)
+	if err != nil {
+		t.Fatalf("fitRegression with decay failed: %v", err)
+	}
+
+	plainErr := math.Abs(NewAnalyzer().predict(target, plain) - actual)
+	decayedErr := math.Abs(NewAnalyzer().predict(target, decayed) - actual)
+	if decayedErr >= plainErr {
+		t.Errorf("Time decay error %f should be smaller than plain error %f", decayedErr, plainErr)
+	}
+	if decayedErr > 1000 {
+		t.Errorf("Time decay prediction should follow recent prices, error %f", decayedErr)
+	}
+}
+
+func TestSeasonality(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Rents are 8000 yen higher for listings first seen in February and March
+	properties := generateTestProperties(60)
+	for i := range properties {
+		properties[i].FirstSeen = start.AddDate(0, 0, i*6)
+		if m := properties[i].FirstSeen.Month(); m == time.February || m == time.March {
+			properties[i].Rent += 8000
+		}
+	}
+
+	model, err := NewAnalyzer(WithSeasonality(true)).fitRegression(properties)
+	if err != nil {
+		t.Fatalf("fitRegression with seasonality failed: %v", err)
+	}
+	if !model.seasonal {
+		t.Fatal("Model should include seasonal terms")
+	}
+
+	a := NewAnalyzer()
+	peak := models.Property{Area: 30, Age: 5, Floor: 3, WalkMinutes: 5, FirstSeen: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	offPeak := peak
+	offPeak.FirstSeen = time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	if a.predict(peak, model) <= a.predict(offPeak, model) {
+		t.Error("Peak season prediction should be higher than off-peak")
+	}
+
+	// A single month cannot identify seasonal terms
+	for i := range properties {
+		properties[i].FirstSeen = start
+	}
+	model, err = NewAnalyzer(WithSeasonality(true)).fitRegression(properties)
+	if err != nil {
+		t.Fatalf("fitRegression with single month failed: %v", err)
+	}
+	if model.seasonal {
+		t.Error("Seasonal terms should be skipped with fewer than MinSeasonalMonths months")
+	}
+}
@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TransformKind selects how a numeric feature enters the design matrix.
+type TransformKind string
+
+const (
+	// TransformLinear uses the raw value as a single column (default).
+	TransformLinear TransformKind = "linear"
+
+	// TransformSpline is a linear spline: the raw value plus max(0, x − knot)
+	// for each knot, so the slope can change at every knot.
+	TransformSpline TransformKind = "spline"
+
+	// TransformBins replaces the value with step indicators [x ≥ knot],
+	// so the effect is constant within each bin.
+	TransformBins TransformKind = "bins"
+)
+
+// FeatureTransform is the transform of a numeric feature.
+type FeatureTransform struct {
+	Kind  TransformKind `json:"kind"`
+	Knots []float64     `json:"knots,omitempty"` // Sorted ascending
+}
+
+// WithFeatureTransforms sets spline or binned transforms of numeric features
+// in the linear model, keyed by feature name ("area", "age", "floor",
+// "walk_minutes"). Features without a transform stay linear.
+func WithFeatureTransforms(transforms map[string]FeatureTransform) Option {
+	return func(a *Analyzer) {
+		a.transforms = transforms
+	}
+}
+
+// ParseFeatureTransforms parses transform specs keyed by feature name.
+// A spec is "linear", "spline(5|10|15)" or "bins(3|10|20)".
+func ParseFeatureTransforms(specs map[string]string) (map[string]FeatureTransform, error) {
+	transforms := make(map[string]FeatureTransform, len(specs))
+	for name, spec := range specs {
+		if f := lookupFeature(name); f == nil || f.Encoding != EncodingNumeric {
+			return nil, fmt.Errorf("unknown numeric feature: %q", name)
+		}
+		t, err := ParseFeatureTransform(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transform for %s: %w", name, err)
+		}
+		transforms[name] = t
+	}
+	return transforms, nil
+}
+
+// ParseFeatureTransform parses a transform spec such as "spline(5|10|15)".
+func ParseFeatureTransform(spec string) (FeatureTransform, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == string(TransformLinear) {
+		return FeatureTransform{Kind: TransformLinear}, nil
+	}
+
+	kind, args, ok := strings.Cut(spec, "(")
+	if !ok || !strings.HasSuffix(args, ")") {
+		return FeatureTransform{}, fmt.Errorf("malformed transform: %q", spec)
+	}
+	t := FeatureTransform{Kind: TransformKind(kind)}
+	if t.Kind != TransformSpline && t.Kind != TransformBins {
+		return FeatureTransform{}, fmt.Errorf("unknown transform: %q", kind)
+	}
+
+	for _, s := range strings.Split(strings.TrimSuffix(args, ")"), "|") {
+		knot, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return FeatureTransform{}, fmt.Errorf("invalid knot %q: %w", s, err)
+		}
+		t.Knots = append(t.Knots, knot)
+	}
+	slices.Sort(t.Knots)
+	t.Knots = slices.Compact(t.Knots)
+
+	return t, nil
+}
+
+// columns returns the number of design matrix columns of the transform.
+func (t FeatureTransform) columns() int {
+	switch t.Kind {
+	case TransformSpline:
+		return 1 + len(t.Knots)
+	case TransformBins:
+		return len(t.Knots)
+	default:
+		return 1
+	}
+}
+
+// expand writes the transformed columns of x into dst.
+func (t FeatureTransform) expand(x float64, dst []float64) {
+	switch t.Kind {
+	case TransformSpline:
+		dst[0] = x
+		for i, knot := range t.Knots {
+			dst[1+i] = max(0, x-knot)
+		}
+	case TransformBins:
+		for i, knot := range t.Knots {
+			dst[i] = 0
+			if x >= knot {
+				dst[i] = 1
+			}
+		}
+	default:
+		dst[0] = x
+	}
+}
+
+// names returns the design matrix column names of the transform for the feature.
+func (t FeatureTransform) names(feature string) []string {
+	knot := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+
+	switch t.Kind {
+	case TransformSpline:
+		names := []string{feature}
+		for _, k := range t.Knots {
+			names = append(names, feature+">"+knot(k))
+		}
+		return names
+	case TransformBins:
+		names := make([]string, len(t.Knots))
+		for i, k := range t.Knots {
+			names[i] = feature + ">=" + knot(k)
+		}
+		return names
+	default:
+		return []string{feature}
+	}
+}
@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestParseFeatureTransform(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    FeatureTransform
+		wantErr bool
+	}{
+		{spec: "linear", want: FeatureTransform{Kind: TransformLinear}},
+		{spec: "spline(10|5)", want: FeatureTransform{Kind: TransformSpline, Knots: []float64{5, 10}}},
+		{spec: "bins(1|5|10|20)", want: FeatureTransform{Kind: TransformBins, Knots: []float64{1, 5, 10, 20}}},
+		{spec: "spline()", wantErr: true},
+		{spec: "cubic(5)", wantErr: true},
+		{spec: "bins(3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseFeatureTransform(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFeatureTransform() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got.Kind != tt.want.Kind || !slices.Equal(got.Knots, tt.want.Knots)) {
+				t.Errorf("ParseFeatureTransform() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseFeatureTransforms(map[string]string{"rent": "linear"}); err == nil {
+		t.Error("ParseFeatureTransforms() with unknown feature should return an error")
+	}
+}
+
+func TestFeatureTransformExpand(t *testing.T) {
+	spline := FeatureTransform{Kind: TransformSpline, Knots: []float64{5, 10}}
+	got := make([]float64, spline.columns())
+	spline.expand(12, got)
+	if !slices.Equal(got, []float64{12, 7, 2}) {
+		t.Errorf("spline.expand(12) = %v, want [12 7 2]", got)
+	}
+	if names := spline.names("walk_minutes"); !slices.Equal(names, []string{"walk_minutes", "walk_minutes>5", "walk_minutes>10"}) {
+		t.Errorf("spline.names() = %v", names)
+	}
+
+	bins := FeatureTransform{Kind: TransformBins, Knots: []float64{1, 5, 10}}
+	got = make([]float64, bins.columns())
+	bins.expand(7, got)
+	if !slices.Equal(got, []float64{1, 1, 0}) {
+		t.Errorf("bins.expand(7) = %v, want [1 1 0]", got)
+	}
+}
+
+// generateNonlinearProperties generates properties whose rent barely depends
+// on walk minutes up to 5 and falls steeply beyond.
+func generateNonlinearProperties(n int) []models.Property {
+	properties := generateTestProperties(n)
+	for i := range properties {
+		walk := float64(properties[i].WalkMinutes)
+		properties[i].Rent = 50000 + 2000*properties[i].Area - 3000*math.Max(0, walk-5)
+	}
+	return properties
+}
+
+func TestSplineTransformFit(t *testing.T) {
+	properties := generateNonlinearProperties(60)
+
+	linear, err := NewAnalyzer().Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit(linear) error = %v", err)
+	}
+	spline, err := NewAnalyzer(WithFeatureTransforms(map[string]FeatureTransform{
+		"walk_minutes": {Kind: TransformSpline, Knots: []float64{5}},
+	})).Fit(properties)
+	if err != nil {
+		t.Fatalf("Fit(spline) error = %v", err)
+	}
+
+	if spline.Metrics().TrainRMSE >= linear.Metrics().TrainRMSE {
+		t.Errorf("Spline RMSE %.0f should be lower than linear RMSE %.0f",
+			spline.Metrics().TrainRMSE, linear.Metrics().TrainRMSE)
+	}
+	if spline.Metrics().TrainRMSE > 1 {
+		t.Errorf("Spline should fit the piecewise linear rent exactly, RMSE = %.2f", spline.Metrics().TrainRMSE)
+	}
+
+	// The spline columns are combined into a single explanation
+	contributions := NewAnalyzer().explain(properties[0], spline.(*regressionModel))
+	if len(contributions) != len(defaultFeatures) {
+		t.Errorf("explain() returned %d contributions, want %d", len(contributions), len(defaultFeatures))
+	}
+
+	// Transforms are persisted with the model
+	snapshot, err := NewSnapshot(spline, time.Now())
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	if !slices.Contains(snapshot.Features, "walk_minutes>5") {
+		t.Errorf("Snapshot features = %v, want walk_minutes>5", snapshot.Features)
+	}
+	restored, err := snapshot.Restore()
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	p := properties[7]
+	if restored.Predict(p) != spline.Predict(p) {
+		t.Errorf("Restored prediction = %f, want %f", restored.Predict(p), spline.Predict(p))
+	}
+}
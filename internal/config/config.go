@@ -3,12 +3,30 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/caarlos0/env/v11"
 )
 
+// Logging holds the logging configuration. It is part of Config, and loaded
+// alone by LoadLogging for commands that don't need the rest.
+type Logging struct {
+	// LogLevel is the minimum level of log records ("debug", "info", "warn" or "error").
+	LogLevel string `env:"LOG_LEVEL" envDefault:"info"`
+
+	// LogFormat is the format of log records ("json" or "text").
+	LogFormat string `env:"LOG_FORMAT" envDefault:"json"`
+}
+
 // Config holds the application configuration loaded from environment variables.
 type Config struct {
+	// Profile is the name of the active configuration profile.
+	// Variables prefixed with PROFILE_<NAME>_ override the unprefixed ones.
+	Profile string `env:"PROFILE" envDefault:"default"`
+
 	// BucketName is the S3 bucket name for storing property data.
 	BucketName string `env:"BUCKET_NAME,required"`
 
@@ -18,18 +36,284 @@ type Config struct {
 	// MaxPage is the maximum number of SUUMO pages to scrape.
 	MaxPage int `env:"MAX_PAGE" envDefault:"30"`
 
+	// MaxDetailPages is the maximum number of property detail pages fetched
+	// per run for facilities and structure. Properties whose details are
+	// already stored are not fetched again. 0 disables detail scraping.
+	MaxDetailPages int `env:"MAX_DETAIL_PAGES" envDefault:"50"`
+
 	// SuumoSearchURL is the SUUMO search result URL to scrape.
 	SuumoSearchURL string `env:"SUUMO_SEARCH_URL,required"`
 
+	Logging
+
+	// MetricsNamespace is the CloudWatch namespace of the metrics written in
+	// Embedded Metric Format.
+	MetricsNamespace string `env:"METRICS_NAMESPACE" envDefault:"SUUMOHunter"`
+
+	// DryRun runs the pipeline without uploading to S3 or sending notifications.
+	// What would be uploaded and sent is logged instead.
+	DryRun bool `env:"DRY_RUN" envDefault:"false"`
+
+	// ServerAddr is the listen address of the server (cmd/server).
+	ServerAddr string `env:"SERVER_ADDR" envDefault:":8080"`
+
+	// Schedules maps the jobs run by the server to cron expressions
+	// (minute hour day month weekday), separated by ";".
+	// Format: "scrape=15 9,15,18,22 * * *;report=0 10 * * 1".
+	Schedules map[string]string `env:"SCHEDULES" envSeparator:";" envKeyValSeparator:"=" envDefault:"scrape=15 9,15,18,22 * * *"`
+
+	// ScheduleTimezone is the time zone of Schedules.
+	ScheduleTimezone string `env:"SCHEDULE_TIMEZONE" envDefault:"Asia/Tokyo"`
+
+	// ScheduleJitter is the maximum random delay of scheduled runs (e.g., "1m").
+	ScheduleJitter time.Duration `env:"SCHEDULE_JITTER" envDefault:"1m"`
+
+	// ShutdownTimeout is how long the server waits for a running job to
+	// finish on shutdown before canceling it.
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"10m"`
+
 	// DiscordWebhookURL is the Discord Webhook URL for notifications.
 	DiscordWebhookURL string `env:"DISCORD_WEBHOOK_URL,required"`
+
+	// ComparablesK is the number of comparable listings used for the
+	// comparables-based rent estimate. Zero disables the estimate.
+	ComparablesK int `env:"COMPARABLES_K" envDefault:"5"`
+
+	// NeighborStations maps a station to its neighboring stations, used when
+	// searching for comparable listings. Format: "中野:東中野|新中野,高円寺:阿佐ヶ谷".
+	NeighborStations map[string]string `env:"NEIGHBOR_STATIONS"`
+
+	// RentModel selects the rent model used for scoring ("ols" or "forest").
+	RentModel string `env:"RENT_MODEL" envDefault:"ols"`
+
+	// ForestTrees is the number of trees when RentModel is "forest".
+	ForestTrees int `env:"FOREST_TREES" envDefault:"100"`
+
+	// QuantileRegression adds a rent band (10th/50th/90th percentiles) and the
+	// percentile rank of the rent to notifications. Only supported by the "ols" model.
+	QuantileRegression bool `env:"QUANTILE_REGRESSION" envDefault:"false"`
+
+	// FeatureTransforms sets spline or binned transforms of numeric features in
+	// the linear model. Format: "walk_minutes:spline(5|10),age:bins(1|5|10|20)".
+	// Features without a transform (area, age, floor, walk_minutes) stay linear.
+	FeatureTransforms map[string]string `env:"FEATURE_TRANSFORMS"`
+
+	// ModelKey is the S3 object key for the persisted rent model.
+	ModelKey string `env:"MODEL_KEY" envDefault:"model.json"`
+
+	// ModelRefitInterval is the minimum interval between model refits
+	// (e.g., "24h"). The persisted model is reused in between. Zero refits on every run.
+	ModelRefitInterval time.Duration `env:"MODEL_REFIT_INTERVAL" envDefault:"0"`
+
+	// OperatorWebhookURL is the Discord Webhook URL for operator alerts such as
+	// model drift. Empty only logs the alerts.
+	OperatorWebhookURL string `env:"OPERATOR_WEBHOOK_URL"`
+
+	// DriftCoefficientThreshold is the maximum relative change of a model
+	// coefficient between fits before alerting (0.5 = 50%). Zero disables the check.
+	DriftCoefficientThreshold float64 `env:"DRIFT_COEFFICIENT_THRESHOLD" envDefault:"0.5"`
+
+	// DriftRMSEThreshold is the maximum relative change of the validation RMSE
+	// between fits before alerting. Zero disables the check.
+	DriftRMSEThreshold float64 `env:"DRIFT_RMSE_THRESHOLD" envDefault:"0.3"`
+
+	// DriftDistributionThreshold is the maximum shift of a feature mean between
+	// fits, in standard deviations, before alerting. Zero disables the check.
+	DriftDistributionThreshold float64 `env:"DRIFT_DISTRIBUTION_THRESHOLD" envDefault:"1.0"`
+
+	// RegressionHalfLife is the half-life of the recency weighting in the
+	// regression (e.g., "720h"). Zero disables time decay.
+	RegressionHalfLife time.Duration `env:"REGRESSION_HALF_LIFE" envDefault:"0"`
+
+	// RegressionLookback restricts the regression to listings first seen within
+	// this window (e.g., "2160h"). Zero uses all listings.
+	RegressionLookback time.Duration `env:"REGRESSION_LOOKBACK" envDefault:"0"`
+
+	// RegressionSeasonality adds month-of-year terms to the regression.
+	RegressionSeasonality bool `env:"REGRESSION_SEASONALITY" envDefault:"false"`
+
+	// RegressionLayout adds layout dummies to the regression.
+	RegressionLayout bool `env:"REGRESSION_LAYOUT" envDefault:"false"`
+
+	// MarketIndexKey is the S3 object key for the persisted market index.
+	MarketIndexKey string `env:"MARKET_INDEX_KEY" envDefault:"market_index.json"`
+
+	// MarketIndexPeriod is the aggregation period of the market index ("week" or "month").
+	MarketIndexPeriod string `env:"MARKET_INDEX_PERIOD" envDefault:"month"`
+
+	// MarketReportInterval is the minimum interval between market report
+	// notifications (e.g., "168h"). Zero disables the report.
+	MarketReportInterval time.Duration `env:"MARKET_REPORT_INTERVAL" envDefault:"0"`
+
+	// ClustersKey is the S3 object key for the persisted history of duplicate listing clusters.
+	ClustersKey string `env:"CLUSTERS_KEY" envDefault:"listing_clusters.json"`
+
+	// DuplicateAreaTolerance is the maximum area difference (m²) between
+	// listings of the same unit.
+	DuplicateAreaTolerance float64 `env:"DUPLICATE_AREA_TOLERANCE" envDefault:"1.0"`
+
+	// ReappearanceGap is the minimum absence after which a unit listed again
+	// counts as a reappearance (e.g., "168h").
+	ReappearanceGap time.Duration `env:"REAPPEARANCE_GAP" envDefault:"168h"`
+
+	// BuildingsKey is the S3 object key for the persisted buildings and their units.
+	BuildingsKey string `env:"BUILDINGS_KEY" envDefault:"buildings.json"`
+
+	// Notification filters. Only new properties satisfying all set rules are
+	// notified; zero values disable a rule.
+
+	// FilterMaxTotalRent is the maximum total rent (yen, management fee included).
+	FilterMaxTotalRent float64 `env:"FILTER_MAX_TOTAL_RENT"`
+
+	// FilterMinArea is the minimum area (m²).
+	FilterMinArea float64 `env:"FILTER_MIN_AREA"`
+
+	// FilterLayouts lists the allowed layouts (e.g., "1LDK,2K").
+	FilterLayouts []string `env:"FILTER_LAYOUTS"`
+
+	// FilterMaxWalkMinutes is the maximum walking time to the station (minutes).
+	FilterMaxWalkMinutes int `env:"FILTER_MAX_WALK_MINUTES"`
+
+	// FilterStations lists the allowed stations (e.g., "中野,高円寺").
+	FilterStations []string `env:"FILTER_STATIONS"`
+
+	// FilterMaxAge is the maximum building age (years).
+	FilterMaxAge int `env:"FILTER_MAX_AGE"`
+
+	// FilterMinFloor is the minimum floor.
+	FilterMinFloor int `env:"FILTER_MIN_FLOOR"`
+
+	// FilterExcludeGroundFloor excludes 1st floor units.
+	FilterExcludeGroundFloor bool `env:"FILTER_EXCLUDE_GROUND_FLOOR" envDefault:"false"`
+
+	// FilterOnlyBargains only notifies properties labeled as bargains.
+	FilterOnlyBargains bool `env:"FILTER_ONLY_BARGAINS" envDefault:"false"`
+
+	// FilterIncludeKeywords requires the property name to contain one of the keywords.
+	FilterIncludeKeywords []string `env:"FILTER_INCLUDE_KEYWORDS"`
+
+	// FilterExcludeKeywords excludes properties whose name contains any of the keywords.
+	FilterExcludeKeywords []string `env:"FILTER_EXCLUDE_KEYWORDS"`
+
+	// FilterMaxMetrics sets maximum values of property metrics (total_rent,
+	// rent_per_area, total_rent_per_area or monthly_cost).
+	// Format: "rent_per_area:3500,monthly_cost:110000".
+	FilterMaxMetrics map[string]float64 `env:"FILTER_MAX_METRICS"`
+
+	// NotifySort is the order of properties in notifications
+	// ("score", "price_per_area", "walk", "newest" or "none" for scrape order).
+	NotifySort string `env:"NOTIFY_SORT" envDefault:"score"`
+
+	// NotifyMaxProperties is the number of properties shown in a notification.
+	// The rest are summarized by label.
+	NotifyMaxProperties int `env:"NOTIFY_MAX_PROPERTIES" envDefault:"10"`
+
+	// DigestMode accumulates new properties, price drops and delistings and
+	// sends them as a digest ("off", "daily" or "weekly").
+	DigestMode string `env:"DIGEST_MODE" envDefault:"off"`
+
+	// DigestTimes are the times of day digests are sent (e.g., "08:00,20:00").
+	DigestTimes []string `env:"DIGEST_TIMES" envDefault:"08:00"`
+
+	// DigestWeekday is the day of the week weekly digests are sent ("mon" to "sun").
+	DigestWeekday string `env:"DIGEST_WEEKDAY" envDefault:"mon"`
+
+	// DigestTimezone is the time zone of DigestTimes.
+	DigestTimezone string `env:"DIGEST_TIMEZONE" envDefault:"Asia/Tokyo"`
+
+	// RunsPrefix is the S3 key prefix of the run records, stored as
+	// <prefix>YYYY/MM/DD/<run ID>.json.
+	RunsPrefix string `env:"RUNS_PREFIX" envDefault:"runs/"`
+
+	// DigestKey is the S3 object key for the accumulated digest.
+	DigestKey string `env:"DIGEST_KEY" envDefault:"digest.json"`
+
+	// DigestImmediateThreshold is the bargain score (yen) from which properties
+	// are notified immediately in digest mode. Zero disables immediate alerts.
+	DigestImmediateThreshold float64 `env:"DIGEST_IMMEDIATE_THRESHOLD" envDefault:"30000"`
+
+	// NotifyExplanations adds a per-feature explanation line to notifications.
+	NotifyExplanations bool `env:"NOTIFY_EXPLANATIONS" envDefault:"false"`
 }
 
-// Load loads configuration from environment variables.
+// StationNeighbors returns NeighborStations with the "|"-separated
+// neighbor lists split into slices.
+func (c *Config) StationNeighbors() map[string][]string {
+	neighbors := make(map[string][]string, len(c.NeighborStations))
+	for station, list := range c.NeighborStations {
+		for _, neighbor := range strings.Split(list, "|") {
+			if neighbor = strings.TrimSpace(neighbor); neighbor != "" {
+				neighbors[station] = append(neighbors[station], neighbor)
+			}
+		}
+	}
+	return neighbors
+}
+
+// Load loads configuration from environment variables for the profile named
+// by PROFILE.
 func Load() (*Config, error) {
+	return LoadProfile(os.Getenv("PROFILE"))
+}
+
+// LoadProfile loads configuration from environment variables for the named
+// profile. Variables prefixed with PROFILE_<NAME>_ (the name upper-cased, with
+// non-alphanumeric characters replaced by "_") take precedence, so e.g.
+// PROFILE_FAMILY_SUUMO_SEARCH_URL overrides SUUMO_SEARCH_URL for the "family" profile.
+// An empty name loads the default profile without overrides.
+func LoadProfile(name string) (*Config, error) {
 	cfg := &Config{}
-	if err := env.Parse(cfg); err != nil {
+	if err := env.ParseWithOptions(cfg, env.Options{Environment: profileEnvironment(name)}); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	if cfg.RentModel != "ols" && cfg.RentModel != "forest" {
+		return nil, fmt.Errorf("invalid RENT_MODEL: %q", cfg.RentModel)
+	}
+	if cfg.MarketIndexPeriod != "week" && cfg.MarketIndexPeriod != "month" {
+		return nil, fmt.Errorf("invalid MARKET_INDEX_PERIOD: %q", cfg.MarketIndexPeriod)
+	}
 	return cfg, nil
 }
+
+// LoadLogging loads the logging configuration of the profile named by
+// PROFILE, without requiring the variables of the rest of the configuration.
+func LoadLogging() (*Logging, error) {
+	cfg := &Logging{}
+	if err := env.ParseWithOptions(cfg, env.Options{Environment: profileEnvironment(os.Getenv("PROFILE"))}); err != nil {
+		return nil, fmt.Errorf("failed to parse logging config: %w", err)
+	}
+	return cfg, nil
+}
+
+// profileEnvironment returns the environment variables with the overrides
+// of the named profile applied.
+func profileEnvironment(name string) map[string]string {
+	environment := env.ToMap(os.Environ())
+	if name == "" {
+		return environment
+	}
+	prefix := ProfilePrefix(name)
+	overrides := make(map[string]string)
+	for key, value := range environment {
+		if strings.HasPrefix(key, prefix) {
+			overrides[strings.TrimPrefix(key, prefix)] = value
+		}
+	}
+	for key, value := range overrides {
+		environment[key] = value
+	}
+	environment["PROFILE"] = name
+	return environment
+}
+
+// ProfilePrefix returns the environment variable prefix of a profile
+// (e.g., "PROFILE_FAMILY_" for "family").
+func ProfilePrefix(name string) string {
+	upper := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+	return "PROFILE_" + upper + "_"
+}
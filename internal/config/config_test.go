@@ -0,0 +1,133 @@
+package config
+
+import (
+	"testing"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("BUCKET_NAME", "bucket")
+	t.Setenv("SUUMO_SEARCH_URL", "https://suumo.jp/search")
+	t.Setenv("DISCORD_WEBHOOK_URL", "https://discord.com/api/webhooks/test")
+}
+
+func TestLoadProfile(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("MAX_PAGE", "5")
+	t.Setenv("FEATURE_TRANSFORMS", "walk_minutes:spline(5|10)")
+	t.Setenv("PROFILE_FAMILY_SUUMO_SEARCH_URL", "https://suumo.jp/family")
+	t.Setenv("PROFILE_FAMILY_FEATURE_TRANSFORMS", "age:bins(1|5|10)")
+
+	cfg, err := LoadProfile("")
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if cfg.Profile != "default" || cfg.SuumoSearchURL != "https://suumo.jp/search" {
+		t.Errorf("Default profile = (%q, %q)", cfg.Profile, cfg.SuumoSearchURL)
+	}
+	if cfg.FeatureTransforms["walk_minutes"] != "spline(5|10)" {
+		t.Errorf("FeatureTransforms = %v", cfg.FeatureTransforms)
+	}
+
+	cfg, err = LoadProfile("family")
+	if err != nil {
+		t.Fatalf("LoadProfile(family) error = %v", err)
+	}
+	if cfg.Profile != "family" {
+		t.Errorf("Profile = %q, want family", cfg.Profile)
+	}
+	if cfg.SuumoSearchURL != "https://suumo.jp/family" {
+		t.Errorf("SuumoSearchURL = %q, want profile override", cfg.SuumoSearchURL)
+	}
+	if cfg.MaxPage != 5 {
+		t.Errorf("MaxPage = %d, want 5 (inherited)", cfg.MaxPage)
+	}
+	if len(cfg.FeatureTransforms) != 1 || cfg.FeatureTransforms["age"] != "bins(1|5|10)" {
+		t.Errorf("FeatureTransforms = %v, want profile override", cfg.FeatureTransforms)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("RENT_MODEL", "svm")
+	if _, err := Load(); err == nil {
+		t.Error("Load() with invalid RENT_MODEL should return an error")
+	}
+}
+
+func TestProfilePrefix(t *testing.T) {
+	tests := map[string]string{
+		"family":      "PROFILE_FAMILY_",
+		"nakano-1ldk": "PROFILE_NAKANO_1LDK_",
+	}
+	for name, want := range tests {
+		if got := ProfilePrefix(name); got != want {
+			t.Errorf("ProfilePrefix(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLoadFilters(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("FILTER_LAYOUTS", "1LDK,2K")
+	t.Setenv("FILTER_MAX_TOTAL_RENT", "120000")
+	t.Setenv("FILTER_EXCLUDE_GROUND_FLOOR", "true")
+	t.Setenv("FILTER_MAX_METRICS", "rent_per_area:3500,monthly_cost:110000")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.FilterLayouts) != 2 || cfg.FilterLayouts[1] != "2K" {
+		t.Errorf("FilterLayouts = %v, want [1LDK 2K]", cfg.FilterLayouts)
+	}
+	if cfg.FilterMaxTotalRent != 120000 || !cfg.FilterExcludeGroundFloor {
+		t.Errorf("Filters = (%v, %v)", cfg.FilterMaxTotalRent, cfg.FilterExcludeGroundFloor)
+	}
+	if len(cfg.FilterMaxMetrics) != 2 || cfg.FilterMaxMetrics["rent_per_area"] != 3500 {
+		t.Errorf("FilterMaxMetrics = %v", cfg.FilterMaxMetrics)
+	}
+	if cfg.FilterStations != nil || cfg.FilterMinArea != 0 {
+		t.Errorf("Unset filters should be disabled, got (%v, %v)", cfg.FilterStations, cfg.FilterMinArea)
+	}
+}
+
+func TestLoadSchedules(t *testing.T) {
+	setRequiredEnv(t)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Schedules) != 1 || cfg.Schedules["scrape"] != "15 9,15,18,22 * * *" {
+		t.Errorf("Default Schedules = %v", cfg.Schedules)
+	}
+
+	t.Setenv("SCHEDULES", "scrape=*/30 * * * *;digest=0 8 * * 1-5")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := map[string]string{"scrape": "*/30 * * * *", "digest": "0 8 * * 1-5"}
+	if len(cfg.Schedules) != len(want) {
+		t.Fatalf("Schedules = %v, want %v", cfg.Schedules, want)
+	}
+	for job, expr := range want {
+		if cfg.Schedules[job] != expr {
+			t.Errorf("Schedules[%q] = %q, want %q", job, cfg.Schedules[job], expr)
+		}
+	}
+}
+
+func TestLoadLogging(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	t.Setenv("PROFILE", "family")
+	t.Setenv("PROFILE_FAMILY_LOG_FORMAT", "text")
+
+	cfg, err := LoadLogging()
+	if err != nil {
+		t.Fatalf("LoadLogging() error = %v", err)
+	}
+	if cfg.LogLevel != "warn" || cfg.LogFormat != "text" {
+		t.Errorf("LoadLogging() = %+v, want warn/text without required variables", *cfg)
+	}
+}
@@ -0,0 +1,191 @@
+// Package dashboard serves a read-only web dashboard over the stored data:
+// the listed properties with their bargain scores, per-station summaries,
+// the price history of each unit and the latest model report.
+package dashboard
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/analyzer"
+	"github.com/alp/suumo-hunter/internal/config"
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+	"github.com/alp/suumo-hunter/internal/storage"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// pages are the templates of the pages, each rendered in layout.html.
+var pages = []string{"properties.html", "stations.html", "building.html", "model.html"}
+
+// Server serves the dashboard. The stored data is loaded on the first
+// request and reloaded when it is older than the refresh interval.
+type Server struct {
+	cfg      *config.Config
+	store    *storage.Storage
+	analyzer *analyzer.Analyzer
+	logger   *slog.Logger
+	refresh  time.Duration
+
+	templates map[string]*template.Template
+	mux       *http.ServeMux
+
+	mu       sync.Mutex
+	data     *data
+	loadedAt time.Time
+}
+
+// Option is a functional option for configuring the Server.
+type Option func(*Server)
+
+// WithLogger sets the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithRefreshInterval sets how long loaded data is served before it is
+// reloaded from the store. Zero reloads on every request.
+func WithRefreshInterval(d time.Duration) Option {
+	return func(s *Server) {
+		s.refresh = d
+	}
+}
+
+// New creates a Server reading the keys of cfg from the store. Properties
+// are scored with the persisted model if it matches the analyzer's model
+// type, or with a model fitted in memory otherwise.
+func New(cfg *config.Config, store *storage.Storage, a *analyzer.Analyzer, opts ...Option) (*Server, error) {
+	s := &Server{
+		cfg:       cfg,
+		store:     store,
+		analyzer:  a,
+		logger:    slog.Default(),
+		refresh:   5 * time.Minute,
+		templates: make(map[string]*template.Template),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for _, page := range pages {
+		t, err := template.New("layout.html").Funcs(funcs).ParseFS(templateFS, "templates/layout.html", "templates/"+page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", page, err)
+		}
+		s.templates[page] = t
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /{$}", s.handleProperties)
+	s.mux.HandleFunc("GET /stations", s.handleStations)
+	s.mux.HandleFunc("GET /building", s.handleBuilding)
+	s.mux.HandleFunc("GET /model", s.handleModel)
+	return s, nil
+}
+
+// ServeHTTP serves the dashboard pages.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// data is the stored data shown by the dashboard.
+type data struct {
+	AsOf       time.Time                    // Time of the latest run (LastSeen of listed properties)
+	Properties []models.Property            // All stored properties
+	Listed     []notifier.PropertyWithScore // Properties listed in the latest run, scored if a model is available
+	Buildings  models.BuildingHistory
+	Market     models.MarketIndex
+	Model      analyzer.RentModel      // Nil if no model is available
+	Snapshot   *analyzer.ModelSnapshot // Persisted model, nil if none
+	ModelError string                  // Why no model is available
+}
+
+// load returns the stored data, reloading it if it is older than the refresh interval.
+func (s *Server) load(ctx context.Context) (*data, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data != nil && time.Since(s.loadedAt) < s.refresh {
+		return s.data, nil
+	}
+
+	d, err := s.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.data, s.loadedAt = d, time.Now()
+	return d, nil
+}
+
+// fetch downloads the stored data and scores the listed properties.
+func (s *Server) fetch(ctx context.Context) (*data, error) {
+	properties, err := s.store.Download(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d := &data{Properties: properties}
+	if _, err := s.store.DownloadJSON(ctx, s.cfg.BuildingsKey, &d.Buildings); err != nil {
+		return nil, fmt.Errorf("failed to download buildings: %w", err)
+	}
+	if _, err := s.store.DownloadJSON(ctx, s.cfg.MarketIndexKey, &d.Market); err != nil {
+		return nil, fmt.Errorf("failed to download market index: %w", err)
+	}
+	var snapshot analyzer.ModelSnapshot
+	found, err := s.store.DownloadJSON(ctx, s.cfg.ModelKey, &snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download model: %w", err)
+	}
+	if found {
+		d.Snapshot = &snapshot
+	}
+
+	var listed []models.Property
+	for _, p := range properties {
+		if p.LastSeen.After(d.AsOf) {
+			d.AsOf = p.LastSeen
+		}
+	}
+	for _, p := range properties {
+		if p.IsListed(d.AsOf) {
+			listed = append(listed, p)
+		}
+	}
+
+	// The persisted model is never refitted: the dashboard is read-only
+	d.Model, _, err = s.analyzer.FitOrReuse(properties, d.Snapshot, time.Now(), math.MaxInt64)
+	if err != nil {
+		s.logger.WarnContext(ctx, "No model available, properties are not scored", "error", err)
+		d.ModelError = err.Error()
+	}
+	d.Listed = s.analyzer.Score(d.Model, properties, listed)
+
+	s.logger.InfoContext(ctx, "Loaded stored data", "properties", len(properties), "listed", len(listed),
+		"buildings", len(d.Buildings.Buildings))
+	return d, nil
+}
+
+// render writes the page with the view data.
+func (s *Server) render(w http.ResponseWriter, r *http.Request, page string, view any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates[page].Execute(w, view); err != nil {
+		s.logger.ErrorContext(r.Context(), "Failed to render page", "page", page, "error", err)
+	}
+}
+
+// fail responds with the error, logging server errors.
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		s.logger.ErrorContext(r.Context(), "Request failed", "path", r.URL.Path, "error", err)
+	}
+	http.Error(w, err.Error(), status)
+}
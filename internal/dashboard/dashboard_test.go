@@ -0,0 +1,243 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/analyzer"
+	"github.com/alp/suumo-hunter/internal/config"
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/storage"
+)
+
+// testNow is the time of the latest run in the test store.
+var testNow = time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+// testProperties returns n properties near 中野 and 高円寺. The last one was
+// not listed in the latest run.
+func testProperties(n int) []models.Property {
+	properties := make([]models.Property, n)
+	for i := range properties {
+		area := 20.0 + float64(i%10)*3
+		age := i % 20
+		walk := i%15 + 1
+		station := "中野"
+		if i%3 == 0 {
+			station = "高円寺"
+		}
+		properties[i] = models.Property{
+			ID:             fmt.Sprintf("jnc_%012d", i),
+			Name:           fmt.Sprintf("テストマンション%d", i),
+			Address:        "東京都中野区中野1-1-1",
+			Age:            age,
+			Floor:          i%5 + 1,
+			Rent:           50000 + 2000*area - 500*float64(age) - 500*float64(walk),
+			ManagementFee:  5000,
+			Layout:         "1K",
+			Area:           area,
+			WalkMinutes:    walk,
+			NearestStation: station,
+			URL:            fmt.Sprintf("https://suumo.jp/chintai/jnc_%012d/", i),
+			FirstSeen:      testNow.Add(-time.Duration(i) * time.Hour),
+			LastSeen:       testNow,
+		}
+	}
+	properties[n-1].LastSeen = testNow.Add(-24 * time.Hour)
+	return properties
+}
+
+// setup returns a dashboard server over a local store with n properties,
+// a building whose unit changed price and a market index.
+func setup(t *testing.T, n int, opts ...Option) (*Server, *storage.Storage) {
+	t.Helper()
+	cfg := &config.Config{
+		BucketName:     "bucket",
+		BucketKey:      "properties.csv",
+		ModelKey:       "model.json",
+		BuildingsKey:   "buildings.json",
+		MarketIndexKey: "market_index.json",
+	}
+	store := storage.NewStorage(storage.NewLocalClient(t.TempDir()), cfg.BucketName, cfg.BucketKey)
+	ctx := context.Background()
+
+	properties := testProperties(n)
+	if err := store.Upload(ctx, properties); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	unit := properties[0]
+	buildings := models.UpdateBuildings(models.BuildingHistory{}, []models.Property{unit}, testNow.Add(-48*time.Hour))
+	unit.Rent -= 3000
+	buildings = models.UpdateBuildings(buildings, []models.Property{unit}, testNow)
+	if err := store.UploadJSON(ctx, cfg.BuildingsKey, buildings); err != nil {
+		t.Fatalf("UploadJSON() error = %v", err)
+	}
+	market := models.MarketIndex{Period: models.IndexPeriodMonth, GeneratedAt: testNow, Stations: []models.IndexSeries{{
+		Name: "中野",
+		Points: []models.IndexPoint{
+			{Start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Value: 100000, Count: 5},
+			{Start: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Value: 102000, Count: 6},
+		},
+	}}}
+	if err := store.UploadJSON(ctx, cfg.MarketIndexKey, market); err != nil {
+		t.Fatalf("UploadJSON() error = %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s, err := New(cfg, store, analyzer.NewAnalyzer(), append([]Option{WithLogger(logger)}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s, store
+}
+
+// get requests the path and returns the status code and body.
+func get(t *testing.T, s *Server, path string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	body, _ := io.ReadAll(rec.Body)
+	return rec.Code, string(body)
+}
+
+func TestPropertiesPage(t *testing.T) {
+	s, _ := setup(t, 30)
+
+	code, body := get(t, s, "/")
+	if code != http.StatusOK {
+		t.Fatalf("GET / = %d: %s", code, body)
+	}
+	if !strings.Contains(body, "掲載中の 29 件中 29 件を表示") {
+		t.Errorf("GET / should list the 29 listed properties")
+	}
+	if strings.Contains(body, "テストマンション29<") {
+		t.Errorf("GET / should not list the delisted property")
+	}
+	if strings.Contains(body, "分析中") {
+		t.Errorf("GET / should show scored properties")
+	}
+
+	code, body = get(t, s, "/?station="+url.QueryEscape("高円寺")+"&max_rent=12&sort=total_rent&order=asc")
+	if code != http.StatusOK {
+		t.Fatalf("GET filtered = %d: %s", code, body)
+	}
+	if strings.Contains(body, "<td>中野</td>") || !strings.Contains(body, "<td>高円寺</td>") {
+		t.Errorf("GET filtered should only list properties near 高円寺")
+	}
+	// The first listed property is the cheapest one near 高円寺 (index 0)
+	if i, j := strings.Index(body, "テストマンション0<"), strings.Index(body, "テストマンション3<"); i < 0 || j < 0 || i > j {
+		t.Errorf("GET sorted by total rent: want テストマンション0 before テストマンション3")
+	}
+
+	for _, path := range []string{"/?sort=random", "/?max_rent=abc", "/?min_area=-1"} {
+		if code, _ := get(t, s, path); code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", path, code)
+		}
+	}
+}
+
+func TestPropertiesPageWithoutModel(t *testing.T) {
+	s, _ := setup(t, 3)
+
+	code, body := get(t, s, "/")
+	if code != http.StatusOK {
+		t.Fatalf("GET / = %d: %s", code, body)
+	}
+	if !strings.Contains(body, "モデルがないため未採点") || !strings.Contains(body, "分析中") {
+		t.Errorf("GET / without enough data should list unscored properties")
+	}
+
+	if code, body := get(t, s, "/model"); code != http.StatusOK || !strings.Contains(body, "モデルがありません") {
+		t.Errorf("GET /model = %d, want a page without a model", code)
+	}
+}
+
+func TestStationsPage(t *testing.T) {
+	s, _ := setup(t, 30)
+
+	code, body := get(t, s, "/stations")
+	if code != http.StatusOK {
+		t.Fatalf("GET /stations = %d: %s", code, body)
+	}
+	for _, want := range []string{"中野", "高円寺", "10.2万円", "2.0%"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("GET /stations lacks %q", want)
+		}
+	}
+}
+
+func TestBuildingPage(t *testing.T) {
+	s, _ := setup(t, 30)
+	key := models.BuildingKey(testProperties(1)[0])
+
+	code, body := get(t, s, "/building?key="+url.QueryEscape(key))
+	if code != http.StatusOK {
+		t.Fatalf("GET /building = %d: %s", code, body)
+	}
+	if !strings.Contains(body, "2025-01-13 9.4万円 → 2025-01-15 9.2万円（-3000円）") {
+		t.Errorf("GET /building lacks the price history:\n%s", body)
+	}
+
+	if code, _ := get(t, s, "/building?key=unknown"); code != http.StatusNotFound {
+		t.Errorf("GET /building with an unknown key = %d, want 404", code)
+	}
+}
+
+func TestModelPage(t *testing.T) {
+	s, store := setup(t, 30)
+
+	// Without a persisted model, a model is fitted on the stored data
+	code, body := get(t, s, "/model")
+	if code != http.StatusOK {
+		t.Fatalf("GET /model = %d: %s", code, body)
+	}
+	if !strings.Contains(body, "validation_rmse") || !strings.Contains(body, "保存データから学習") {
+		t.Errorf("GET /model should show the report of a fitted model:\n%s", body)
+	}
+
+	// The persisted model is shown once the data is reloaded
+	a := analyzer.NewAnalyzer()
+	model, err := a.Fit(testProperties(30))
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	snapshot, err := analyzer.NewSnapshot(model, testNow)
+	if err != nil {
+		t.Fatalf("NewSnapshot() error = %v", err)
+	}
+	if err := store.UploadJSON(context.Background(), "model.json", snapshot); err != nil {
+		t.Fatalf("UploadJSON() error = %v", err)
+	}
+	s.refresh = 0
+
+	_, body = get(t, s, "/model")
+	if !strings.Contains(body, "保存済みモデル: 2025-01-15 09:00 学習") || strings.Contains(body, "保存データから学習") {
+		t.Errorf("GET /model should show the persisted model:\n%s", body)
+	}
+}
+
+func TestRefreshInterval(t *testing.T) {
+	s, store := setup(t, 30, WithRefreshInterval(time.Hour))
+	if _, body := get(t, s, "/"); !strings.Contains(body, "29 件中") {
+		t.Fatalf("GET / should list 29 properties")
+	}
+
+	if err := store.Upload(context.Background(), testProperties(20)); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if _, body := get(t, s, "/"); !strings.Contains(body, "29 件中") {
+		t.Errorf("GET / within the refresh interval should serve the loaded data")
+	}
+
+	s.refresh = 0
+	if _, body := get(t, s, "/"); !strings.Contains(body, "19 件中") {
+		t.Errorf("GET / after the refresh interval should reload the data")
+	}
+}
@@ -0,0 +1,394 @@
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/analyzer"
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+// funcs are the template functions.
+var funcs = template.FuncMap{
+	"man":      func(yen float64) string { return fmt.Sprintf("%.1f万円", yen/10000) },
+	"yen":      func(yen float64) string { return fmt.Sprintf("%+.0f円", yen) },
+	"date":     formatTime("2006-01-02"),
+	"datetime": formatTime("2006-01-02 15:04"),
+	"pct":      func(v float64) string { return fmt.Sprintf("%+.1f%%", v*100) },
+	"buildingKey": func(p models.Property) string {
+		return models.BuildingKey(p)
+	},
+	"query": func(key, value string) template.URL { return template.URL(key + "=" + url.QueryEscape(value)) },
+}
+
+// formatTime returns a template function formatting times with the layout,
+// or "-" for the zero time.
+func formatTime(layout string) func(t time.Time) string {
+	return func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format(layout)
+	}
+}
+
+// column is a sortable column of the properties table.
+type column struct {
+	Name  string
+	Label string
+	value func(p notifier.PropertyWithScore) float64
+}
+
+// columns returns the sortable columns: the score, the registered property
+// metrics and the basic attributes.
+func columns() []column {
+	cols := []column{{Name: "score", Label: "お得度", value: func(p notifier.PropertyWithScore) float64 { return p.Score }}}
+	for _, m := range models.Metrics() {
+		value := m.Value
+		cols = append(cols, column{Name: m.Name, Label: m.Label, value: func(p notifier.PropertyWithScore) float64 { return value(p.Property) }})
+	}
+	return append(cols,
+		column{Name: "area", Label: "面積", value: func(p notifier.PropertyWithScore) float64 { return p.Property.Area }},
+		column{Name: "walk", Label: "徒歩", value: func(p notifier.PropertyWithScore) float64 { return float64(p.Property.WalkMinutes) }},
+		column{Name: "age", Label: "築年数", value: func(p notifier.PropertyWithScore) float64 { return float64(p.Property.Age) }},
+		column{Name: "first_seen", Label: "初回掲載", value: func(p notifier.PropertyWithScore) float64 { return float64(p.Property.FirstSeen.Unix()) }},
+	)
+}
+
+// lookupColumn returns the sortable column with the name.
+func lookupColumn(name string) (column, error) {
+	for _, c := range columns() {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return column{}, fmt.Errorf("unknown sort column: %q", name)
+}
+
+// sortProperties sorts the properties by the column, in descending order if
+// desc is set. Properties with equal values keep their order.
+func sortProperties(properties []notifier.PropertyWithScore, c column, desc bool) {
+	sort.SliceStable(properties, func(i, j int) bool {
+		if desc {
+			return c.value(properties[i]) > c.value(properties[j])
+		}
+		return c.value(properties[i]) < c.value(properties[j])
+	})
+}
+
+// parseFilter parses the filter of the properties table from the query.
+// Rents are in 万円 as in notifications.
+func parseFilter(q url.Values) (notifier.Filter, error) {
+	var f notifier.Filter
+	var errs []error
+	number := func(key string) float64 {
+		s := strings.TrimSpace(q.Get(key))
+		if s == "" {
+			return 0
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 0 {
+			errs = append(errs, fmt.Errorf("invalid %s: %q", key, s))
+		}
+		return v
+	}
+
+	f.MaxTotalRent = number("max_rent") * 10000
+	f.MinArea = number("min_area")
+	f.MaxWalkMinutes = int(number("max_walk"))
+	f.MaxAge = int(number("max_age"))
+	if s := q.Get("station"); s != "" {
+		f.Stations = []string{s}
+	}
+	if s := q.Get("layout"); s != "" {
+		f.Layouts = []string{s}
+	}
+	if s := strings.TrimSpace(q.Get("q")); s != "" {
+		f.IncludeKeywords = []string{s}
+	}
+	f.OnlyBargains = q.Get("bargains") != ""
+	return f, errors.Join(errs...)
+}
+
+// header is a column header of the properties table, linking to the table
+// sorted by the column.
+type header struct {
+	Label  string
+	URL    string
+	Active bool
+	Desc   bool
+}
+
+// propertiesView is the view of the properties table.
+type propertiesView struct {
+	AsOf       time.Time
+	Properties []notifier.PropertyWithScore
+	Total      int // Listed properties before filtering
+	Headers    map[string]header
+	Query      url.Values
+	Stations   []string
+	Layouts    []string
+	ModelError string
+}
+
+func (s *Server) handleProperties(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := parseFilter(q)
+	if err != nil {
+		s.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	sortName := q.Get("sort")
+	if sortName == "" {
+		sortName = "score"
+	}
+	sortBy, err := lookupColumn(sortName)
+	if err != nil {
+		s.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	// The score sorts highest first, the other columns lowest first, by default
+	desc := q.Get("order") == "desc" || (q.Get("order") == "" && sortName == "score")
+
+	d, err := s.load(r.Context())
+	if err != nil {
+		s.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	properties, _ := filter.Apply(d.Listed)
+	sortProperties(properties, sortBy, desc)
+
+	view := propertiesView{
+		AsOf:       d.AsOf,
+		Properties: properties,
+		Total:      len(d.Listed),
+		Headers:    make(map[string]header),
+		Query:      q,
+		Stations:   distinct(d.Listed, func(p models.Property) string { return p.NearestStation }),
+		Layouts:    distinct(d.Listed, func(p models.Property) string { return p.Layout }),
+		ModelError: d.ModelError,
+	}
+	for _, c := range columns() {
+		h := header{Label: c.Label, Active: c.Name == sortName}
+		h.Desc = h.Active && desc
+		sq := cloneValues(q)
+		sq.Set("sort", c.Name)
+		sq.Set("order", "asc")
+		if (h.Active && !desc) || (!h.Active && c.Name == "score") {
+			sq.Set("order", "desc")
+		}
+		h.URL = "/?" + sq.Encode()
+		view.Headers[c.Name] = h
+	}
+	s.render(w, r, "properties.html", view)
+}
+
+// cloneValues returns a copy of the query values.
+func cloneValues(q url.Values) url.Values {
+	c := make(url.Values, len(q))
+	for k, v := range q {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// distinct returns the sorted non-empty values of the properties.
+func distinct(properties []notifier.PropertyWithScore, value func(models.Property) string) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, p := range properties {
+		if v := value(p.Property); v != "" && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// stationSummary summarizes the listed properties near a station.
+type stationSummary struct {
+	Station           string
+	Count             int
+	Bargains          int
+	MedianTotalRent   float64
+	MedianRentPerArea float64 // Total rent per m²
+	MeanScore         float64
+	Index             float64 // Latest market index of the station (yen), zero if none
+	IndexChange       float64 // Relative change of the index from the previous period
+	HasIndexChange    bool
+}
+
+// summarizeStations summarizes the properties by nearest station, most
+// listings first.
+func summarizeStations(properties []notifier.PropertyWithScore, market models.MarketIndex) []stationSummary {
+	groups := make(map[string][]notifier.PropertyWithScore)
+	for _, p := range properties {
+		groups[p.Property.NearestStation] = append(groups[p.Property.NearestStation], p)
+	}
+	indexes := make(map[string]models.IndexSeries)
+	for _, series := range market.Stations {
+		indexes[series.Name] = series
+	}
+
+	summaries := make([]stationSummary, 0, len(groups))
+	for station, group := range groups {
+		sum := stationSummary{Station: station, Count: len(group)}
+		if series := indexes[station]; len(series.Points) > 0 {
+			sum.Index = series.Points[len(series.Points)-1].Value
+			sum.IndexChange, sum.HasIndexChange = series.Change(market.Period)
+		}
+		rents := make([]float64, 0, len(group))
+		perArea := make([]float64, 0, len(group))
+		var scores float64
+		for _, p := range group {
+			rents = append(rents, p.Property.TotalRent())
+			if v := p.Property.TotalRentPerArea(); v > 0 {
+				perArea = append(perArea, v)
+			}
+			if p.Label == notifier.ScoreLabelBargain {
+				sum.Bargains++
+			}
+			scores += p.Score
+		}
+		sum.MedianTotalRent = median(rents)
+		sum.MedianRentPerArea = median(perArea)
+		sum.MeanScore = scores / float64(len(group))
+		summaries = append(summaries, sum)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Count != summaries[j].Count {
+			return summaries[i].Count > summaries[j].Count
+		}
+		return summaries[i].Station < summaries[j].Station
+	})
+	return summaries
+}
+
+// median returns the median of values. The slice is sorted in place.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// stationsView is the view of the station summaries.
+type stationsView struct {
+	AsOf       time.Time
+	Stations   []stationSummary
+	MarketAsOf time.Time
+	Scored     bool
+}
+
+func (s *Server) handleStations(w http.ResponseWriter, r *http.Request) {
+	d, err := s.load(r.Context())
+	if err != nil {
+		s.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	s.render(w, r, "stations.html", stationsView{
+		AsOf:       d.AsOf,
+		Stations:   summarizeStations(d.Listed, d.Market),
+		MarketAsOf: d.Market.GeneratedAt,
+		Scored:     d.Model != nil,
+	})
+}
+
+// unitView is a unit of a building with its price history.
+type unitView struct {
+	models.BuildingUnit
+	Listed  bool
+	History []pricePointView
+}
+
+// pricePointView is a price point and its change from the previous one.
+type pricePointView struct {
+	models.PricePoint
+	Change float64 // Difference from the previous point (yen), zero for the first
+}
+
+// buildingView is the view of a building and the price history of its units.
+type buildingView struct {
+	Building models.Building
+	Units    []unitView
+}
+
+func (s *Server) handleBuilding(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	d, err := s.load(r.Context())
+	if err != nil {
+		s.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	var building *models.Building
+	for i := range d.Buildings.Buildings {
+		if d.Buildings.Buildings[i].Key == key {
+			building = &d.Buildings.Buildings[i]
+			break
+		}
+	}
+	if building == nil {
+		s.fail(w, r, http.StatusNotFound, fmt.Errorf("building not found: %q", key))
+		return
+	}
+
+	view := buildingView{Building: *building}
+	for _, u := range building.Units {
+		uv := unitView{BuildingUnit: u, Listed: !u.LastSeen.Before(d.AsOf)}
+		for i, p := range u.PriceHistory() {
+			pv := pricePointView{PricePoint: p}
+			if i > 0 {
+				pv.Change = p.TotalRent - uv.History[i-1].TotalRent
+			}
+			uv.History = append(uv.History, pv)
+		}
+		view.Units = append(view.Units, uv)
+	}
+	sort.SliceStable(view.Units, func(i, j int) bool { return view.Units[i].Floor < view.Units[j].Floor })
+	s.render(w, r, "building.html", view)
+}
+
+// modelView is the view of the model report.
+type modelView struct {
+	Report     string
+	Snapshot   *analyzer.ModelSnapshot
+	Persisted  bool // The report is of the persisted model
+	ModelError string
+}
+
+func (s *Server) handleModel(w http.ResponseWriter, r *http.Request) {
+	d, err := s.load(r.Context())
+	if err != nil {
+		s.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	view := modelView{Snapshot: d.Snapshot, ModelError: d.ModelError}
+	if d.Model != nil {
+		var report strings.Builder
+		if err := analyzer.WriteModelReport(&report, d.Model); err != nil {
+			s.fail(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		view.Report = report.String()
+		view.Persisted = d.Snapshot != nil && d.Snapshot.Metrics == d.Model.Metrics()
+	}
+	s.render(w, r, "model.html", view)
+}
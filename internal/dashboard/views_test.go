@@ -0,0 +1,144 @@
+package dashboard
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    notifier.Filter
+		wantErr bool
+	}{
+		{"", notifier.Filter{}, false},
+		{"max_rent=8.5&min_area=25&max_walk=10&max_age=20", notifier.Filter{MaxTotalRent: 85000, MinArea: 25, MaxWalkMinutes: 10, MaxAge: 20}, false},
+		{"station=中野&layout=1K&q=+パーク+&bargains=1", notifier.Filter{Stations: []string{"中野"}, Layouts: []string{"1K"}, IncludeKeywords: []string{"パーク"}, OnlyBargains: true}, false},
+		{"max_rent=abc", notifier.Filter{}, true},
+		{"max_walk=-1", notifier.Filter{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, _ := url.ParseQuery(tt.query)
+			got, err := parseFilter(q)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFilter(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.MaxTotalRent != tt.want.MaxTotalRent || got.MinArea != tt.want.MinArea ||
+				got.MaxWalkMinutes != tt.want.MaxWalkMinutes || got.MaxAge != tt.want.MaxAge ||
+				got.OnlyBargains != tt.want.OnlyBargains || !equal(got.Stations, tt.want.Stations) ||
+				!equal(got.Layouts, tt.want.Layouts) || !equal(got.IncludeKeywords, tt.want.IncludeKeywords) {
+				t.Errorf("parseFilter(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// equal reports whether the string slices are equal.
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSortProperties(t *testing.T) {
+	properties := []notifier.PropertyWithScore{
+		{Property: models.Property{ID: "a", Rent: 90000, Area: 30, WalkMinutes: 5}, Score: 1000},
+		{Property: models.Property{ID: "b", Rent: 80000, Area: 20, WalkMinutes: 10}, Score: 5000},
+		{Property: models.Property{ID: "c", Rent: 70000, Area: 25, WalkMinutes: 5}, Score: -2000},
+	}
+
+	tests := []struct {
+		column string
+		desc   bool
+		want   string
+	}{
+		{"score", true, "bac"},
+		{"score", false, "cab"},
+		{"total_rent", false, "cba"},
+		{"total_rent_per_area", false, "cab"},
+		{"walk", false, "acb"}, // Stable for equal values
+		{"area", true, "acb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			c, err := lookupColumn(tt.column)
+			if err != nil {
+				t.Fatalf("lookupColumn(%q) error = %v", tt.column, err)
+			}
+			sorted := append([]notifier.PropertyWithScore(nil), properties...)
+			sortProperties(sorted, c, tt.desc)
+
+			got := ""
+			for _, p := range sorted {
+				got += p.Property.ID
+			}
+			if got != tt.want {
+				t.Errorf("sortProperties(%s, desc=%v) = %s, want %s", tt.column, tt.desc, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := lookupColumn("random"); err == nil {
+		t.Error("lookupColumn(random) should return an error")
+	}
+}
+
+func TestSummarizeStations(t *testing.T) {
+	property := func(station string, rent, area, score float64) notifier.PropertyWithScore {
+		return notifier.PropertyWithScore{
+			Property: models.Property{NearestStation: station, Rent: rent, Area: area},
+			Score:    score,
+			Label:    notifier.CalculateScoreLabel(score),
+		}
+	}
+	properties := []notifier.PropertyWithScore{
+		property("中野", 80000, 20, 10000),
+		property("中野", 100000, 25, 0),
+		property("中野", 90000, 0, -4000),
+		property("高円寺", 70000, 20, 0),
+	}
+	market := models.MarketIndex{Period: models.IndexPeriodWeek, Stations: []models.IndexSeries{
+		{Name: "高円寺", Points: []models.IndexPoint{
+			{Start: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), Value: 100000},
+			{Start: time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), Value: 95000},
+		}},
+	}}
+
+	got := summarizeStations(properties, market)
+	if len(got) != 2 {
+		t.Fatalf("summarizeStations() returned %d stations, want 2", len(got))
+	}
+
+	nakano := got[0]
+	if nakano.Station != "中野" || nakano.Count != 3 || nakano.MedianTotalRent != 90000 || nakano.MeanScore != 2000 {
+		t.Errorf("中野 = %+v, want 3 listings, median 90000 and mean score 2000", nakano)
+	}
+	// Listings without an area are excluded from the rent per m²
+	if nakano.MedianRentPerArea != 4000 {
+		t.Errorf("中野 MedianRentPerArea = %f, want 4000", nakano.MedianRentPerArea)
+	}
+	if nakano.Bargains != 1 || nakano.Index != 0 || nakano.HasIndexChange {
+		t.Errorf("中野 = %+v, want 1 bargain and no index", nakano)
+	}
+
+	koenji := got[1]
+	if koenji.Index != 95000 || !koenji.HasIndexChange || koenji.IndexChange > -0.049 || koenji.IndexChange < -0.051 {
+		t.Errorf("高円寺 = %+v, want index 95000 changed by -5%%", koenji)
+	}
+}
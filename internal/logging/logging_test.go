@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		level   string
+		format  string
+		wantErr bool
+	}{
+		{"info", "json", false},
+		{"DEBUG", "text", false},
+		{"warn", "JSON", false},
+		{"verbose", "json", true},
+		{"info", "xml", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level+"/"+tt.format, func(t *testing.T) {
+			_, err := New(&bytes.Buffer{}, tt.level, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New(%q, %q) error = %v, wantErr %v", tt.level, tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info", "json")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := WithAttrs(context.Background(), "run_id", "run-1", "profile", "default")
+	ctx = WithAttrs(ctx, "page", 2)
+	logger.InfoContext(ctx, "Fetched page", "properties", 30)
+	logger.DebugContext(ctx, "Below the level")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Got %d records, want 1: %q", len(lines), buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Invalid JSON record %q: %v", lines[0], err)
+	}
+	want := map[string]any{"msg": "Fetched page", "run_id": "run-1", "profile": "default", "page": 2.0, "properties": 30.0}
+	for k, v := range want {
+		if record[k] != v {
+			t.Errorf("record[%q] = %v, want %v", k, record[k], v)
+		}
+	}
+
+	// Attributes of a derived context don't leak into the parent
+	if got := len(Attrs(WithAttrs(context.Background(), "a", 1))); got != 1 {
+		t.Errorf("len(Attrs()) = %d, want 1", got)
+	}
+}
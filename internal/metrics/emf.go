@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EMF is a Recorder that buffers metrics and writes them as CloudWatch
+// Embedded Metric Format records, one JSON line per label set. Written to
+// the Lambda log, the records are extracted into CloudWatch metrics.
+type EMF struct {
+	namespace  string
+	dimensions []Label
+
+	mu     sync.Mutex
+	groups map[string]*emfGroup
+}
+
+// emfGroup holds the metrics of one label set.
+type emfGroup struct {
+	labels   []Label
+	counters map[string]float64
+	gauges   map[string]float64
+	timings  map[string][]float64 // Seconds
+}
+
+// NewEMF creates an EMF recorder for the CloudWatch namespace. The
+// dimensions (e.g., the profile) are added to every metric.
+func NewEMF(namespace string, dimensions ...Label) *EMF {
+	return &EMF{
+		namespace:  namespace,
+		dimensions: dimensions,
+		groups:     make(map[string]*emfGroup),
+	}
+}
+
+// group returns the group of the labels, creating it if needed.
+// The caller must hold e.mu.
+func (e *EMF) group(labels []Label) *emfGroup {
+	key := labelKey(labels)
+	g := e.groups[key]
+	if g == nil {
+		g = &emfGroup{
+			labels:   sortLabels(labels),
+			counters: make(map[string]float64),
+			gauges:   make(map[string]float64),
+			timings:  make(map[string][]float64),
+		}
+		e.groups[key] = g
+	}
+	return g
+}
+
+// Count adds value to a counter.
+func (e *EMF) Count(name string, value float64, labels ...Label) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.group(labels).counters[name] += value
+}
+
+// Gauge sets a gauge to value.
+func (e *EMF) Gauge(name string, value float64, labels ...Label) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.group(labels).gauges[name] = value
+}
+
+// Timing records the duration of an operation.
+func (e *EMF) Timing(name string, d time.Duration, labels ...Label) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	g := e.group(labels)
+	g.timings[name] = append(g.timings[name], d.Seconds())
+}
+
+// emfMetric is a metric definition in an EMF record.
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+// Flush writes the buffered metrics as EMF records and clears the buffer.
+func (e *EMF) Flush(w io.Writer) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	keys := make([]string, 0, len(e.groups))
+	for k := range e.groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	timestamp := time.Now().UnixMilli()
+	enc := json.NewEncoder(w)
+	for _, k := range keys {
+		g := e.groups[k]
+		record := make(map[string]any)
+
+		dimensions := []string{}
+		for _, l := range append(append([]Label(nil), e.dimensions...), g.labels...) {
+			dimensions = append(dimensions, l.Name)
+			record[l.Name] = l.Value
+		}
+
+		var defs []emfMetric
+		for name, v := range g.counters {
+			defs = append(defs, emfMetric{Name: name, Unit: "Count"})
+			record[name] = v
+		}
+		for name, v := range g.gauges {
+			defs = append(defs, emfMetric{Name: name, Unit: "None"})
+			record[name] = v
+		}
+		for name, v := range g.timings {
+			defs = append(defs, emfMetric{Name: name, Unit: "Seconds"})
+			record[name] = v
+		}
+		sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+
+		record["_aws"] = map[string]any{
+			"Timestamp": timestamp,
+			"CloudWatchMetrics": []map[string]any{{
+				"Namespace":  e.namespace,
+				"Dimensions": [][]string{dimensions},
+				"Metrics":    defs,
+			}},
+		}
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("failed to write EMF record: %w", err)
+		}
+	}
+
+	e.groups = make(map[string]*emfGroup)
+	return nil
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEMFFlush(t *testing.T) {
+	e := NewEMF("SUUMOHunter", L("Profile", "default"))
+	e.Count("scrape_pages_total", 2)
+	e.Count("scrape_pages_total", 1)
+	e.Timing("scrape_duration_seconds", 2*time.Second)
+	e.Count("notifier_messages_total", 1, Result(nil))
+	e.Gauge("model_validation_rmse", 4200, L("model", "ols"))
+
+	var buf bytes.Buffer
+	if err := e.Flush(&buf); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Got %d records, want one per label set (3): %s", len(lines), buf.String())
+	}
+
+	records := make(map[string]map[string]any)
+	for _, line := range lines {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid JSON record %q: %v", line, err)
+		}
+		if record["Profile"] != "default" {
+			t.Errorf("Record %q lacks the Profile dimension", line)
+		}
+		aws := record["_aws"].(map[string]any)
+		if _, ok := aws["Timestamp"].(float64); !ok {
+			t.Errorf("Record %q lacks the timestamp", line)
+		}
+		directive := aws["CloudWatchMetrics"].([]any)[0].(map[string]any)
+		if directive["Namespace"] != "SUUMOHunter" {
+			t.Errorf("Namespace = %v, want SUUMOHunter", directive["Namespace"])
+		}
+		dims, _ := json.Marshal(directive["Dimensions"])
+		records[string(dims)] = record
+	}
+
+	unlabeled := records[`[["Profile"]]`]
+	if unlabeled == nil || unlabeled["scrape_pages_total"] != 3.0 {
+		t.Errorf("Unlabeled record = %v, want scrape_pages_total 3", unlabeled)
+	}
+	if d, _ := unlabeled["scrape_duration_seconds"].([]any); len(d) != 1 || d[0] != 2.0 {
+		t.Errorf("scrape_duration_seconds = %v, want [2]", unlabeled["scrape_duration_seconds"])
+	}
+	if r := records[`[["Profile","result"]]`]; r == nil || r["result"] != "success" || r["notifier_messages_total"] != 1.0 {
+		t.Errorf("Result record = %v, want notifier_messages_total 1 with result=success", r)
+	}
+	if r := records[`[["Profile","model"]]`]; r == nil || r["model_validation_rmse"] != 4200.0 {
+		t.Errorf("Model record = %v, want model_validation_rmse 4200", r)
+	}
+
+	// The buffer is cleared
+	buf.Reset()
+	if err := e.Flush(&buf); err != nil || buf.Len() != 0 {
+		t.Errorf("Second Flush() = (%q, %v), want nothing written", buf.String(), err)
+	}
+}
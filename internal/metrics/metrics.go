@@ -0,0 +1,80 @@
+// Package metrics records operational metrics of the pipeline, such as scrape
+// durations, retries and notification failures. Metrics are written as
+// CloudWatch Embedded Metric Format (EMF) records in Lambda, or exposed in the
+// Prometheus text format by long-running processes.
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Recorder records metrics. Implementations must be safe for concurrent use.
+type Recorder interface {
+	// Count adds value to a counter.
+	Count(name string, value float64, labels ...Label)
+
+	// Gauge sets a gauge to value.
+	Gauge(name string, value float64, labels ...Label)
+
+	// Timing records the duration of an operation.
+	Timing(name string, d time.Duration, labels ...Label)
+}
+
+// Label is a metric dimension.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// L returns a label.
+func L(name, value string) Label {
+	return Label{Name: name, Value: value}
+}
+
+// Nop is a Recorder that discards metrics.
+type Nop struct{}
+
+func (Nop) Count(string, float64, ...Label)        {}
+func (Nop) Gauge(string, float64, ...Label)        {}
+func (Nop) Timing(string, time.Duration, ...Label) {}
+
+// Result returns the "result" label of an operation: "error" if err is set,
+// "success" otherwise.
+func Result(err error) Label {
+	if err != nil {
+		return L("result", "error")
+	}
+	return L("result", "success")
+}
+
+// series identifies a metric with its labels.
+type series struct {
+	name   string
+	labels string // Canonical form of the labels, see labelKey
+}
+
+// labelKey returns the labels sorted by name in a canonical string form,
+// e.g. `operation="get",result="success"`.
+func labelKey(labels []Label) string {
+	sorted := sortLabels(labels)
+	parts := make([]string, len(sorted))
+	for i, l := range sorted {
+		parts[i] = l.Name + "=" + quote(l.Value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortLabels returns a copy of the labels sorted by name.
+func sortLabels(labels []Label) []Label {
+	sorted := append([]Label(nil), labels...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// quote quotes a label value as in the Prometheus text format.
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
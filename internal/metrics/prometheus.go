@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Registry is a Recorder that accumulates metrics in memory and exposes them
+// in the Prometheus text format, for long-running processes. Timings are
+// exposed as summaries (sum and count).
+type Registry struct {
+	namespace string
+
+	mu       sync.Mutex
+	counters map[series]float64
+	gauges   map[series]float64
+	timings  map[series]*summary
+}
+
+// summary is the sum and count of timing observations, in seconds.
+type summary struct {
+	sum   float64
+	count int
+}
+
+// NewRegistry creates a Registry. Metric names are prefixed with the
+// namespace and "_" if it is not empty.
+func NewRegistry(namespace string) *Registry {
+	return &Registry{
+		namespace: namespace,
+		counters:  make(map[series]float64),
+		gauges:    make(map[series]float64),
+		timings:   make(map[series]*summary),
+	}
+}
+
+// Count adds value to a counter.
+func (r *Registry) Count(name string, value float64, labels ...Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[series{name, labelKey(labels)}] += value
+}
+
+// Gauge sets a gauge to value.
+func (r *Registry) Gauge(name string, value float64, labels ...Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[series{name, labelKey(labels)}] = value
+}
+
+// Timing records the duration of an operation.
+func (r *Registry) Timing(name string, d time.Duration, labels ...Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := series{name, labelKey(labels)}
+	if r.timings[s] == nil {
+		r.timings[s] = &summary{}
+	}
+	r.timings[s].sum += d.Seconds()
+	r.timings[s].count++
+}
+
+// WriteText writes the metrics in the Prometheus text exposition format,
+// sorted by name and labels.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	writeFamily(bw, r.fullName, "counter", r.counters, func(name, labels string, v float64) {
+		writeSample(bw, name, labels, v)
+	})
+	writeFamily(bw, r.fullName, "gauge", r.gauges, func(name, labels string, v float64) {
+		writeSample(bw, name, labels, v)
+	})
+	writeFamily(bw, r.fullName, "summary", r.timings, func(name, labels string, s *summary) {
+		writeSample(bw, name+"_sum", labels, s.sum)
+		writeSample(bw, name+"_count", labels, float64(s.count))
+	})
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := r.WriteText(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// fullName returns the name prefixed with the namespace.
+func (r *Registry) fullName(name string) string {
+	if r.namespace == "" {
+		return name
+	}
+	return r.namespace + "_" + name
+}
+
+// writeFamily writes the TYPE line and the samples of each metric in values.
+func writeFamily[V any](w io.Writer, fullName func(string) string, typ string, values map[series]V, write func(name, labels string, v V)) {
+	keys := make([]series, 0, len(values))
+	for s := range values {
+		keys = append(keys, s)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].labels < keys[j].labels
+	})
+
+	for i, s := range keys {
+		name := fullName(s.name)
+		if i == 0 || keys[i-1].name != s.name {
+			fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+		}
+		write(name, s.labels, values[s])
+	}
+}
+
+// writeSample writes a sample line.
+func writeSample(w io.Writer, name, labels string, v float64) {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s %s\n", name, strconv.FormatFloat(v, 'g', -1, 64))
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	r := NewRegistry("suumo_hunter")
+	r.Count("scrape_pages_total", 2)
+	r.Count("scrape_pages_total", 3)
+	r.Count("storage_operations_total", 1, L("operation", "get"), Result(nil))
+	r.Count("storage_operations_total", 1, Result(nil), L("operation", "get")) // Same series
+	r.Count("storage_operations_total", 1, L("operation", "put"), L("result", `quoted "value"`))
+	r.Gauge("model_samples", 100)
+	r.Gauge("model_samples", 120)
+	r.Timing("scrape_duration_seconds", 1500*time.Millisecond)
+	r.Timing("scrape_duration_seconds", 500*time.Millisecond)
+
+	var buf strings.Builder
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	want := `# TYPE suumo_hunter_scrape_pages_total counter
+suumo_hunter_scrape_pages_total 5
+# TYPE suumo_hunter_storage_operations_total counter
+suumo_hunter_storage_operations_total{operation="get",result="success"} 2
+suumo_hunter_storage_operations_total{operation="put",result="quoted \"value\""} 1
+# TYPE suumo_hunter_model_samples gauge
+suumo_hunter_model_samples 120
+# TYPE suumo_hunter_scrape_duration_seconds summary
+suumo_hunter_scrape_duration_seconds_sum 2
+suumo_hunter_scrape_duration_seconds_count 2
+`
+	if buf.String() != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	r := NewRegistry("")
+	r.Count("runs_total", 1, L("job", "scrape"))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", ct)
+	}
+	if !strings.Contains(rec.Body.String(), `runs_total{job="scrape"} 1`) {
+		t.Errorf("Body = %q, want the unprefixed counter", rec.Body.String())
+	}
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// BuildingRetention is how long buildings and units are kept after they were last listed.
+const BuildingRetention = 365 * 24 * time.Hour
+
+// BuildingHistory is the persisted list of buildings and their units.
+type BuildingHistory struct {
+	UpdatedAt time.Time  `json:"updated_at"`
+	Buildings []Building `json:"buildings"`
+}
+
+// Building is a building (one SUUMO cassette) with the units listed in it.
+type Building struct {
+	Key         string         `json:"key"`
+	Name        string         `json:"name"`
+	Address     string         `json:"address"`
+	Age         int            `json:"age"`
+	TotalFloors int            `json:"total_floors"`
+	Access      string         `json:"access"`
+	FirstSeen   time.Time      `json:"first_seen"`
+	LastSeen    time.Time      `json:"last_seen"`
+	Units       []BuildingUnit `json:"units"`
+}
+
+// BuildingUnit is a unit of a building that has been listed.
+type BuildingUnit struct {
+	Key       string    `json:"key"` // Floor, layout and area; stable across listing IDs
+	ID        string    `json:"id"`  // Latest listing ID
+	URL       string    `json:"url"`
+	Floor     int       `json:"floor"`
+	Layout    string    `json:"layout"`
+	Area      float64   `json:"area"`
+	TotalRent float64   `json:"total_rent"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+
+	// Prices are the total rents of the unit, oldest first. A point is added
+	// when the unit is first listed and whenever its total rent changes.
+	Prices []PricePoint `json:"prices,omitempty"`
+}
+
+// PricePoint is the total rent of a unit from a time on.
+type PricePoint struct {
+	Time      time.Time `json:"time"`
+	ID        string    `json:"id"`
+	TotalRent float64   `json:"total_rent"`
+}
+
+// BuildingKey returns the key of the building a property belongs to
+// (normalized name and address).
+func BuildingKey(p Property) string {
+	return NormalizeText(p.Name) + "|" + NormalizeText(p.Address)
+}
+
+// unitKey returns the key of a unit within its building.
+func unitKey(p Property) string {
+	return fmt.Sprintf("%d|%s|%.2f", p.Floor, NormalizeText(p.Layout), p.Area)
+}
+
+// RentPerArea returns the total rent per m² (0 if the area is unknown).
+func (u BuildingUnit) RentPerArea() float64 {
+	if u.Area <= 0 {
+		return 0
+	}
+	return u.TotalRent / u.Area
+}
+
+// PriceHistory returns the total rents of the unit, oldest first. Units
+// persisted before prices were recorded have a single point at FirstSeen.
+func (u BuildingUnit) PriceHistory() []PricePoint {
+	if len(u.Prices) == 0 {
+		return []PricePoint{{Time: u.FirstSeen, ID: u.ID, TotalRent: u.TotalRent}}
+	}
+	return u.Prices
+}
+
+// Unit returns the unit of the building the property is listed for.
+func (b *Building) Unit(p Property) (BuildingUnit, bool) {
+	key := unitKey(p)
+	for _, u := range b.Units {
+		if u.Key == key {
+			return u, true
+		}
+	}
+	return BuildingUnit{}, false
+}
+
+// Vacancies returns the units listed in the run at asOf, lowest floor first.
+func (b *Building) Vacancies(asOf time.Time) []BuildingUnit {
+	var units []BuildingUnit
+	for _, u := range b.Units {
+		if !u.LastSeen.Before(asOf) {
+			units = append(units, u)
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool { return units[i].Floor < units[j].Floor })
+	return units
+}
+
+// UpdateBuildings records the current properties as vacancies of their
+// buildings at now and returns the updated history. A unit listed again
+// under a new ID keeps its FirstSeen. Buildings and units not listed within
+// BuildingRetention are dropped.
+func UpdateBuildings(history BuildingHistory, current []Property, now time.Time) BuildingHistory {
+	index := make(map[string]int)
+	var buildings []Building
+	for _, b := range history.Buildings {
+		if now.Sub(b.LastSeen) > BuildingRetention {
+			continue
+		}
+		units := b.Units[:0:0]
+		for _, u := range b.Units {
+			if now.Sub(u.LastSeen) <= BuildingRetention {
+				units = append(units, u)
+			}
+		}
+		b.Units = units
+		index[b.Key] = len(buildings)
+		buildings = append(buildings, b)
+	}
+
+	for _, p := range current {
+		key := BuildingKey(p)
+		i, ok := index[key]
+		if !ok {
+			buildings = append(buildings, Building{Key: key, FirstSeen: now})
+			i = len(buildings) - 1
+			index[key] = i
+		}
+
+		b := &buildings[i]
+		b.Name = p.Name
+		b.Address = p.Address
+		b.Age = p.Age
+		b.TotalFloors = p.TotalFloors
+		b.Access = p.Access
+		b.LastSeen = now
+		upsertUnit(b, p, now)
+	}
+
+	return BuildingHistory{UpdatedAt: now, Buildings: buildings}
+}
+
+// upsertUnit records that the property was listed at now.
+func upsertUnit(b *Building, p Property, now time.Time) {
+	key := unitKey(p)
+	for i := range b.Units {
+		if u := &b.Units[i]; u.Key == key {
+			if u.Prices = u.PriceHistory(); u.Prices[len(u.Prices)-1].TotalRent != p.TotalRent() {
+				u.Prices = append(u.Prices, PricePoint{Time: now, ID: p.ID, TotalRent: p.TotalRent()})
+			}
+			u.ID = p.ID
+			u.URL = p.URL
+			u.TotalRent = p.TotalRent()
+			u.LastSeen = now
+			return
+		}
+	}
+	b.Units = append(b.Units, BuildingUnit{
+		Key:       key,
+		ID:        p.ID,
+		URL:       p.URL,
+		Floor:     p.Floor,
+		Layout:    p.Layout,
+		Area:      p.Area,
+		TotalRent: p.TotalRent(),
+		FirstSeen: now,
+		LastSeen:  now,
+		Prices:    []PricePoint{{Time: now, ID: p.ID, TotalRent: p.TotalRent()}},
+	})
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func buildingUnit(id string, floor int, area, rent float64) Property {
+	return Property{
+		ID:          id,
+		Name:        "テストマンション",
+		Address:     "東京都中野区中野1-1-1",
+		Age:         5,
+		TotalFloors: 5,
+		Access:      "JR中央線/中野駅 歩5分",
+		Floor:       floor,
+		Layout:      "1K",
+		Area:        area,
+		Rent:        rent,
+	}
+}
+
+func TestUpdateBuildings(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	other := buildingUnit("jnc_9", 1, 30, 100000)
+	other.Name = "別のマンション"
+
+	history := UpdateBuildings(BuildingHistory{}, []Property{
+		buildingUnit("jnc_1", 2, 25, 80000),
+		buildingUnit("jnc_2", 3, 25, 85000),
+		other,
+	}, now)
+
+	if len(history.Buildings) != 2 {
+		t.Fatalf("Expected 2 buildings, got %d", len(history.Buildings))
+	}
+	b := history.Buildings[0]
+	if b.TotalFloors != 5 || b.Access != "JR中央線/中野駅 歩5分" || len(b.Units) != 2 {
+		t.Errorf("Building = %+v, want 5 floors, access and 2 units", b)
+	}
+
+	// Next run: the 2nd floor unit is relisted under a new ID, the 3rd floor is rented
+	later := now.Add(24 * time.Hour)
+	history = UpdateBuildings(history, []Property{buildingUnit("jnc_3", 2, 25, 79000)}, later)
+
+	b = history.Buildings[0]
+	vacancies := b.Vacancies(later)
+	if len(vacancies) != 1 {
+		t.Fatalf("Expected 1 vacancy, got %d", len(vacancies))
+	}
+	if v := vacancies[0]; v.ID != "jnc_3" || v.TotalRent != 79000 || !v.FirstSeen.Equal(now) {
+		t.Errorf("Vacancy = %+v, want jnc_3 at 79000 first seen %v", v, now)
+	}
+	if len(b.Units) != 2 {
+		t.Errorf("Rented units should remain in the history, got %d units", len(b.Units))
+	}
+
+	// Buildings not listed within the retention period are dropped
+	history = UpdateBuildings(history, nil, later.Add(2*BuildingRetention))
+	if len(history.Buildings) != 0 {
+		t.Errorf("Expected expired buildings to be dropped, got %d", len(history.Buildings))
+	}
+}
+
+func TestBuildingUnitPriceHistory(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// A unit persisted before prices were recorded
+	legacy := UpdateBuildings(BuildingHistory{}, []Property{buildingUnit("jnc_1", 2, 25, 80000)}, now)
+	legacy.Buildings[0].Units[0].Prices = nil
+
+	history := legacy
+	for i, rent := range []float64{80000, 78000, 78000, 79000} {
+		history = UpdateBuildings(history, []Property{buildingUnit(fmt.Sprintf("jnc_%d", i+2), 2, 25, rent)}, now.Add(time.Duration(i+1)*24*time.Hour))
+	}
+
+	got := history.Buildings[0].Units[0].PriceHistory()
+	want := []PricePoint{
+		{Time: now, ID: "jnc_1", TotalRent: 80000},
+		{Time: now.Add(48 * time.Hour), ID: "jnc_3", TotalRent: 78000},
+		{Time: now.Add(96 * time.Hour), ID: "jnc_5", TotalRent: 79000},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("PriceHistory() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || got[i].ID != want[i].ID || got[i].TotalRent != want[i].TotalRent {
+			t.Errorf("PriceHistory()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBuildingUnitRentPerArea(t *testing.T) {
+	if got := (BuildingUnit{TotalRent: 90000, Area: 30}).RentPerArea(); got != 3000 {
+		t.Errorf("RentPerArea() = %f, want 3000", got)
+	}
+	if got := (BuildingUnit{TotalRent: 90000}).RentPerArea(); got != 0 {
+		t.Errorf("RentPerArea() without area = %f, want 0", got)
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// PriceChange is a change of the total rent of a listing between runs.
+type PriceChange struct {
+	Property     Property `json:"property"`      // Listing with the current rent
+	PreviousRent float64  `json:"previous_rent"` // Previous total rent (yen)
+}
+
+// Difference returns the change of the total rent (negative for a price drop).
+func (c PriceChange) Difference() float64 {
+	return c.Property.TotalRent() - c.PreviousRent
+}
+
+// FindPriceChanges returns the current properties whose total rent differs
+// from the matching previous property (by UniqueKey).
+func FindPriceChanges(current, previous []Property) []PriceChange {
+	previousRent := make(map[string]float64)
+	for _, p := range previous {
+		previousRent[p.UniqueKey()] = p.TotalRent()
+	}
+
+	var changes []PriceChange
+	for _, p := range current {
+		rent, ok := previousRent[p.UniqueKey()]
+		if ok && rent > 0 && rent != p.TotalRent() {
+			changes = append(changes, PriceChange{Property: p, PreviousRent: rent})
+		}
+	}
+
+	return changes
+}
+
+// FindDelisted returns the previous properties that were listed in the
+// previous run (the latest LastSeen) but are no longer in current.
+// Returns nil if the previous run time is unknown. current must cover all
+// the search results: a listing that only moved past the scraped pages
+// would be reported as delisted.
+func FindDelisted(current, previous []Property) []Property {
+	var lastRun time.Time
+	for _, p := range previous {
+		if p.LastSeen.After(lastRun) {
+			lastRun = p.LastSeen
+		}
+	}
+	if lastRun.IsZero() {
+		return nil
+	}
+
+	currentKeys := make(map[string]bool)
+	for _, p := range current {
+		currentKeys[p.UniqueKey()] = true
+	}
+
+	var delisted []Property
+	for _, p := range previous {
+		if p.IsListed(lastRun) && !currentKeys[p.UniqueKey()] {
+			delisted = append(delisted, p)
+		}
+	}
+
+	return delisted
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindPriceChanges(t *testing.T) {
+	previous := []Property{
+		{ID: "1", Address: "中野1", Area: 25, Layout: "1K", Rent: 80000, ManagementFee: 5000},
+		{ID: "2", Address: "中野2", Area: 30, Layout: "1DK", Rent: 90000},
+		{ID: "3", Address: "中野3", Area: 20, Layout: "1R", Rent: 70000},
+	}
+	current := []Property{
+		{ID: "1", Address: "中野1", Area: 25, Layout: "1K", Rent: 78000, ManagementFee: 5000},
+		{ID: "2", Address: "中野2", Area: 30, Layout: "1DK", Rent: 90000},
+		{ID: "4", Address: "中野4", Area: 20, Layout: "1R", Rent: 60000},
+	}
+
+	changes := FindPriceChanges(current, previous)
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 price change, got %d", len(changes))
+	}
+	if c := changes[0]; c.Property.ID != "1" || c.PreviousRent != 85000 || c.Difference() != -2000 {
+		t.Errorf("Price change = %+v (difference %v), want jnc_1 from 85000 by -2000", c, c.Difference())
+	}
+}
+
+func TestFindDelisted(t *testing.T) {
+	lastRun := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	previous := []Property{
+		{ID: "1", Address: "中野1", Area: 25, Layout: "1K", LastSeen: lastRun},
+		{ID: "2", Address: "中野2", Area: 30, Layout: "1DK", LastSeen: lastRun},
+		{ID: "3", Address: "中野3", Area: 20, Layout: "1R", LastSeen: lastRun.Add(-24 * time.Hour)}, // Delisted earlier
+	}
+	current := []Property{{ID: "1", Address: "中野1", Area: 25, Layout: "1K"}}
+
+	delisted := FindDelisted(current, previous)
+	if len(delisted) != 1 || delisted[0].ID != "2" {
+		t.Errorf("FindDelisted() = %+v, want only property 2", delisted)
+	}
+
+	// Previous data without seen dates
+	if got := FindDelisted(nil, []Property{{ID: "1"}}); got != nil {
+		t.Errorf("FindDelisted() without seen dates = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ClusterHistory is the persisted history of listing clusters. A cluster
+// groups near-duplicate listings of the same physical unit across IDs
+// (e.g., the same unit listed by several agencies).
+type ClusterHistory struct {
+	UpdatedAt time.Time        `json:"updated_at"`
+	Clusters  []ListingCluster `json:"clusters"`
+}
+
+// ListingCluster is a physical unit and the listings that advertise it.
+type ListingCluster struct {
+	Key      string           `json:"key"`
+	Name     string           `json:"name"`    // Normalized building name
+	Address  string           `json:"address"` // Normalized address
+	Floor    int              `json:"floor"`
+	Layout   string           `json:"layout"`
+	Area     float64          `json:"area"`
+	Listings []ClusterListing `json:"listings"`
+	Episodes []SeenPeriod     `json:"episodes"` // Periods in which the unit was listed, oldest first
+}
+
+// ClusterListing is one listing (ID) of a cluster.
+type ClusterListing struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	TotalRent float64   `json:"total_rent"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// SeenPeriod is a period in which a unit was continuously listed.
+type SeenPeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Reappearances returns how many times the unit was listed again after being delisted.
+func (c *ListingCluster) Reappearances() int {
+	return max(0, len(c.Episodes)-1)
+}
+
+// LastSeen returns when the unit was last listed.
+func (c *ListingCluster) LastSeen() time.Time {
+	if len(c.Episodes) == 0 {
+		return time.Time{}
+	}
+	return c.Episodes[len(c.Episodes)-1].End
+}
+
+// NormalizeText normalizes names and addresses for comparison: full-width
+// ASCII is converted to half-width, letters are lower-cased and whitespace is removed.
+func NormalizeText(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r >= '！' && r <= '～' {
+			r -= '！' - '!'
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := map[string]string{
+		"東京都中野区中野１丁目":  "東京都中野区中野1丁目",
+		"パークハウス　中野 ＡＢ": "パークハウス中野ab",
+		"":             "",
+	}
+	for input, want := range tests {
+		if got := NormalizeText(input); got != want {
+			t.Errorf("NormalizeText(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestListingClusterEpisodes(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var c ListingCluster
+	if c.Reappearances() != 0 || !c.LastSeen().IsZero() {
+		t.Errorf("Empty cluster = (%d, %v), want (0, zero)", c.Reappearances(), c.LastSeen())
+	}
+
+	c.Episodes = []SeenPeriod{
+		{Start: start, End: start.AddDate(0, 0, 7)},
+		{Start: start.AddDate(0, 1, 0), End: start.AddDate(0, 1, 3)},
+	}
+	if c.Reappearances() != 1 {
+		t.Errorf("Reappearances() = %d, want 1", c.Reappearances())
+	}
+	if !c.LastSeen().Equal(start.AddDate(0, 1, 3)) {
+		t.Errorf("LastSeen() = %v, want %v", c.LastSeen(), start.AddDate(0, 1, 3))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 )
 
 // CSV header columns in order.
@@ -25,6 +26,34 @@ var csvHeaders = []string{
 	"url",
 }
 
+// Optional CSV columns. Files written before these were added load with zero values.
+var optionalCSVHeaders = []string{
+	"first_seen",
+	"last_seen",
+	"auto_lock",
+	"separate_bath",
+	"indoor_washer",
+	"corner_unit",
+	"structure",
+	"has_details",
+	"total_floors",
+	"access",
+}
+
+// Derived metric columns (see Metrics), written by SaveToCSV to both the
+// stored data and exports so that spreadsheets can use them. They are
+// ignored when loading and recomputed from the other columns.
+var derivedCSVHeaders = func() []string {
+	names := make([]string, len(metricRegistry))
+	for i, m := range metricRegistry {
+		names[i] = m.Name
+	}
+	return names
+}()
+
+// csvTimeLayout is the layout used for timestamps in CSV files.
+const csvTimeLayout = time.RFC3339
+
 // LoadFromCSV reads properties from a CSV file.
 // The CSV must have a header row matching the expected columns.
 func LoadFromCSV(r io.Reader) ([]Property, error) {
@@ -87,6 +116,14 @@ func recordToProperty(record []string, colIndex map[string]int) Property {
 	managementFee, _ := strconv.ParseFloat(getField("management_fee"), 64)
 	area, _ := strconv.ParseFloat(getField("area"), 64)
 	walkMinutes, _ := strconv.Atoi(getField("walk_minutes"))
+	firstSeen, _ := time.Parse(csvTimeLayout, getField("first_seen"))
+	lastSeen, _ := time.Parse(csvTimeLayout, getField("last_seen"))
+	autoLock, _ := strconv.ParseBool(getField("auto_lock"))
+	separateBath, _ := strconv.ParseBool(getField("separate_bath"))
+	indoorWasher, _ := strconv.ParseBool(getField("indoor_washer"))
+	cornerUnit, _ := strconv.ParseBool(getField("corner_unit"))
+	hasDetails, _ := strconv.ParseBool(getField("has_details"))
+	totalFloors, _ := strconv.Atoi(getField("total_floors"))
 
 	return Property{
 		ID:             getField("id"),
@@ -103,6 +140,16 @@ func recordToProperty(record []string, colIndex map[string]int) Property {
 		WalkMinutes:    walkMinutes,
 		NearestStation: getField("nearest_station"),
 		URL:            getField("url"),
+		FirstSeen:      firstSeen,
+		LastSeen:       lastSeen,
+		AutoLock:       autoLock,
+		SeparateBath:   separateBath,
+		IndoorWasher:   indoorWasher,
+		CornerUnit:     cornerUnit,
+		Structure:      getField("structure"),
+		HasDetails:     hasDetails,
+		TotalFloors:    totalFloors,
+		Access:         getField("access"),
 	}
 }
 
@@ -113,7 +160,8 @@ func SaveToCSV(w io.Writer, properties []Property) error {
 	defer writer.Flush()
 
 	// Write header
-	if err := writer.Write(csvHeaders); err != nil {
+	header := append(append(append([]string{}, csvHeaders...), optionalCSVHeaders...), derivedCSVHeaders...)
+	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
@@ -130,7 +178,7 @@ func SaveToCSV(w io.Writer, properties []Property) error {
 
 // propertyToRecord converts a Property struct to a CSV record.
 func propertyToRecord(p Property) []string {
-	return []string{
+	record := []string{
 		p.ID,
 		p.Name,
 		p.Address,
@@ -145,7 +193,29 @@ func propertyToRecord(p Property) []string {
 		strconv.Itoa(p.WalkMinutes),
 		p.NearestStation,
 		p.URL,
+		formatCSVTime(p.FirstSeen),
+		formatCSVTime(p.LastSeen),
+		strconv.FormatBool(p.AutoLock),
+		strconv.FormatBool(p.SeparateBath),
+		strconv.FormatBool(p.IndoorWasher),
+		strconv.FormatBool(p.CornerUnit),
+		p.Structure,
+		strconv.FormatBool(p.HasDetails),
+		strconv.Itoa(p.TotalFloors),
+		p.Access,
+	}
+	for _, m := range metricRegistry {
+		record = append(record, strconv.FormatFloat(m.Value(p), 'f', 0, 64))
 	}
+	return record
+}
+
+// formatCSVTime formats a timestamp for CSV output. Zero times are written as empty strings.
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(csvTimeLayout)
 }
 
 // FindNewProperties returns properties that exist in current but not in previous.
@@ -167,6 +237,54 @@ func FindNewProperties(current, previous []Property) []Property {
 	return newProps
 }
 
+// MarkSeen records that the current properties were seen at now.
+// FirstSeen is carried over from the matching previous property (by UniqueKey)
+// when known, otherwise it is set to now. LastSeen is always set to now.
+// The current slice is modified in place.
+func MarkSeen(current, previous []Property, now time.Time) {
+	firstSeen := make(map[string]time.Time)
+	for _, p := range previous {
+		if !p.FirstSeen.IsZero() {
+			firstSeen[p.UniqueKey()] = p.FirstSeen
+		}
+	}
+
+	for i := range current {
+		if t, ok := firstSeen[current[i].UniqueKey()]; ok {
+			current[i].FirstSeen = t
+		} else {
+			current[i].FirstSeen = now
+		}
+		current[i].LastSeen = now
+	}
+}
+
+// CarryOverDetails copies the facilities and structure of the matching
+// previous property (by UniqueKey) to current properties without details,
+// so that detail pages are only fetched once per listing.
+// The current slice is modified in place.
+func CarryOverDetails(current, previous []Property) {
+	details := make(map[string]Property)
+	for _, p := range previous {
+		if p.HasDetails {
+			details[p.UniqueKey()] = p
+		}
+	}
+
+	for i := range current {
+		d, ok := details[current[i].UniqueKey()]
+		if !ok || current[i].HasDetails {
+			continue
+		}
+		current[i].AutoLock = d.AutoLock
+		current[i].SeparateBath = d.SeparateBath
+		current[i].IndoorWasher = d.IndoorWasher
+		current[i].CornerUnit = d.CornerUnit
+		current[i].Structure = d.Structure
+		current[i].HasDetails = true
+	}
+}
+
 // MergeProperties merges two property lists, removing duplicates by UniqueKey.
 // Properties from 'current' take precedence over 'previous'.
 // Uses UniqueKey (address+area+layout+floor) to handle cases where
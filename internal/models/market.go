@@ -0,0 +1,115 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// IndexPeriod is the aggregation period of a market index.
+type IndexPeriod string
+
+const (
+	IndexPeriodWeek  IndexPeriod = "week"
+	IndexPeriodMonth IndexPeriod = "month"
+)
+
+// Start returns the start of the period containing t.
+// Weeks start on Monday.
+func (p IndexPeriod) Start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	if p == IndexPeriodWeek {
+		offset := (int(t.Weekday()) + 6) % 7 // Days since Monday
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+}
+
+// Next returns the start of the period following the one starting at start.
+func (p IndexPeriod) Next(start time.Time) time.Time {
+	if p == IndexPeriodWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// MarketIndex is a hedonic rent index per station and per ward.
+type MarketIndex struct {
+	Period       IndexPeriod   `json:"period"`
+	GeneratedAt  time.Time     `json:"generated_at"`
+	LastReportAt time.Time     `json:"last_report_at,omitempty"`
+	Stations     []IndexSeries `json:"stations"`
+	Wards        []IndexSeries `json:"wards"`
+}
+
+// IndexSeries is the index time series of one station or ward.
+type IndexSeries struct {
+	Name   string       `json:"name"`
+	Points []IndexPoint `json:"points"`
+}
+
+// IndexPoint is the index value of one period.
+type IndexPoint struct {
+	Start time.Time `json:"start"` // Start of the period
+	Value float64   `json:"value"` // Quality-adjusted median total rent (yen)
+	Count int       `json:"count"` // Number of listings first seen in the period
+}
+
+// Change returns the relative change between the last two points
+// (e.g., 0.018 for +1.8%) of a series aggregated by period. Returns false if
+// there are fewer than two points or the last two are not consecutive periods
+// (periods with too few listings are left out of the series).
+func (s IndexSeries) Change(period IndexPeriod) (float64, bool) {
+	n := len(s.Points)
+	if n < 2 || s.Points[n-2].Value == 0 {
+		return 0, false
+	}
+	if !s.Points[n-1].Start.Equal(period.Next(s.Points[n-2].Start)) {
+		return 0, false
+	}
+	return s.Points[n-1].Value/s.Points[n-2].Value - 1, true
+}
+
+// Total returns the total number of listings in the series.
+func (s IndexSeries) Total() int {
+	total := 0
+	for _, p := range s.Points {
+		total += p.Count
+	}
+	return total
+}
+
+// prefectureRegex matches the leading prefecture of an address.
+var prefectureRegex = regexp.MustCompile(`^(?:東京都|北海道|(?:京都|大阪)府|[^都道府県\d]{2,3}県)`)
+
+// ParseWard extracts the ward (or city) following the prefecture of an address.
+// Example: "東京都中野区中野5丁目" -> "中野区"
+// Example: "東京都武蔵村山市学園" -> "武蔵村山市"
+// The earliest "区" or "市" wins; "町" and "村" are used only when neither exists,
+// since they also appear inside city names.
+// Returns empty string if the ward cannot be parsed.
+func ParseWard(address string) string {
+	rest := prefectureRegex.ReplaceAllString(strings.TrimSpace(address), "")
+
+	end := suffixEnd(rest, "区", "市")
+	if end < 0 {
+		end = suffixEnd(rest, "町", "村")
+	}
+	if end < 0 {
+		return ""
+	}
+
+	return rest[:end]
+}
+
+// suffixEnd returns the byte offset just after the earliest of the suffixes
+// in s (ignoring a match at the very start), or -1 if none is found.
+func suffixEnd(s string, suffixes ...string) int {
+	best, end := -1, -1
+	for _, suffix := range suffixes {
+		if i := strings.Index(s, suffix); i > 0 && (best < 0 || i < best) {
+			best, end = i, i+len(suffix)
+		}
+	}
+	return end
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWard(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "東京都中野区中野5丁目", want: "中野区"},
+		{input: "東京都北区赤羽1", want: "北区"},
+		{input: "東京都武蔵村山市学園", want: "武蔵村山市"},
+		{input: "東京都羽村市羽東", want: "羽村市"},
+		{input: "神奈川県横浜市中区山下町", want: "横浜市"},
+		{input: "東京都西多摩郡瑞穂町箱根ケ崎", want: "西多摩郡瑞穂町"},
+		{input: "中野区中央1", want: "中野区"},
+		{input: "", want: ""},
+		{input: "Tokyo", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParseWard(tt.input); got != tt.want {
+				t.Errorf("ParseWard(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexPeriodStart(t *testing.T) {
+	// 2025-01-15 is a Wednesday
+	ts := time.Date(2025, 1, 15, 13, 30, 0, 0, time.UTC)
+
+	if got, want := IndexPeriodWeek.Start(ts), time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("IndexPeriodWeek.Start() = %v, want %v", got, want)
+	}
+	if got, want := IndexPeriodMonth.Start(ts), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("IndexPeriodMonth.Start() = %v, want %v", got, want)
+	}
+
+	// Sunday belongs to the week starting the previous Monday
+	sunday := time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)
+	if got, want := IndexPeriodWeek.Start(sunday), time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("IndexPeriodWeek.Start(sunday) = %v, want %v", got, want)
+	}
+
+	if got, want := IndexPeriodMonth.Next(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("IndexPeriodMonth.Next() = %v, want %v", got, want)
+	}
+}
+
+func TestIndexSeriesChange(t *testing.T) {
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := IndexSeries{Points: []IndexPoint{{Start: jan, Value: 100000, Count: 3}, {Start: feb, Value: 102000, Count: 4}}}
+
+	change, ok := s.Change(IndexPeriodMonth)
+	if !ok || change < 0.0199 || change > 0.0201 {
+		t.Errorf("Change() = (%f, %v), want (0.02, true)", change, ok)
+	}
+	if s.Total() != 7 {
+		t.Errorf("Total() = %d, want 7", s.Total())
+	}
+
+	if _, ok := (IndexSeries{Points: s.Points[:1]}).Change(IndexPeriodMonth); ok {
+		t.Error("Change() with a single point should not be ok")
+	}
+
+	// February had too few listings and is missing from the series
+	gap := IndexSeries{Points: []IndexPoint{{Start: jan, Value: 100000, Count: 3}, {Start: mar, Value: 102000, Count: 4}}}
+	if _, ok := gap.Change(IndexPeriodMonth); ok {
+		t.Error("Change() across a missing period should not be ok")
+	}
+
+	// Weekly points a month apart are not consecutive either
+	if _, ok := s.Change(IndexPeriodWeek); ok {
+		t.Error("Change(week) of monthly points should not be ok")
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContractMonths is the contract length over which the deposit and key money
+// are amortised in MonthlyCost.
+const ContractMonths = 24
+
+// RentPerArea returns the rent per m² excluding the management fee (0 if the area is unknown).
+func (p Property) RentPerArea() float64 {
+	if p.Area <= 0 {
+		return 0
+	}
+	return p.Rent / p.Area
+}
+
+// TotalRentPerArea returns the total rent (rent + management fee) per m²
+// (0 if the area is unknown).
+func (p Property) TotalRentPerArea() float64 {
+	if p.Area <= 0 {
+		return 0
+	}
+	return p.TotalRent() / p.Area
+}
+
+// UpfrontCost returns the deposit plus key money in yen.
+// Unparseable values count as 0.
+func (p Property) UpfrontCost() float64 {
+	deposit, _ := ParseUpfrontCost(p.Deposit, p.Rent)
+	keyMoney, _ := ParseUpfrontCost(p.KeyMoney, p.Rent)
+	return deposit + keyMoney
+}
+
+// MonthlyCost returns the total rent plus the deposit and key money
+// amortised over a ContractMonths contract.
+func (p Property) MonthlyCost() float64 {
+	return p.TotalRent() + p.UpfrontCost()/ContractMonths
+}
+
+// Metric is a numeric value of a property used for sorting, filtering and export.
+type Metric struct {
+	Name  string // Name used in configuration and CSV columns
+	Label string // Display name in notifications
+	Value func(p Property) float64
+}
+
+// metricRegistry lists the metrics in CSV column order.
+var metricRegistry = []Metric{
+	{Name: "total_rent", Label: "家賃（管理費込）", Value: Property.TotalRent},
+	{Name: "rent_per_area", Label: "㎡単価（家賃）", Value: Property.RentPerArea},
+	{Name: "total_rent_per_area", Label: "㎡単価（管理費込）", Value: Property.TotalRentPerArea},
+	{Name: "monthly_cost", Label: "実質月額（敷金・礼金を2年で按分）", Value: Property.MonthlyCost},
+}
+
+// Metrics returns the registered property metrics.
+func Metrics() []Metric {
+	return metricRegistry
+}
+
+// LookupMetric returns the metric with the name.
+func LookupMetric(name string) (Metric, error) {
+	name = strings.TrimSpace(name)
+	for _, m := range metricRegistry {
+		if m.Name == name {
+			return m, nil
+		}
+	}
+	return Metric{}, fmt.Errorf("unknown metric: %q", name)
+}
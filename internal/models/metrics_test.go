@@ -0,0 +1,109 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+func TestPropertyMetrics(t *testing.T) {
+	tests := []struct {
+		name            string
+		prop            Property
+		rentPerArea     float64
+		totalPerArea    float64
+		monthlyCost     float64
+		wantUpfrontCost float64
+	}{
+		{
+			name:            "months of rent",
+			prop:            Property{Rent: 80000, ManagementFee: 4000, Deposit: "1ヶ月", KeyMoney: "2ヶ月", Area: 20},
+			rentPerArea:     4000,
+			totalPerArea:    4200,
+			monthlyCost:     84000 + 240000/24.0,
+			wantUpfrontCost: 240000,
+		},
+		{
+			name:            "yen amounts",
+			prop:            Property{Rent: 80000, Deposit: "8万円", KeyMoney: "-", Area: 25},
+			rentPerArea:     3200,
+			totalPerArea:    3200,
+			monthlyCost:     80000 + 80000/24.0,
+			wantUpfrontCost: 80000,
+		},
+		{
+			name:        "unknown area",
+			prop:        Property{Rent: 80000, Deposit: "-", KeyMoney: "-"},
+			monthlyCost: 80000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.prop.RentPerArea(); got != tt.rentPerArea {
+				t.Errorf("RentPerArea() = %v, want %v", got, tt.rentPerArea)
+			}
+			if got := tt.prop.TotalRentPerArea(); got != tt.totalPerArea {
+				t.Errorf("TotalRentPerArea() = %v, want %v", got, tt.totalPerArea)
+			}
+			if got := tt.prop.UpfrontCost(); got != tt.wantUpfrontCost {
+				t.Errorf("UpfrontCost() = %v, want %v", got, tt.wantUpfrontCost)
+			}
+			if got := tt.prop.MonthlyCost(); got != tt.monthlyCost {
+				t.Errorf("MonthlyCost() = %v, want %v", got, tt.monthlyCost)
+			}
+		})
+	}
+}
+
+func TestLookupMetric(t *testing.T) {
+	m, err := LookupMetric("total_rent_per_area")
+	if err != nil {
+		t.Fatalf("LookupMetric() error = %v", err)
+	}
+	if got := m.Value(Property{Rent: 90000, Area: 30}); got != 3000 {
+		t.Errorf("Value() = %v, want 3000", got)
+	}
+
+	if _, err := LookupMetric("unknown"); err == nil {
+		t.Error("LookupMetric() should fail for an unknown metric")
+	}
+}
+
+func TestSaveToCSVMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	props := []Property{{ID: "jnc_001", Rent: 80000, ManagementFee: 4000, Deposit: "1ヶ月", KeyMoney: "-", Area: 20}}
+	if err := SaveToCSV(&buf, props); err != nil {
+		t.Fatalf("SaveToCSV() error = %v", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	columns := make(map[string]string)
+	for i, name := range records[0] {
+		columns[name] = records[1][i]
+	}
+
+	want := map[string]string{
+		"total_rent":          "84000",
+		"rent_per_area":       "4000",
+		"total_rent_per_area": "4200",
+		"monthly_cost":        "87333",
+	}
+	for name, value := range want {
+		if columns[name] != value {
+			t.Errorf("column %s = %q, want %q", name, columns[name], value)
+		}
+	}
+
+	// Derived columns are ignored when loading
+	loaded, err := LoadFromCSV(&buf)
+	if err != nil {
+		t.Fatalf("LoadFromCSV() error = %v", err)
+	}
+	if loaded[0] != props[0] {
+		t.Errorf("Round-tripped property = %+v, want %+v", loaded[0], props[0])
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Property represents a rental property listing from SUUMO.
@@ -24,6 +25,20 @@ type Property struct {
 	WalkMinutes    int     `csv:"walk_minutes"`    // 駅徒歩分数
 	NearestStation string  `csv:"nearest_station"` // 最寄り駅名
 	URL            string  `csv:"url"`             // 物件詳細URL
+	TotalFloors    int     `csv:"total_floors"`    // 建物の階数（地上）
+	Access         string  `csv:"access"`          // 交通（全路線、" / "区切り）
+
+	FirstSeen time.Time `csv:"first_seen"` // 初回掲載確認日時
+	LastSeen  time.Time `csv:"last_seen"`  // 最終掲載確認日時
+
+	// Facilities and building structure, from the detail page. False/empty
+	// also means unknown, e.g. before the detail page is fetched.
+	AutoLock     bool   `csv:"auto_lock"`     // オートロック
+	SeparateBath bool   `csv:"separate_bath"` // バス・トイレ別
+	IndoorWasher bool   `csv:"indoor_washer"` // 室内洗濯機置場
+	CornerUnit   bool   `csv:"corner_unit"`   // 角部屋
+	Structure    string `csv:"structure"`     // 構造（鉄筋コン、木造など）
+	HasDetails   bool   `csv:"has_details"`   // 詳細ページの設備・構造を取得済み
 }
 
 // TotalRent returns the total monthly cost (rent + management fee).
@@ -36,6 +51,12 @@ func (p Property) TotalRentMan() float64 {
 	return p.TotalRent() / 10000
 }
 
+// IsListed reports whether the property was seen in the run at asOf.
+// Properties that were not seen in that run are considered delisted.
+func (p Property) IsListed(asOf time.Time) bool {
+	return !p.LastSeen.Before(asOf)
+}
+
 // UniqueKey generates a unique identifier based on property attributes.
 // This is used to detect duplicate properties that may have different IDs
 // but represent the same physical unit.
@@ -65,6 +86,12 @@ var (
 	// Does not match basement floors like "B1階"
 	floorRegex = regexp.MustCompile(`^(\d+)(?:-\d+)?階`)
 
+	// totalFloorsRegex matches patterns like "3階建", "地下1地上5階建" (takes the above-ground floors)
+	totalFloorsRegex = regexp.MustCompile(`(\d+)階建`)
+
+	// monthsRegex matches deposit/key money given in months of rent, like "1ヶ月", "1.5ヵ月", "2カ月"
+	monthsRegex = regexp.MustCompile(`^([\d.]+)\s*[ヶヵカケか]?月$`)
+
 	// stationRegex matches patterns like "JR中央線/吉祥寺駅 歩8分", "東京メトロ丸ノ内線/新宿駅 歩5分"
 	// Captures the station name (e.g., "吉祥寺", "新宿")
 	stationRegex = regexp.MustCompile(`[/線]?([^/\s]+?)駅`)
@@ -108,6 +135,26 @@ func ParseRent(s string) (float64, error) {
 	return 0, fmt.Errorf("invalid rent format: %q", s)
 }
 
+// ParseUpfrontCost converts a deposit or key money string to yen.
+// Supports the formats of ParseRent and months of rent ("1ヶ月" -> rent).
+// Returns 0 for "-" and empty strings.
+func ParseUpfrontCost(s string, rent float64) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" {
+		return 0, nil
+	}
+
+	if matches := monthsRegex.FindStringSubmatch(s); len(matches) >= 2 {
+		months, err := strconv.ParseFloat(matches[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse months value: %w", err)
+		}
+		return months * rent, nil
+	}
+
+	return ParseRent(s)
+}
+
 // ParseArea converts an area string like "25.5m²" to float64 (25.5).
 // Returns 0 if the string cannot be parsed.
 func ParseArea(s string) (float64, error) {
@@ -199,6 +246,28 @@ func ParseFloor(s string) (int, error) {
 	return value, nil
 }
 
+// ParseTotalFloors converts a building floors string like "5階建" or
+// "地下1地上5階建" to the number of above-ground floors (5).
+// Returns 0 if the string cannot be parsed.
+func ParseTotalFloors(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "-" {
+		return 0, nil
+	}
+
+	matches := totalFloorsRegex.FindStringSubmatch(s)
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("invalid building floors format: %q", s)
+	}
+
+	value, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse building floors value: %w", err)
+	}
+
+	return value, nil
+}
+
 // ParseStationName extracts the station name from an access string.
 // Example: "JR中央線/吉祥寺駅 歩8分" -> "吉祥寺"
 // Example: "東京メトロ丸ノ内線/新宿駅 歩5分" -> "新宿"
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseRent(t *testing.T) {
@@ -519,3 +520,164 @@ func TestUniqueKey(t *testing.T) {
 		t.Errorf("UniqueKey() = %s, want %s", p1.UniqueKey(), expected)
 	}
 }
+
+func TestMarkSeen(t *testing.T) {
+	firstRun := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	now := firstRun.Add(24 * time.Hour)
+
+	previous := []Property{
+		{Address: "東京都渋谷区1", Area: 25.0, Layout: "1K", FirstSeen: firstRun, LastSeen: firstRun},
+	}
+	current := []Property{
+		{Address: "東京都渋谷区1", Area: 25.0, Layout: "1K"},
+		{Address: "東京都新宿区1", Area: 28.0, Layout: "1K"},
+	}
+
+	MarkSeen(current, previous, now)
+
+	if !current[0].FirstSeen.Equal(firstRun) {
+		t.Errorf("FirstSeen of known property = %v, want %v", current[0].FirstSeen, firstRun)
+	}
+	if !current[1].FirstSeen.Equal(now) {
+		t.Errorf("FirstSeen of new property = %v, want %v", current[1].FirstSeen, now)
+	}
+	for i, p := range current {
+		if !p.LastSeen.Equal(now) {
+			t.Errorf("Property[%d].LastSeen = %v, want %v", i, p.LastSeen, now)
+		}
+	}
+
+	if previous[0].IsListed(now) {
+		t.Error("Property not seen at now should not be listed")
+	}
+	if !current[0].IsListed(now) {
+		t.Error("Property seen at now should be listed")
+	}
+}
+
+func TestCarryOverDetails(t *testing.T) {
+	previous := []Property{
+		{Address: "東京都渋谷区1", Area: 25.0, Layout: "1K", AutoLock: true, Structure: "鉄筋コン", HasDetails: true},
+		{Address: "東京都新宿区1", Area: 28.0, Layout: "1K"},
+	}
+	current := []Property{
+		{Address: "東京都渋谷区1", Area: 25.0, Layout: "1K"},
+		{Address: "東京都新宿区1", Area: 28.0, Layout: "1K"},
+		{Address: "東京都中野区1", Area: 30.0, Layout: "1K"},
+	}
+
+	CarryOverDetails(current, previous)
+
+	if !current[0].HasDetails || !current[0].AutoLock || current[0].Structure != "鉄筋コン" {
+		t.Errorf("Known property = %+v, want the previous details", current[0])
+	}
+	for _, p := range current[1:] {
+		if p.HasDetails {
+			t.Errorf("Property without previous details = %+v, want no details", p)
+		}
+	}
+}
+
+func TestCSVRoundTripSeenDates(t *testing.T) {
+	seen := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	original := []Property{
+		{ID: "jnc_001", FirstSeen: seen, LastSeen: seen.Add(time.Hour)},
+		{ID: "jnc_002"},
+	}
+
+	var buf bytes.Buffer
+	if err := SaveToCSV(&buf, original); err != nil {
+		t.Fatalf("SaveToCSV() error = %v", err)
+	}
+
+	loaded, err := LoadFromCSV(&buf)
+	if err != nil {
+		t.Fatalf("LoadFromCSV() error = %v", err)
+	}
+
+	if !loaded[0].FirstSeen.Equal(original[0].FirstSeen) || !loaded[0].LastSeen.Equal(original[0].LastSeen) {
+		t.Errorf("Seen dates = (%v, %v), want (%v, %v)",
+			loaded[0].FirstSeen, loaded[0].LastSeen, original[0].FirstSeen, original[0].LastSeen)
+	}
+	if !loaded[1].FirstSeen.IsZero() {
+		t.Errorf("Zero FirstSeen should round-trip as zero, got %v", loaded[1].FirstSeen)
+	}
+}
+
+func TestCSVRoundTripFacilities(t *testing.T) {
+	original := []Property{
+		{ID: "jnc_001", AutoLock: true, SeparateBath: true, IndoorWasher: true, CornerUnit: true, Structure: "鉄筋コン", HasDetails: true},
+		{ID: "jnc_002", Structure: "木造"},
+	}
+
+	var buf bytes.Buffer
+	if err := SaveToCSV(&buf, original); err != nil {
+		t.Fatalf("SaveToCSV() error = %v", err)
+	}
+
+	loaded, err := LoadFromCSV(&buf)
+	if err != nil {
+		t.Fatalf("LoadFromCSV() error = %v", err)
+	}
+
+	for i := range original {
+		if loaded[i] != original[i] {
+			t.Errorf("Round-tripped property = %+v, want %+v", loaded[i], original[i])
+		}
+	}
+}
+
+func TestParseTotalFloors(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{input: "3階建", want: 3},
+		{input: "地下1地上5階建", want: 5},
+		{input: "12階建", want: 12},
+		{input: "", want: 0},
+		{input: "-", want: 0},
+		{input: "平屋", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTotalFloors(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTotalFloors(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTotalFloors(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUpfrontCost(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{input: "1ヶ月", want: 80000},
+		{input: "1.5ヵ月", want: 120000},
+		{input: "2カ月", want: 160000},
+		{input: "8万円", want: 80000},
+		{input: "-", want: 0},
+		{input: "", want: 0},
+		{input: "応相談", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseUpfrontCost(tt.input, 80000)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUpfrontCost(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseUpfrontCost(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
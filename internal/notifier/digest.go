@@ -0,0 +1,351 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+const (
+	// MaxDigestItems is the maximum number of listings shown per digest section.
+	MaxDigestItems = 5
+
+	// MaxDigestStations is the maximum number of stations in the per-station counts.
+	MaxDigestStations = 10
+)
+
+// DigestMode selects how often digests are sent.
+type DigestMode string
+
+const (
+	// DigestOff notifies new properties on every run.
+	DigestOff DigestMode = "off"
+
+	// DigestDaily sends a digest at the configured times every day.
+	DigestDaily DigestMode = "daily"
+
+	// DigestWeekly sends a digest at the configured times on one weekday.
+	DigestWeekly DigestMode = "weekly"
+)
+
+// Digest accumulates scored new listings, price drops and delistings between
+// digest notifications. It is persisted across runs.
+type Digest struct {
+	Since          time.Time            `json:"since"`                  // Start of the accumulation
+	LastSentAt     time.Time            `json:"last_sent_at,omitempty"` // Time the previous digest was sent
+	LastMedianRent float64              `json:"last_median_rent"`       // Median total rent of the new listings in the previous digest
+	Listings       []PropertyWithScore  `json:"listings"`
+	PriceDrops     []models.PriceChange `json:"price_drops"`
+	Delistings     []models.Property    `json:"delistings"`
+}
+
+// Add accumulates a run into the digest. Price increases are ignored.
+// Listings are deduplicated by UniqueKey; a repeated price drop keeps the
+// rent before the first drop.
+func (d *Digest) Add(scored []PropertyWithScore, changes []models.PriceChange, delisted []models.Property, now time.Time) {
+	if d.Since.IsZero() {
+		d.Since = now
+	}
+
+	listed := make(map[string]bool)
+	for _, p := range d.Listings {
+		listed[p.Property.UniqueKey()] = true
+	}
+	for _, p := range scored {
+		if !listed[p.Property.UniqueKey()] {
+			listed[p.Property.UniqueKey()] = true
+			d.Listings = append(d.Listings, p)
+		}
+	}
+
+	drops := make(map[string]int)
+	for i, c := range d.PriceDrops {
+		drops[c.Property.UniqueKey()] = i
+	}
+	for _, c := range changes {
+		if c.Difference() >= 0 {
+			continue
+		}
+		if i, ok := drops[c.Property.UniqueKey()]; ok {
+			d.PriceDrops[i].Property = c.Property
+			continue
+		}
+		drops[c.Property.UniqueKey()] = len(d.PriceDrops)
+		d.PriceDrops = append(d.PriceDrops, c)
+	}
+
+	gone := make(map[string]bool)
+	for _, p := range d.Delistings {
+		gone[p.UniqueKey()] = true
+	}
+	for _, p := range delisted {
+		if !gone[p.UniqueKey()] {
+			gone[p.UniqueKey()] = true
+			d.Delistings = append(d.Delistings, p)
+		}
+	}
+}
+
+// Empty reports whether nothing was accumulated.
+func (d *Digest) Empty() bool {
+	return len(d.Listings) == 0 && len(d.PriceDrops) == 0 && len(d.Delistings) == 0
+}
+
+// Reset starts a new accumulation after the digest was sent at now.
+func (d *Digest) Reset(now time.Time) {
+	if rent, ok := medianTotalRent(d.Listings); ok {
+		d.LastMedianRent = rent
+	}
+	d.Since = now
+	d.LastSentAt = now
+	d.Listings = nil
+	d.PriceDrops = nil
+	d.Delistings = nil
+}
+
+// DigestSchedule is the set of times digests are sent.
+type DigestSchedule struct {
+	Mode     DigestMode
+	Times    []time.Duration // Times of day as offsets from midnight
+	Weekday  time.Weekday    // Day of the week for DigestWeekly
+	Location *time.Location
+}
+
+// weekdays maps weekday names to time.Weekday.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseDigestSchedule parses a digest mode ("off", "daily" or "weekly"),
+// times of day ("08:00"), a weekday ("mon") used by the weekly mode and a
+// time zone name.
+func ParseDigestSchedule(mode string, times []string, weekday, timezone string) (DigestSchedule, error) {
+	s := DigestSchedule{Mode: DigestMode(mode)}
+	switch s.Mode {
+	case DigestOff:
+		return s, nil
+	case DigestDaily, DigestWeekly:
+	default:
+		return s, fmt.Errorf("unknown digest mode: %q", mode)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return s, fmt.Errorf("failed to load time zone: %w", err)
+	}
+	s.Location = loc
+
+	for _, t := range times {
+		clock, err := time.Parse("15:04", strings.TrimSpace(t))
+		if err != nil {
+			return s, fmt.Errorf("invalid digest time %q: %w", t, err)
+		}
+		s.Times = append(s.Times, time.Duration(clock.Hour())*time.Hour+time.Duration(clock.Minute())*time.Minute)
+	}
+	if len(s.Times) == 0 {
+		return s, fmt.Errorf("no digest times")
+	}
+
+	if s.Mode == DigestWeekly {
+		day, ok := weekdays[strings.ToLower(strings.TrimSpace(weekday))]
+		if !ok {
+			return s, fmt.Errorf("unknown weekday: %q", weekday)
+		}
+		s.Weekday = day
+	}
+
+	return s, nil
+}
+
+// Enabled reports whether properties are accumulated into digests.
+func (s DigestSchedule) Enabled() bool {
+	return s.Mode == DigestDaily || s.Mode == DigestWeekly
+}
+
+// Due reports whether a scheduled time has passed since the digest was last
+// sent (or started accumulating).
+func (s DigestSchedule) Due(d Digest, now time.Time) bool {
+	if !s.Enabled() {
+		return false
+	}
+	since := d.LastSentAt
+	if since.IsZero() {
+		since = d.Since
+	}
+	latest, ok := s.latest(now)
+	return ok && latest.After(since)
+}
+
+// latest returns the most recent scheduled time at or before now.
+func (s DigestSchedule) latest(now time.Time) (time.Time, bool) {
+	local := now.In(s.Location)
+	y, m, d := local.Date()
+	for days := 0; days <= 7; days++ {
+		midnight := time.Date(y, m, d-days, 0, 0, 0, 0, s.Location)
+		if s.Mode == DigestWeekly && midnight.Weekday() != s.Weekday {
+			continue
+		}
+		var latest time.Time
+		for _, offset := range s.Times {
+			t := midnight.Add(offset)
+			if !t.After(now) && t.After(latest) {
+				latest = t
+			}
+		}
+		if !latest.IsZero() {
+			return latest, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// NotifyDigest sends the accumulated digest ending at now. Times are shown
+// in the location of now. Nothing is sent if the digest is empty.
+func (n *Notifier) NotifyDigest(ctx context.Context, d Digest, now time.Time) error {
+	if d.Empty() {
+		return nil
+	}
+
+	for _, msg := range n.formatDigest(d, now) {
+		if err := n.send(ctx, msg); err != nil {
+			return fmt.Errorf("failed to send digest: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// formatDigest creates digest messages: the totals, top bargains, counts per
+// station, the median rent movement, price drops and delistings.
+// Messages are split if they exceed MaxMessageLength.
+func (n *Notifier) formatDigest(d Digest, now time.Time) []string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("🗞 **ダイジェスト（%s〜%s）**\n", d.Since.In(now.Location()).Format("1/2 15:04"), now.Format("1/2 15:04")))
+	lines = append(lines, fmt.Sprintf("新着 %d件 / 値下げ %d件 / 掲載終了 %d件\n", len(d.Listings), len(d.PriceDrops), len(d.Delistings)))
+
+	if rent, ok := medianTotalRent(d.Listings); ok {
+		line := fmt.Sprintf("💹 新着の家賃中央値 %.1f万円", rent/10000)
+		if d.LastMedianRent > 0 {
+			line += fmt.Sprintf("（前回比 %+.1f%%）", (rent/d.LastMedianRent-1)*100)
+		}
+		lines = append(lines, line+"\n")
+	}
+
+	var bargains []PropertyWithScore
+	for _, p := range SortScore.rank(d.Listings) {
+		// Bargains not suspected as bait
+		if labelPriority(p) == 0 && len(bargains) < MaxDigestItems {
+			bargains = append(bargains, p)
+		}
+	}
+	if len(bargains) > 0 {
+		lines = append(lines, "\n**お買い得**\n")
+		for _, p := range bargains {
+			lines = append(lines, fmt.Sprintf("・%s %.1f万円（相場より %s円/月 お得）\n%s\n",
+				p.Property.Name, p.Property.TotalRentMan(), formatThousands(int(p.Score)), p.Property.URL))
+		}
+	}
+
+	if stations := formatStationCounts(d.Listings); stations != "" {
+		lines = append(lines, "\n**駅別の新着**\n", stations+"\n")
+	}
+
+	if len(d.PriceDrops) > 0 {
+		drops := append([]models.PriceChange(nil), d.PriceDrops...)
+		sort.SliceStable(drops, func(i, j int) bool { return drops[i].Difference() < drops[j].Difference() })
+		lines = append(lines, "\n**値下げ**\n")
+		for i, c := range drops {
+			if i == MaxDigestItems {
+				lines = append(lines, fmt.Sprintf("他%d件\n", len(drops)-MaxDigestItems))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("・%s %.1f→%.1f万円（-%s円）\n%s\n",
+				c.Property.Name, c.PreviousRent/10000, c.Property.TotalRentMan(), formatThousands(int(-c.Difference())), c.Property.URL))
+		}
+	}
+
+	if len(d.Delistings) > 0 {
+		lines = append(lines, "\n**掲載終了**\n")
+		for i, p := range d.Delistings {
+			if i == MaxDigestItems {
+				lines = append(lines, fmt.Sprintf("他%d件\n", len(d.Delistings)-MaxDigestItems))
+				break
+			}
+			line := fmt.Sprintf("・%s %.1f万円", p.Name, p.TotalRentMan())
+			if !p.FirstSeen.IsZero() && !p.LastSeen.IsZero() {
+				line += fmt.Sprintf("（掲載%d日）", int(p.LastSeen.Sub(p.FirstSeen).Hours()/24)+1)
+			}
+			lines = append(lines, line+"\n")
+		}
+	}
+
+	var messages []string
+	var currentMsg strings.Builder
+	for _, line := range lines {
+		if currentMsg.Len()+len(line) > MaxMessageLength {
+			messages = append(messages, currentMsg.String())
+			currentMsg.Reset()
+			currentMsg.WriteString("🗞 **ダイジェスト（続き）**\n")
+		}
+		currentMsg.WriteString(line)
+	}
+	if currentMsg.Len() > 0 {
+		messages = append(messages, currentMsg.String())
+	}
+
+	return messages
+}
+
+// formatStationCounts formats the number of listings per station, most first,
+// e.g. "中野 5件・高円寺 3件".
+func formatStationCounts(listings []PropertyWithScore) string {
+	counts := make(map[string]int)
+	for _, p := range listings {
+		if p.Property.NearestStation != "" {
+			counts[p.Property.NearestStation]++
+		}
+	}
+
+	stations := make([]string, 0, len(counts))
+	for s := range counts {
+		stations = append(stations, s)
+	}
+	sort.Slice(stations, func(i, j int) bool {
+		if counts[stations[i]] != counts[stations[j]] {
+			return counts[stations[i]] > counts[stations[j]]
+		}
+		return stations[i] < stations[j]
+	})
+	if len(stations) > MaxDigestStations {
+		stations = stations[:MaxDigestStations]
+	}
+
+	parts := make([]string, len(stations))
+	for i, s := range stations {
+		parts[i] = s + " " + strconv.Itoa(counts[s]) + "件"
+	}
+	return strings.Join(parts, "・")
+}
+
+// medianTotalRent returns the median total rent of the listings.
+func medianTotalRent(listings []PropertyWithScore) (float64, bool) {
+	if len(listings) == 0 {
+		return 0, false
+	}
+	rents := make([]float64, len(listings))
+	for i, p := range listings {
+		rents[i] = p.Property.TotalRent()
+	}
+	sort.Float64s(rents)
+	mid := len(rents) / 2
+	if len(rents)%2 == 0 {
+		return (rents[mid-1] + rents[mid]) / 2, true
+	}
+	return rents[mid], true
+}
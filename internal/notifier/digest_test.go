@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestDigestAdd(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	unit := models.Property{ID: "1", Name: "中野ハイツ", Address: "中野1", Area: 25, Layout: "1K", Rent: 80000}
+	dropped := unit
+	dropped.Rent = 78000
+	droppedAgain := unit
+	droppedAgain.Rent = 75000
+	raised := models.Property{ID: "2", Address: "中野2", Area: 30, Layout: "1DK", Rent: 95000}
+
+	var d Digest
+	d.Add([]PropertyWithScore{{Property: unit}}, []models.PriceChange{{Property: dropped, PreviousRent: 80000}}, []models.Property{raised}, now)
+	d.Add([]PropertyWithScore{{Property: unit}},
+		[]models.PriceChange{{Property: droppedAgain, PreviousRent: 78000}, {Property: raised, PreviousRent: 90000}},
+		[]models.Property{raised}, now.Add(time.Hour))
+
+	if !d.Since.Equal(now) {
+		t.Errorf("Since = %v, want %v", d.Since, now)
+	}
+	if len(d.Listings) != 1 || len(d.Delistings) != 1 {
+		t.Errorf("Listings and delistings should be deduplicated, got %d and %d", len(d.Listings), len(d.Delistings))
+	}
+	if len(d.PriceDrops) != 1 {
+		t.Fatalf("Expected 1 price drop (increases ignored), got %d", len(d.PriceDrops))
+	}
+	if c := d.PriceDrops[0]; c.PreviousRent != 80000 || c.Property.Rent != 75000 {
+		t.Errorf("Price drop = %v→%v, want 80000→75000", c.PreviousRent, c.Property.Rent)
+	}
+
+	sent := now.Add(2 * time.Hour)
+	d.Reset(sent)
+	if !d.Empty() || !d.LastSentAt.Equal(sent) || d.LastMedianRent != 80000 {
+		t.Errorf("Reset digest = %+v, want empty, sent at %v with median 80000", d, sent)
+	}
+}
+
+func TestParseDigestSchedule(t *testing.T) {
+	if s, err := ParseDigestSchedule("off", nil, "", ""); err != nil || s.Enabled() {
+		t.Errorf("ParseDigestSchedule(off) = (%+v, %v), want disabled", s, err)
+	}
+
+	s, err := ParseDigestSchedule("weekly", []string{"08:00", " 20:30"}, "Fri", "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("ParseDigestSchedule() error = %v", err)
+	}
+	if len(s.Times) != 2 || s.Times[1] != 20*time.Hour+30*time.Minute || s.Weekday != time.Friday {
+		t.Errorf("Schedule = %+v", s)
+	}
+
+	for _, tt := range []struct {
+		mode, time, weekday, timezone string
+	}{
+		{"hourly", "08:00", "mon", "UTC"},
+		{"daily", "8am", "mon", "UTC"},
+		{"daily", "08:00", "mon", "Mars/Olympus"},
+		{"weekly", "08:00", "someday", "UTC"},
+	} {
+		if _, err := ParseDigestSchedule(tt.mode, []string{tt.time}, tt.weekday, tt.timezone); err == nil {
+			t.Errorf("ParseDigestSchedule(%v) should return an error", tt)
+		}
+	}
+}
+
+func TestDigestScheduleDue(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	daily := DigestSchedule{Mode: DigestDaily, Times: []time.Duration{8 * time.Hour, 20 * time.Hour}, Location: jst}
+	// 2025-01-06 is a Monday
+	weekly := DigestSchedule{Mode: DigestWeekly, Times: []time.Duration{8 * time.Hour}, Weekday: time.Monday, Location: jst}
+
+	at := func(day, hour int) time.Time { return time.Date(2025, 1, day, hour, 0, 0, 0, jst) }
+
+	tests := []struct {
+		name     string
+		schedule DigestSchedule
+		digest   Digest
+		now      time.Time
+		want     bool
+	}{
+		{"before first time", daily, Digest{Since: at(6, 1)}, at(6, 7), false},
+		{"after first time", daily, Digest{Since: at(6, 1)}, at(6, 8), true},
+		{"already sent", daily, Digest{Since: at(6, 8), LastSentAt: at(6, 8)}, at(6, 19), false},
+		{"second time", daily, Digest{Since: at(6, 8), LastSentAt: at(6, 8)}, at(6, 21), true},
+		{"previous day", daily, Digest{Since: at(5, 21), LastSentAt: at(5, 21)}, at(6, 1), false},
+		{"weekly on other day", weekly, Digest{Since: at(6, 9), LastSentAt: at(6, 9)}, at(10, 9), false},
+		{"weekly next week", weekly, Digest{Since: at(6, 9), LastSentAt: at(6, 9)}, at(13, 9), true},
+		{"disabled", DigestSchedule{Mode: DigestOff}, Digest{Since: at(1, 0)}, at(13, 9), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Due(tt.digest, tt.now); got != tt.want {
+				t.Errorf("Due() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDigest(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2025, 1, 6, 8, 0, 0, 0, jst)
+	d := Digest{
+		Since:          now.Add(-24 * time.Hour),
+		LastMedianRent: 80000,
+		Listings: []PropertyWithScore{
+			{Property: models.Property{Name: "お得A", Rent: 80000, NearestStation: "中野", URL: "https://suumo.jp/a/"}, Score: 15000, Label: ScoreLabelBargain},
+			{Property: models.Property{Name: "標準B", Rent: 90000, NearestStation: "中野"}, Score: 0, Label: ScoreLabelStandard},
+			{Property: models.Property{Name: "釣りC", Rent: 70000, NearestStation: "高円寺"}, Score: 40000, Label: ScoreLabelBargain, Duplicates: &DuplicateInfo{SuspectedBait: true}},
+		},
+		PriceDrops: []models.PriceChange{{Property: models.Property{Name: "値下げD", Rent: 79500}, PreviousRent: 80000}},
+		Delistings: []models.Property{{Name: "終了E", Rent: 85000, FirstSeen: now.Add(-72 * time.Hour), LastSeen: now.Add(-time.Hour)}},
+	}
+
+	message := strings.Join(NewNotifier("").formatDigest(d, now), "")
+	for _, want := range []string{
+		"🗞 **ダイジェスト（1/5 08:00〜1/6 08:00）**",
+		"新着 3件 / 値下げ 1件 / 掲載終了 1件",
+		"💹 新着の家賃中央値 8.0万円（前回比 +0.0%）",
+		"・お得A 8.0万円（相場より 15,000円/月 お得）",
+		"中野 2件・高円寺 1件",
+		"・値下げD 8.0→8.0万円（-500円）",
+		"・終了E 8.5万円（掲載3日）",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("formatDigest() should contain %q, got %q", want, message)
+		}
+	}
+	if strings.Contains(message, "・釣りC") {
+		t.Errorf("Suspected bait should not be listed as a bargain, got %q", message)
+	}
+}
@@ -7,9 +7,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/alp/suumo-hunter/internal/metrics"
 	"github.com/alp/suumo-hunter/internal/models"
 )
 
@@ -25,6 +30,9 @@ const (
 
 	// ExpensiveThreshold is the threshold (in yen) for considering a property expensive.
 	ExpensiveThreshold = -10000
+
+	// MaxExplanationItems is the maximum number of contributions shown in an explanation line.
+	MaxExplanationItems = 3
 )
 
 // ScoreLabel represents the bargain level of a property.
@@ -39,9 +47,53 @@ const (
 
 // PropertyWithScore represents a property with its bargain score.
 type PropertyWithScore struct {
-	Property models.Property
-	Score    float64    // Bargain score in yen (positive = cheaper than expected)
-	Label    ScoreLabel // Score label
+	Property    models.Property
+	Score       float64              // Bargain score in yen (positive = cheaper than expected)
+	Label       ScoreLabel           // Score label
+	Comparables *ComparablesEstimate // Comparables-based estimate (nil if unavailable)
+	Explanation []Contribution       // Predicted rent breakdown, largest first (nil if unavailable)
+	Band        *RentBand            // Rent band from quantile regression (nil if unavailable)
+	Duplicates  *DuplicateInfo       // Other listings of the same unit (nil if none)
+	Building    *BuildingInfo        // Vacancies in the same building (nil if none)
+}
+
+// BuildingInfo describes the current vacancies in the building of a property.
+type BuildingInfo struct {
+	Vacancies      int     // Units currently listed, including this one
+	MinRentPerArea float64 // Lowest total rent per m² among the vacancies (yen)
+	MaxRentPerArea float64 // Highest total rent per m² among the vacancies (yen)
+	NewVacancy     bool    // The unit was first listed in this run in a building already known
+}
+
+// DuplicateInfo describes the listings of the same physical unit across IDs.
+type DuplicateInfo struct {
+	Listings      int     // Number of listings of the unit, including this one
+	OthersMedian  float64 // Median total rent of the other listings (yen, 0 if none)
+	Reappearances int     // Times the unit was listed again after being delisted
+	SuspectedBait bool    // Much cheaper than both the model and the other listings
+	Reappearing   bool    // Reappeared repeatedly
+}
+
+// RentBand is the distribution of total rent for listings with the same
+// features, estimated by quantile regression.
+type RentBand struct {
+	Lower          float64 // 10th percentile (yen)
+	Median         float64 // 50th percentile (yen)
+	Upper          float64 // 90th percentile (yen)
+	PercentileRank float64 // Percentile of the actual rent (1–99)
+}
+
+// Contribution is the effect of one feature on the predicted rent,
+// relative to the average of the dataset.
+type Contribution struct {
+	Name   string  // Feature name (e.g., "駅", "築年")
+	Amount float64 // Contribution in yen
+}
+
+// ComparablesEstimate is a rent estimate derived from similar nearby listings.
+type ComparablesEstimate struct {
+	Median     float64           // Median total rent of the comparables (yen)
+	Properties []models.Property // Comparable listings, most similar first
 }
 
 // CalculateScoreLabel determines the score label based on the score value.
@@ -67,8 +119,15 @@ type discordPayload struct {
 
 // Notifier sends notifications via Discord Webhook.
 type Notifier struct {
-	webhookURL string
-	client     HTTPClient
+	webhookURL       string
+	client           HTTPClient
+	showExplanations bool
+	sortOrder        SortOrder
+	maxProperties    int
+	output           io.Writer
+	dryRun           bool
+	logger           *slog.Logger
+	metrics          metrics.Recorder
 }
 
 // Option is a function that configures a Notifier.
@@ -81,11 +140,51 @@ func WithHTTPClient(c HTTPClient) Option {
 	}
 }
 
+// WithOutput writes messages to w instead of posting them to the webhook,
+// e.g. to preview notifications.
+func WithOutput(w io.Writer) Option {
+	return func(n *Notifier) {
+		n.output = w
+	}
+}
+
+// WithDryRun logs messages instead of posting them to the webhook.
+func WithDryRun(enabled bool) Option {
+	return func(n *Notifier) {
+		n.dryRun = enabled
+	}
+}
+
+// WithLogger sets the logger. Defaults to slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(n *Notifier) {
+		n.logger = l
+	}
+}
+
+// WithMetrics sets the recorder of sent and failed messages.
+func WithMetrics(m metrics.Recorder) Option {
+	return func(n *Notifier) {
+		n.metrics = m
+	}
+}
+
+// WithExplanations enables the per-feature explanation line for scored properties.
+func WithExplanations(enabled bool) Option {
+	return func(n *Notifier) {
+		n.showExplanations = enabled
+	}
+}
+
 // NewNotifier creates a new Notifier with the given Discord webhook URL.
 func NewNotifier(webhookURL string, opts ...Option) *Notifier {
 	n := &Notifier{
-		webhookURL: webhookURL,
-		client:     http.DefaultClient,
+		webhookURL:    webhookURL,
+		client:        http.DefaultClient,
+		sortOrder:     SortScore,
+		maxProperties: MaxPropertiesPerNotification,
+		logger:        slog.Default(),
+		metrics:       metrics.Nop{},
 	}
 
 	for _, opt := range opts {
@@ -96,8 +195,9 @@ func NewNotifier(webhookURL string, opts ...Option) *Notifier {
 }
 
 // Notify sends a notification for new properties.
-// If there are more than MaxPropertiesPerNotification properties,
-// only the first MaxPropertiesPerNotification are shown with a summary.
+// Properties are ranked by label and the sort order. If there are more than
+// the maximum number of properties (MaxPropertiesPerNotification by default),
+// only the top ones are shown and the rest are summarized by label.
 func (n *Notifier) Notify(ctx context.Context, properties []PropertyWithScore) error {
 	if len(properties) == 0 {
 		return nil
@@ -123,12 +223,11 @@ func (n *Notifier) formatMessages(properties []PropertyWithScore) []string {
 	// Header
 	currentMsg.WriteString("🏠 **新着物件のお知らせ**\n")
 
-	// Limit to MaxPropertiesPerNotification
-	displayProps := properties
-	remaining := 0
-	if len(properties) > MaxPropertiesPerNotification {
-		displayProps = properties[:MaxPropertiesPerNotification]
-		remaining = len(properties) - MaxPropertiesPerNotification
+	// Show the top properties and summarize the rest
+	displayProps := n.sortOrder.rank(properties)
+	var remaining []PropertyWithScore
+	if n.maxProperties > 0 && len(displayProps) > n.maxProperties {
+		displayProps, remaining = displayProps[:n.maxProperties], displayProps[n.maxProperties:]
 	}
 
 	for _, prop := range displayProps {
@@ -146,8 +245,8 @@ func (n *Notifier) formatMessages(properties []PropertyWithScore) []string {
 	}
 
 	// Add remaining count if any
-	if remaining > 0 {
-		summary := fmt.Sprintf("\n📋 他%d件の新着あり\n", remaining)
+	if len(remaining) > 0 {
+		summary := formatRemaining(remaining)
 		if currentMsg.Len()+len(summary) > MaxMessageLength {
 			messages = append(messages, currentMsg.String())
 			currentMsg.Reset()
@@ -173,9 +272,19 @@ func (n *Notifier) formatPropertyEntry(prop PropertyWithScore) string {
 	// Address
 	sb.WriteString(fmt.Sprintf("📍 %s\n", prop.Property.Address))
 
-	// Total rent in 万円
+	// Total rent in 万円, with the price per m² when the area is known
 	totalRent := prop.Property.TotalRentMan()
-	sb.WriteString(fmt.Sprintf("💰 %.1f万円（管理費込）\n", totalRent))
+	if perArea := prop.Property.TotalRentPerArea(); perArea > 0 {
+		sb.WriteString(fmt.Sprintf("💰 %.1f万円（管理費込・㎡単価 %s円）\n", totalRent, formatThousands(int(math.Round(perArea)))))
+	} else {
+		sb.WriteString(fmt.Sprintf("💰 %.1f万円（管理費込）\n", totalRent))
+	}
+
+	// Monthly cost with the deposit and key money amortised over the contract
+	if prop.Property.UpfrontCost() > 0 {
+		sb.WriteString(fmt.Sprintf("🧾 実質 %.1f万円/月（敷金・礼金を%dヶ月で按分）\n",
+			prop.Property.MonthlyCost()/10000, models.ContractMonths))
+	}
 
 	// Score
 	if prop.Label != ScoreLabelAnalyzing {
@@ -184,6 +293,45 @@ func (n *Notifier) formatPropertyEntry(prop PropertyWithScore) string {
 		} else {
 			sb.WriteString(fmt.Sprintf("💴 相場より %.0f円/月 高い\n", -prop.Score))
 		}
+
+		if b := prop.Band; b != nil {
+			sb.WriteString(fmt.Sprintf("📊 同条件で%sの家賃（相場帯 %.1f〜%.1f万円）\n",
+				formatPercentileRank(b.PercentileRank), b.Lower/10000, b.Upper/10000))
+		}
+
+		if n.showExplanations {
+			if line := formatExplanation(prop.Explanation); line != "" {
+				sb.WriteString(fmt.Sprintf("📐 %s\n", line))
+			}
+		}
+	}
+
+	// Building vacancies
+	if b := prop.Building; b != nil {
+		sb.WriteString(formatBuildingInfo(b))
+	}
+
+	// Duplicate listings
+	if d := prop.Duplicates; d != nil {
+		if d.SuspectedBait {
+			sb.WriteString("⚠️ 釣り物件の疑い（相場・同一物件の他掲載より大幅に安い）\n")
+		}
+		if d.Listings > 1 {
+			sb.WriteString(fmt.Sprintf("👥 同一物件 %d件掲載（他掲載の中央値 %.1f万円）\n", d.Listings, d.OthersMedian/10000))
+		}
+		if d.Reappearing {
+			sb.WriteString(fmt.Sprintf("🔁 再掲載 %d回\n", d.Reappearances))
+		}
+	}
+
+	// Comparables
+	if c := prop.Comparables; c != nil && len(c.Properties) > 0 {
+		sb.WriteString(fmt.Sprintf("🏘 近隣類似物件 %d件の中央値 %.1f万円\n", len(c.Properties), c.Median/10000))
+		links := make([]string, len(c.Properties))
+		for i, cp := range c.Properties {
+			links[i] = fmt.Sprintf("[%.1f万](<%s>)", cp.TotalRentMan(), cp.URL)
+		}
+		sb.WriteString(fmt.Sprintf("　└ %s\n", strings.Join(links, " ")))
 	}
 
 	// URL
@@ -192,8 +340,86 @@ func (n *Notifier) formatPropertyEntry(prop PropertyWithScore) string {
 	return sb.String()
 }
 
-// send sends a message to Discord Webhook.
+// formatBuildingInfo formats the vacancies of a building as
+// "🏢 同じ建物で新たに空室（空室3件、㎡単価 3,100〜3,600円）".
+func formatBuildingInfo(b *BuildingInfo) string {
+	var sb strings.Builder
+	if b.NewVacancy {
+		sb.WriteString("🏢 同じ建物で新たに空室（")
+	} else {
+		sb.WriteString("🏢 同じ建物の空室（")
+	}
+	sb.WriteString(fmt.Sprintf("空室%d件", b.Vacancies))
+	if b.Vacancies > 1 && b.MaxRentPerArea > 0 {
+		sb.WriteString(fmt.Sprintf("、㎡単価 %s〜%s円",
+			formatThousands(int(math.Round(b.MinRentPerArea))), formatThousands(int(math.Round(b.MaxRentPerArea)))))
+	}
+	sb.WriteString("）\n")
+	return sb.String()
+}
+
+// formatPercentileRank formats a percentile rank as "下位8%" or "上位15%".
+func formatPercentileRank(rank float64) string {
+	if rank <= 50 {
+		return fmt.Sprintf("下位%.0f%%", rank)
+	}
+	return fmt.Sprintf("上位%.0f%%", 100-rank)
+}
+
+// formatExplanation formats the largest contributions as a compact line,
+// e.g. "駅+8,000 / 築年-5,000 / 面積+3,000". Amounts are rounded to 100 yen
+// and contributions that round to zero are omitted.
+func formatExplanation(contributions []Contribution) string {
+	parts := make([]string, 0, MaxExplanationItems)
+	for _, c := range contributions {
+		if len(parts) == MaxExplanationItems {
+			break
+		}
+		amount := int(math.Round(c.Amount/100) * 100)
+		if amount == 0 {
+			continue
+		}
+		sign := "+"
+		if amount < 0 {
+			sign = "-"
+			amount = -amount
+		}
+		parts = append(parts, c.Name+sign+formatThousands(amount))
+	}
+	return strings.Join(parts, " / ")
+}
+
+// formatThousands formats a non-negative integer with comma separators.
+func formatThousands(v int) string {
+	s := strconv.Itoa(v)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// send sends a message to Discord Webhook, or writes it to the output if set.
 func (n *Notifier) send(ctx context.Context, message string) error {
+	if n.output != nil {
+		if _, err := fmt.Fprintln(n.output, message); err != nil {
+			return fmt.Errorf("failed to write message: %w", err)
+		}
+		return nil
+	}
+	if n.dryRun {
+		n.logger.InfoContext(ctx, "Dry run: skipping Discord message", "length", len([]rune(message)), "message", message)
+		return nil
+	}
+
+	start := time.Now()
+	err := n.post(ctx, message)
+	n.metrics.Count("notifier_messages_total", 1, metrics.Result(err))
+	n.metrics.Timing("notifier_send_duration_seconds", time.Since(start))
+	return err
+}
+
+// post posts a message to the Discord webhook.
+func (n *Notifier) post(ctx context.Context, message string) error {
 	payload := discordPayload{Content: message}
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -218,6 +444,7 @@ func (n *Notifier) send(ctx context.Context, message string) error {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("Discord Webhook returned status %d: %s", resp.StatusCode, string(body))
 	}
+	n.logger.DebugContext(ctx, "Sent Discord message", "length", len([]rune(message)))
 
 	return nil
 }
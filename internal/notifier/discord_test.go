@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/alp/suumo-hunter/internal/metrics"
 	"github.com/alp/suumo-hunter/internal/models"
 )
 
@@ -231,6 +232,42 @@ func TestNotifyError(t *testing.T) {
 	}
 }
 
+func TestNotifyMetrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   string
+	}{
+		{name: "success", status: http.StatusNoContent, want: `notifier_messages_total{result="success"} 1`},
+		{name: "error", status: http.StatusUnauthorized, want: `notifier_messages_total{result="error"} 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockHTTPClient{
+				doFunc: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: tt.status,
+						Body:       io.NopCloser(bytes.NewReader(nil)),
+					}, nil
+				},
+			}
+			registry := metrics.NewRegistry("")
+			notifier := NewNotifier("https://discord.com/api/webhooks/test", WithHTTPClient(mock), WithMetrics(registry))
+
+			properties := []PropertyWithScore{{Property: models.Property{Name: "Test"}, Label: ScoreLabelStandard}}
+			_ = notifier.Notify(context.Background(), properties)
+
+			var buf bytes.Buffer
+			if err := registry.WriteText(&buf); err != nil {
+				t.Fatalf("WriteText() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("Metrics lack %q:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
 func TestFormatPropertyEntry(t *testing.T) {
 	notifier := NewNotifier("https://discord.com/api/webhooks/test")
 
@@ -284,6 +321,27 @@ func TestFormatPropertyEntry(t *testing.T) {
 			},
 			contains: []string{"分析中マンション", "10.5万円"},
 		},
+		{
+			name: "property with comparables",
+			prop: PropertyWithScore{
+				Property: models.Property{
+					Name:          "比較マンション",
+					Rent:          85000,
+					ManagementFee: 5000,
+					URL:           "https://suumo.jp/test/",
+				},
+				Score: 2000,
+				Label: ScoreLabelStandard,
+				Comparables: &ComparablesEstimate{
+					Median: 92000,
+					Properties: []models.Property{
+						{Rent: 92000, URL: "https://suumo.jp/comp1/"},
+						{Rent: 90000, URL: "https://suumo.jp/comp2/"},
+					},
+				},
+			},
+			contains: []string{"近隣類似物件 2件の中央値 9.2万円", "[9.2万](<https://suumo.jp/comp1/>)"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -353,3 +411,170 @@ func TestMessageSplitting(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatExplanation(t *testing.T) {
+	contributions := []Contribution{
+		{Name: "駅", Amount: 8023},
+		{Name: "築年", Amount: -5049},
+		{Name: "階数", Amount: 20},
+		{Name: "面積", Amount: 3000},
+		{Name: "徒歩", Amount: -1000},
+	}
+
+	got := formatExplanation(contributions)
+	want := "駅+8,000 / 築年-5,000 / 面積+3,000"
+	if got != want {
+		t.Errorf("formatExplanation() = %q, want %q", got, want)
+	}
+
+	if got := formatExplanation(nil); got != "" {
+		t.Errorf("formatExplanation(nil) = %q, want empty", got)
+	}
+}
+
+func TestFormatPropertyEntryExplanation(t *testing.T) {
+	prop := PropertyWithScore{
+		Property:    models.Property{Name: "説明マンション", Rent: 80000},
+		Score:       12800,
+		Label:       ScoreLabelBargain,
+		Explanation: []Contribution{{Name: "駅", Amount: 12000}},
+	}
+
+	if result := NewNotifier("").formatPropertyEntry(prop); strings.Contains(result, "駅+12,000") {
+		t.Errorf("Explanation should be hidden by default, got %q", result)
+	}
+
+	result := NewNotifier("", WithExplanations(true)).formatPropertyEntry(prop)
+	if !strings.Contains(result, "📐 駅+12,000") {
+		t.Errorf("formatPropertyEntry() should contain explanation, got %q", result)
+	}
+}
+
+func TestFormatPropertyEntryBand(t *testing.T) {
+	tests := []struct {
+		rank float64
+		want string
+	}{
+		{rank: 8, want: "📊 同条件で下位8%の家賃（相場帯 8.1〜10.2万円）"},
+		{rank: 85, want: "📊 同条件で上位15%の家賃（相場帯 8.1〜10.2万円）"},
+	}
+
+	for _, tt := range tests {
+		prop := PropertyWithScore{
+			Property: models.Property{Name: "分位マンション", Rent: 78000},
+			Score:    14000,
+			Label:    ScoreLabelBargain,
+			Band:     &RentBand{Lower: 81000, Median: 92000, Upper: 102000, PercentileRank: tt.rank},
+		}
+		if result := NewNotifier("").formatPropertyEntry(prop); !strings.Contains(result, tt.want) {
+			t.Errorf("formatPropertyEntry() should contain %q, got %q", tt.want, result)
+		}
+	}
+}
+
+func TestFormatPropertyEntryDuplicates(t *testing.T) {
+	prop := PropertyWithScore{
+		Property: models.Property{Name: "重複マンション", Rent: 75000},
+		Score:    15000,
+		Label:    ScoreLabelBargain,
+		Duplicates: &DuplicateInfo{
+			Listings:      3,
+			OthersMedian:  91000,
+			Reappearances: 2,
+			SuspectedBait: true,
+			Reappearing:   true,
+		},
+	}
+
+	result := NewNotifier("").formatPropertyEntry(prop)
+	for _, want := range []string{"⚠️ 釣り物件の疑い", "👥 同一物件 3件掲載（他掲載の中央値 9.1万円）", "🔁 再掲載 2回"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("formatPropertyEntry() should contain %q, got %q", want, result)
+		}
+	}
+}
+
+func TestFormatPropertyEntryMetrics(t *testing.T) {
+	prop := PropertyWithScore{
+		Property: models.Property{Name: "単価マンション", Rent: 80000, ManagementFee: 4000, Area: 20, Deposit: "1ヶ月", KeyMoney: "1ヶ月"},
+		Label:    ScoreLabelAnalyzing,
+	}
+
+	result := NewNotifier("").formatPropertyEntry(prop)
+	for _, want := range []string{"💰 8.4万円（管理費込・㎡単価 4,200円）", "🧾 実質 9.1万円/月（敷金・礼金を24ヶ月で按分）"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("formatPropertyEntry() should contain %q, got %q", want, result)
+		}
+	}
+
+	prop.Property.Deposit, prop.Property.KeyMoney = "-", "-"
+	if result := NewNotifier("").formatPropertyEntry(prop); strings.Contains(result, "🧾") {
+		t.Errorf("Monthly cost should be omitted without upfront costs, got %q", result)
+	}
+}
+
+func TestFormatBuildingInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		info BuildingInfo
+		want string
+	}{
+		{
+			name: "new vacancy",
+			info: BuildingInfo{Vacancies: 3, MinRentPerArea: 3100, MaxRentPerArea: 3600, NewVacancy: true},
+			want: "🏢 同じ建物で新たに空室（空室3件、㎡単価 3,100〜3,600円）\n",
+		},
+		{
+			name: "single vacancy",
+			info: BuildingInfo{Vacancies: 1, MinRentPerArea: 3100, MaxRentPerArea: 3100, NewVacancy: true},
+			want: "🏢 同じ建物で新たに空室（空室1件）\n",
+		},
+		{
+			name: "new building",
+			info: BuildingInfo{Vacancies: 2, MinRentPerArea: 3000, MaxRentPerArea: 3200},
+			want: "🏢 同じ建物の空室（空室2件、㎡単価 3,000〜3,200円）\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatBuildingInfo(&tt.info); got != tt.want {
+				t.Errorf("formatBuildingInfo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyWithOutput(t *testing.T) {
+	mock := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			t.Error("No request should be sent with an output")
+			return nil, nil
+		},
+	}
+
+	var buf bytes.Buffer
+	n := NewNotifier("https://discord.com/api/webhooks/test", WithHTTPClient(mock), WithOutput(&buf))
+	properties := []PropertyWithScore{{Property: models.Property{Name: "出力マンション"}, Label: ScoreLabelAnalyzing}}
+	if err := n.Notify(context.Background(), properties); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "🏠 **新着物件のお知らせ**") || !strings.Contains(buf.String(), "出力マンション") {
+		t.Errorf("Output = %q, want the notification message", buf.String())
+	}
+}
+
+func TestNotifyDryRun(t *testing.T) {
+	mock := &mockHTTPClient{
+		doFunc: func(req *http.Request) (*http.Response, error) {
+			t.Error("No request should be sent in dry run")
+			return nil, nil
+		},
+	}
+
+	n := NewNotifier("https://discord.com/api/webhooks/test", WithHTTPClient(mock), WithDryRun(true))
+	properties := []PropertyWithScore{{Property: models.Property{Name: "テストマンション"}, Label: ScoreLabelAnalyzing}}
+	if err := n.Notify(context.Background(), properties); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DriftKind identifies what a drift item compares between two model fits.
+type DriftKind string
+
+const (
+	// DriftCoefficient is the relative change of a regression coefficient.
+	DriftCoefficient DriftKind = "coefficient"
+
+	// DriftRMSE is the relative change of the validation RMSE.
+	DriftRMSE DriftKind = "rmse"
+
+	// DriftDistribution is the shift of a feature mean, in standard deviations
+	// of the previous training data.
+	DriftDistribution DriftKind = "distribution"
+)
+
+// DriftItem is one compared quantity between the previous and the new model.
+type DriftItem struct {
+	Kind     DriftKind
+	Feature  string // Empty for DriftRMSE
+	Previous float64
+	Current  float64
+	Shift    float64 // Relative change (coefficient, RMSE) or standardized shift (distribution)
+	Exceeded bool    // Whether Shift exceeds the configured threshold
+}
+
+// DriftReport compares a newly fitted rent model with the previously persisted one.
+type DriftReport struct {
+	PreviousTrainedAt time.Time
+	CurrentTrainedAt  time.Time
+	Items             []DriftItem
+}
+
+// Drifted reports whether any item exceeds its threshold.
+func (r DriftReport) Drifted() bool {
+	for _, item := range r.Items {
+		if item.Exceeded {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyDrift sends an operator alert listing the drift items that exceed their thresholds.
+// Nothing is sent if the report has not drifted.
+func (n *Notifier) NotifyDrift(ctx context.Context, report DriftReport) error {
+	if !report.Drifted() {
+		return nil
+	}
+
+	if err := n.send(ctx, formatDriftReport(report)); err != nil {
+		return fmt.Errorf("failed to send drift alert: %w", err)
+	}
+
+	return nil
+}
+
+// formatDriftReport creates the drift alert message.
+func formatDriftReport(report DriftReport) string {
+	var sb strings.Builder
+	sb.WriteString("⚠️ **モデルドリフト検知**\n")
+	sb.WriteString(fmt.Sprintf("前回学習: %s → 今回学習: %s\n",
+		report.PreviousTrainedAt.Format("2006-01-02 15:04"), report.CurrentTrainedAt.Format("2006-01-02 15:04")))
+	sb.WriteString("スクレイピング結果のパースが壊れていないか確認してください。\n\n")
+
+	for _, item := range report.Items {
+		if !item.Exceeded {
+			continue
+		}
+		line := formatDriftItem(item)
+		if sb.Len()+len(line) > MaxMessageLength {
+			break
+		}
+		sb.WriteString(line)
+	}
+
+	return sb.String()
+}
+
+// formatDriftItem formats one drift item as a line.
+func formatDriftItem(item DriftItem) string {
+	switch item.Kind {
+	case DriftRMSE:
+		return fmt.Sprintf("• 検証RMSE: %.0f → %.0f (%+.0f%%)\n", item.Previous, item.Current, item.Shift*100)
+	case DriftDistribution:
+		return fmt.Sprintf("• 分布 %s: 平均 %.1f → %.1f (%.1fσ)\n", item.Feature, item.Previous, item.Current, item.Shift)
+	default:
+		return fmt.Sprintf("• 係数 %s: %.0f → %.0f (%+.0f%%)\n", item.Feature, item.Previous, item.Current, item.Shift*100)
+	}
+}
@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatDriftReport(t *testing.T) {
+	report := DriftReport{
+		PreviousTrainedAt: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+		CurrentTrainedAt:  time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC),
+		Items: []DriftItem{
+			{Kind: DriftCoefficient, Feature: "area", Previous: 2500, Current: 250, Shift: -0.9, Exceeded: true},
+			{Kind: DriftCoefficient, Feature: "age", Previous: -800, Current: -820, Shift: 0.025},
+			{Kind: DriftDistribution, Feature: "area", Previous: 25, Current: 2.5, Shift: 4.5, Exceeded: true},
+			{Kind: DriftRMSE, Previous: 8000, Current: 16000, Shift: 1, Exceeded: true},
+		},
+	}
+
+	if !report.Drifted() {
+		t.Fatal("Drifted() = false, want true")
+	}
+
+	msg := formatDriftReport(report)
+	for _, want := range []string{
+		"モデルドリフト検知",
+		"2025-01-01 09:00",
+		"係数 area: 2500 → 250 (-90%)",
+		"分布 area: 平均 25.0 → 2.5 (4.5σ)",
+		"検証RMSE: 8000 → 16000 (+100%)",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Message should contain %q, got:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "age") {
+		t.Errorf("Message should not contain items within thresholds, got:\n%s", msg)
+	}
+}
+
+func TestDriftReportNotDrifted(t *testing.T) {
+	report := DriftReport{Items: []DriftItem{{Kind: DriftRMSE, Shift: 0.1}}}
+	if report.Drifted() {
+		t.Error("Drifted() = true, want false")
+	}
+}
@@ -0,0 +1,161 @@
+package notifier
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+// Filter is a set of rules a property must satisfy to be notified.
+// Zero values disable the corresponding rule.
+type Filter struct {
+	MaxTotalRent       float64  // Maximum total rent (yen)
+	MinArea            float64  // Minimum area (m²)
+	Layouts            []string // Allowed layouts (e.g., "1LDK")
+	MaxWalkMinutes     int      // Maximum walking minutes to the station
+	Stations           []string // Allowed stations (nearest station or any station in the access lines)
+	MaxAge             int      // Maximum building age (years)
+	MinFloor           int      // Minimum floor
+	ExcludeGroundFloor bool     // Exclude 1st floor units
+	OnlyBargains       bool     // Only properties labeled as bargains
+	IncludeKeywords    []string // The name must contain one of these
+	ExcludeKeywords    []string // The name must contain none of these
+	MaxMetrics         []MetricLimit
+}
+
+// MetricLimit is the maximum value of a property metric (see models.Metrics).
+type MetricLimit struct {
+	Metric models.Metric
+	Max    float64
+}
+
+// ParseMetricLimits resolves maximum values keyed by metric name
+// (e.g., "rent_per_area": 3500), sorted by name.
+func ParseMetricLimits(limits map[string]float64) ([]MetricLimit, error) {
+	parsed := make([]MetricLimit, 0, len(limits))
+	for name, max := range limits {
+		m, err := models.LookupMetric(name)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, MetricLimit{Metric: m, Max: max})
+	}
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].Metric.Name < parsed[j].Metric.Name })
+	return parsed, nil
+}
+
+// filterRule is a named rule of a Filter.
+type filterRule struct {
+	name string
+	keep func(p PropertyWithScore) bool
+}
+
+// FilterResult reports the outcome of applying a Filter.
+type FilterResult struct {
+	Total    int            // Properties before filtering
+	Kept     int            // Properties passing all rules
+	Excluded map[string]int // Excluded properties by the first rule they failed
+}
+
+// ExcludedCount returns the number of excluded properties.
+func (r FilterResult) ExcludedCount() int {
+	return r.Total - r.Kept
+}
+
+// String formats the excluded counts by rule, e.g. "max_total_rent=3, min_area=1".
+func (r FilterResult) String() string {
+	rules := make([]string, 0, len(r.Excluded))
+	for rule := range r.Excluded {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	parts := make([]string, len(rules))
+	for i, rule := range rules {
+		parts[i] = fmt.Sprintf("%s=%d", rule, r.Excluded[rule])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// rules returns the enabled rules of the filter in evaluation order.
+// Unknown values (0) pass numeric rules, so that a listing is not dropped
+// because of a parse gap: maximums pass them by comparison, minimums explicitly.
+func (f Filter) rules() []filterRule {
+	var rules []filterRule
+	add := func(enabled bool, name string, keep func(p models.Property) bool) {
+		if enabled {
+			rules = append(rules, filterRule{name: name, keep: func(s PropertyWithScore) bool { return keep(s.Property) }})
+		}
+	}
+
+	add(f.MaxTotalRent > 0, "max_total_rent", func(p models.Property) bool { return p.TotalRent() <= f.MaxTotalRent })
+	add(f.MinArea > 0, "min_area", func(p models.Property) bool { return p.Area == 0 || p.Area >= f.MinArea })
+	add(len(f.Layouts) > 0, "layouts", func(p models.Property) bool {
+		layout := models.NormalizeText(p.Layout)
+		return slices.ContainsFunc(f.Layouts, func(l string) bool { return models.NormalizeText(l) == layout })
+	})
+	add(f.MaxWalkMinutes > 0, "max_walk_minutes", func(p models.Property) bool { return p.WalkMinutes <= f.MaxWalkMinutes })
+	add(len(f.Stations) > 0, "stations", func(p models.Property) bool {
+		return slices.ContainsFunc(f.Stations, func(s string) bool {
+			return s == p.NearestStation || strings.Contains(p.Access, "/"+s+"駅")
+		})
+	})
+	add(f.MaxAge > 0, "max_age", func(p models.Property) bool { return p.Age <= f.MaxAge })
+	add(f.MinFloor > 0, "min_floor", func(p models.Property) bool { return p.Floor == 0 || p.Floor >= f.MinFloor })
+	for _, l := range f.MaxMetrics {
+		add(l.Max > 0, "max_"+l.Metric.Name, func(p models.Property) bool { return l.Metric.Value(p) <= l.Max })
+	}
+	add(f.ExcludeGroundFloor, "exclude_ground_floor", func(p models.Property) bool { return p.Floor != 1 })
+	add(len(f.IncludeKeywords) > 0, "include_keywords", func(p models.Property) bool {
+		return slices.ContainsFunc(f.IncludeKeywords, nameContains(p))
+	})
+	add(len(f.ExcludeKeywords) > 0, "exclude_keywords", func(p models.Property) bool {
+		return !slices.ContainsFunc(f.ExcludeKeywords, nameContains(p))
+	})
+
+	if f.OnlyBargains {
+		rules = append(rules, filterRule{name: "only_bargains", keep: func(s PropertyWithScore) bool { return s.Label == ScoreLabelBargain }})
+	}
+
+	return rules
+}
+
+// nameContains returns a function reporting whether the property name
+// contains a keyword, ignoring width, case and spaces.
+func nameContains(p models.Property) func(keyword string) bool {
+	name := models.NormalizeText(p.Name)
+	return func(keyword string) bool {
+		keyword = models.NormalizeText(keyword)
+		return keyword != "" && strings.Contains(name, keyword)
+	}
+}
+
+// Apply returns the properties satisfying all rules of the filter, in their
+// original order, and the number of properties excluded by each rule.
+// Each excluded property is counted once, for the first rule it failed.
+func (f Filter) Apply(properties []PropertyWithScore) ([]PropertyWithScore, FilterResult) {
+	rules := f.rules()
+	result := FilterResult{Total: len(properties), Excluded: make(map[string]int)}
+
+	kept := make([]PropertyWithScore, 0, len(properties))
+	for _, p := range properties {
+		failed := ""
+		for _, rule := range rules {
+			if !rule.keep(p) {
+				failed = rule.name
+				break
+			}
+		}
+		if failed != "" {
+			result.Excluded[failed]++
+			continue
+		}
+		kept = append(kept, p)
+	}
+
+	result.Kept = len(kept)
+	return kept, result
+}
@@ -0,0 +1,131 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestFilterApply(t *testing.T) {
+	base := models.Property{
+		Name:           "中野ハイツ",
+		Rent:           90000,
+		ManagementFee:  5000,
+		Area:           30,
+		Layout:         "1LDK",
+		WalkMinutes:    5,
+		NearestStation: "中野",
+		Access:         "JR中央線/中野駅 歩5分 / 東京メトロ東西線/落合駅 歩12分",
+		Age:            10,
+		Floor:          3,
+	}
+	with := func(modify func(p *models.Property)) models.Property {
+		p := base
+		modify(&p)
+		return p
+	}
+
+	filter := Filter{
+		MaxTotalRent:       100000,
+		MinArea:            25,
+		Layouts:            []string{"1ＬＤＫ", "2K"},
+		MaxWalkMinutes:     10,
+		Stations:           []string{"落合"},
+		MaxAge:             20,
+		MinFloor:           2,
+		ExcludeGroundFloor: true,
+		IncludeKeywords:    []string{"ハイツ", "レジデンス"},
+		ExcludeKeywords:    []string{"シェア"},
+		MaxMetrics: []MetricLimit{
+			{Metric: mustMetric(t, "rent_per_area"), Max: 3500},
+			{Metric: mustMetric(t, "monthly_cost"), Max: 110000},
+		},
+	}
+
+	tests := []struct {
+		name string
+		prop models.Property
+		rule string // Expected excluding rule, empty if kept
+	}{
+		{name: "matching", prop: base},
+		{name: "too expensive", prop: with(func(p *models.Property) { p.Rent = 150000 }), rule: "max_total_rent"},
+		{name: "too small", prop: with(func(p *models.Property) { p.Area = 15 }), rule: "min_area"},
+		{name: "unknown area", prop: with(func(p *models.Property) { p.Area = 0 })},
+		{name: "other layout", prop: with(func(p *models.Property) { p.Layout = "ワンルーム" }), rule: "layouts"},
+		{name: "too far", prop: with(func(p *models.Property) { p.WalkMinutes = 15 }), rule: "max_walk_minutes"},
+		{name: "other station", prop: with(func(p *models.Property) { p.NearestStation, p.Access = "高円寺", "JR中央線/高円寺駅 歩5分" }), rule: "stations"},
+		{name: "too old", prop: with(func(p *models.Property) { p.Age = 30 }), rule: "max_age"},
+		{name: "ground floor", prop: with(func(p *models.Property) { p.Floor = 1 }), rule: "min_floor"},
+		{name: "unknown floor", prop: with(func(p *models.Property) { p.Floor = 0 })},
+		{name: "high rent per area", prop: with(func(p *models.Property) { p.Rent, p.Area = 94000, 25.5 }), rule: "max_rent_per_area"},
+		{name: "high upfront cost", prop: with(func(p *models.Property) { p.Deposit, p.KeyMoney = "3ヶ月", "2ヶ月" }), rule: "max_monthly_cost"},
+		{name: "no keyword", prop: with(func(p *models.Property) { p.Name = "中野コーポ" }), rule: "include_keywords"},
+		{name: "excluded keyword", prop: with(func(p *models.Property) { p.Name = "シェアハイツ中野" }), rule: "exclude_keywords"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, result := filter.Apply([]PropertyWithScore{{Property: tt.prop}})
+			if tt.rule == "" {
+				if len(kept) != 1 || result.ExcludedCount() != 0 {
+					t.Errorf("Property should be kept, got %+v", result)
+				}
+				return
+			}
+			if len(kept) != 0 || result.Excluded[tt.rule] != 1 {
+				t.Errorf("Property should be excluded by %s, got %+v", tt.rule, result)
+			}
+		})
+	}
+}
+
+func mustMetric(t *testing.T, name string) models.Metric {
+	t.Helper()
+	m, err := models.LookupMetric(name)
+	if err != nil {
+		t.Fatalf("LookupMetric(%q) error = %v", name, err)
+	}
+	return m
+}
+
+func TestParseMetricLimits(t *testing.T) {
+	limits, err := ParseMetricLimits(map[string]float64{"rent_per_area": 3500, " monthly_cost": 110000})
+	if err != nil {
+		t.Fatalf("ParseMetricLimits() error = %v", err)
+	}
+	if len(limits) != 2 || limits[0].Metric.Name != "monthly_cost" || limits[1].Metric.Name != "rent_per_area" || limits[1].Max != 3500 {
+		t.Errorf("ParseMetricLimits() = %+v, want monthly_cost and rent_per_area", limits)
+	}
+
+	if _, err := ParseMetricLimits(map[string]float64{"balcony": 1}); err == nil {
+		t.Error("ParseMetricLimits() with an unknown metric should return an error")
+	}
+}
+
+func TestFilterApplyOnlyBargains(t *testing.T) {
+	properties := []PropertyWithScore{
+		{Property: models.Property{ID: "1", Floor: 1}, Label: ScoreLabelBargain},
+		{Property: models.Property{ID: "2", Floor: 2}, Label: ScoreLabelStandard},
+		{Property: models.Property{ID: "3", Floor: 3}, Label: ScoreLabelBargain},
+		{Property: models.Property{ID: "4", Floor: 4}, Label: ScoreLabelAnalyzing},
+	}
+
+	kept, result := Filter{OnlyBargains: true, ExcludeGroundFloor: true}.Apply(properties)
+	if len(kept) != 1 || kept[0].Property.ID != "3" {
+		t.Fatalf("Apply() kept %+v, want only property 3", kept)
+	}
+	if result.Total != 4 || result.Kept != 1 || result.ExcludedCount() != 3 {
+		t.Errorf("Result = %+v, want 4 total, 1 kept", result)
+	}
+	if got, want := result.String(), "exclude_ground_floor=1, only_bargains=2"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestFilterApplyEmpty(t *testing.T) {
+	properties := []PropertyWithScore{{Property: models.Property{ID: "1"}}, {Property: models.Property{ID: "2"}}}
+	kept, result := Filter{}.Apply(properties)
+	if len(kept) != 2 || result.ExcludedCount() != 0 {
+		t.Errorf("Empty filter should keep all properties, got %d kept (%+v)", len(kept), result)
+	}
+}
@@ -0,0 +1,150 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortOrder selects the order of properties in notifications.
+type SortOrder string
+
+const (
+	// SortScore orders by bargain score, highest first.
+	SortScore SortOrder = "score"
+
+	// SortPricePerArea orders by total rent per m², cheapest first.
+	SortPricePerArea SortOrder = "price_per_area"
+
+	// SortWalk orders by walking minutes to the station, nearest first.
+	SortWalk SortOrder = "walk"
+
+	// SortNewest orders by first seen time, newest first.
+	SortNewest SortOrder = "newest"
+
+	// SortNone keeps the scrape order.
+	SortNone SortOrder = "none"
+)
+
+// ParseSortOrder parses a sort order name.
+func ParseSortOrder(s string) (SortOrder, error) {
+	switch order := SortOrder(strings.TrimSpace(s)); order {
+	case SortScore, SortPricePerArea, SortWalk, SortNewest, SortNone:
+		return order, nil
+	default:
+		return "", fmt.Errorf("unknown sort order: %q", s)
+	}
+}
+
+// WithSortOrder sets the order of properties in notifications.
+func WithSortOrder(order SortOrder) Option {
+	return func(n *Notifier) {
+		n.sortOrder = order
+	}
+}
+
+// WithMaxProperties sets the number of properties shown in a notification.
+// The rest are summarized by label.
+func WithMaxProperties(max int) Option {
+	return func(n *Notifier) {
+		n.maxProperties = max
+	}
+}
+
+// labelPriority returns the selection priority of a property (lower first):
+// bargains, standard, expensive, then properties without a score.
+// Suspected bait listings rank with expensive ones.
+func labelPriority(p PropertyWithScore) int {
+	if p.Duplicates != nil && p.Duplicates.SuspectedBait {
+		return 2
+	}
+	switch p.Label {
+	case ScoreLabelBargain:
+		return 0
+	case ScoreLabelStandard:
+		return 1
+	case ScoreLabelExpensive:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// less reports whether a comes before b in the sort order, among properties
+// of the same priority.
+func (o SortOrder) less(a, b PropertyWithScore) bool {
+	switch o {
+	case SortScore:
+		return a.Score > b.Score
+	case SortPricePerArea:
+		// Unknown areas last
+		pa, pb := a.Property.TotalRentPerArea(), b.Property.TotalRentPerArea()
+		if (pa > 0) != (pb > 0) {
+			return pa > 0
+		}
+		return pa < pb
+	case SortWalk:
+		return a.Property.WalkMinutes < b.Property.WalkMinutes
+	case SortNewest:
+		return a.Property.FirstSeen.After(b.Property.FirstSeen)
+	default:
+		return false
+	}
+}
+
+// rank returns the properties in notification order: by label priority, then
+// by the sort order. SortNone keeps the original order. The input is not modified.
+func (o SortOrder) rank(properties []PropertyWithScore) []PropertyWithScore {
+	ranked := append([]PropertyWithScore(nil), properties...)
+	if o == SortNone {
+		return ranked
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		pi, pj := labelPriority(ranked[i]), labelPriority(ranked[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return o.less(ranked[i], ranked[j])
+	})
+	return ranked
+}
+
+// summaryBait is the summary bucket of suspected bait listings, which are
+// counted apart from their label as in labelPriority.
+const summaryBait = "釣り物件の疑い"
+
+// summaryBuckets is the order of buckets in the summary of omitted properties.
+var summaryBuckets = []string{
+	string(ScoreLabelBargain), string(ScoreLabelStandard), string(ScoreLabelExpensive), summaryBait, string(ScoreLabelAnalyzing),
+}
+
+// summaryBucket returns the summary bucket of a property: its label, or
+// summaryBait for suspected bait listings.
+func summaryBucket(p PropertyWithScore) string {
+	if p.Duplicates != nil && p.Duplicates.SuspectedBait {
+		return summaryBait
+	}
+	return string(p.Label)
+}
+
+// formatRemaining summarizes properties omitted from a notification by label,
+// e.g. "📋 他5件の新着あり（お買い得1件・標準4件）".
+func formatRemaining(properties []PropertyWithScore) string {
+	counts := make(map[string]int)
+	for _, p := range properties {
+		counts[summaryBucket(p)]++
+	}
+
+	var parts []string
+	for _, bucket := range summaryBuckets {
+		if counts[bucket] > 0 {
+			parts = append(parts, fmt.Sprintf("%s%d件", bucket, counts[bucket]))
+		}
+	}
+
+	summary := fmt.Sprintf("\n📋 他%d件の新着あり", len(properties))
+	if len(parts) > 0 {
+		summary += "（" + strings.Join(parts, "・") + "）"
+	}
+	return summary + "\n"
+}
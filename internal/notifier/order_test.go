@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestParseSortOrder(t *testing.T) {
+	for _, s := range []string{"score", "price_per_area", "walk", "newest", "none"} {
+		if got, err := ParseSortOrder(s); err != nil || string(got) != s {
+			t.Errorf("ParseSortOrder(%q) = (%q, %v)", s, got, err)
+		}
+	}
+	if _, err := ParseSortOrder("rent"); err == nil {
+		t.Error("ParseSortOrder() should fail for an unknown order")
+	}
+}
+
+func TestSortOrderRank(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	properties := []PropertyWithScore{
+		{Property: models.Property{ID: "standard", Rent: 90000, Area: 30, WalkMinutes: 3, FirstSeen: now}, Score: 2000, Label: ScoreLabelStandard},
+		{Property: models.Property{ID: "analyzing", Rent: 50000, Area: 25, WalkMinutes: 1, FirstSeen: now.Add(time.Hour)}, Label: ScoreLabelAnalyzing},
+		{Property: models.Property{ID: "small-bargain", Rent: 80000, Area: 20, WalkMinutes: 6, FirstSeen: now.Add(-time.Hour)}, Score: 12000, Label: ScoreLabelBargain},
+		{Property: models.Property{ID: "big-bargain", Rent: 80000, Area: 40, WalkMinutes: 8, FirstSeen: now.Add(-2 * time.Hour)}, Score: 30000, Label: ScoreLabelBargain},
+		{Property: models.Property{ID: "bait", Rent: 60000, Area: 30, WalkMinutes: 2}, Score: 40000, Label: ScoreLabelBargain, Duplicates: &DuplicateInfo{SuspectedBait: true}},
+		{Property: models.Property{ID: "expensive", Rent: 120000, Area: 30, WalkMinutes: 5}, Score: -20000, Label: ScoreLabelExpensive},
+	}
+
+	tests := []struct {
+		order SortOrder
+		want  []string
+	}{
+		{SortScore, []string{"big-bargain", "small-bargain", "standard", "bait", "expensive", "analyzing"}},
+		{SortPricePerArea, []string{"big-bargain", "small-bargain", "standard", "bait", "expensive", "analyzing"}},
+		{SortWalk, []string{"small-bargain", "big-bargain", "standard", "bait", "expensive", "analyzing"}},
+		{SortNewest, []string{"small-bargain", "big-bargain", "standard", "bait", "expensive", "analyzing"}},
+		{SortNone, []string{"standard", "analyzing", "small-bargain", "big-bargain", "bait", "expensive"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			ranked := tt.order.rank(properties)
+			var got []string
+			for _, p := range ranked {
+				got = append(got, p.Property.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("rank() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if properties[0].Property.ID != "standard" {
+		t.Error("rank() should not modify the input")
+	}
+}
+
+func TestFormatMessagesTopN(t *testing.T) {
+	var properties []PropertyWithScore
+	for i, label := range []ScoreLabel{ScoreLabelStandard, ScoreLabelStandard, ScoreLabelBargain, ScoreLabelExpensive, ScoreLabelAnalyzing} {
+		properties = append(properties, PropertyWithScore{
+			Property: models.Property{Name: string(label) + string(rune('A'+i))},
+			Score:    float64(-i),
+			Label:    label,
+		})
+	}
+
+	messages := NewNotifier("", WithMaxProperties(2)).formatMessages(properties)
+	message := strings.Join(messages, "")
+
+	if !strings.Contains(message, "お買い得C") || !strings.Contains(message, "標準A") {
+		t.Errorf("Top properties should be shown, got %q", message)
+	}
+	if strings.Contains(message, "標準B") {
+		t.Errorf("Lower ranked properties should be summarized, got %q", message)
+	}
+	if want := "📋 他3件の新着あり（標準1件・割高1件・分析中1件）"; !strings.Contains(message, want) {
+		t.Errorf("formatMessages() should contain %q, got %q", want, message)
+	}
+}
+
+func TestFormatRemainingBait(t *testing.T) {
+	properties := []PropertyWithScore{
+		{Label: ScoreLabelBargain},
+		{Label: ScoreLabelBargain, Duplicates: &DuplicateInfo{SuspectedBait: true}},
+		{Label: ScoreLabelExpensive},
+	}
+
+	want := "\n📋 他3件の新着あり（お買い得1件・割高1件・釣り物件の疑い1件）\n"
+	if got := formatRemaining(properties); got != want {
+		t.Errorf("formatRemaining() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+const (
+	// MaxReportSeries is the maximum number of stations (and wards) in a market report.
+	MaxReportSeries = 10
+
+	// SparklineLength is the number of most recent periods drawn in a sparkline.
+	SparklineLength = 12
+)
+
+// sparkTicks are the characters used to draw sparklines, lowest first.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// NotifyMarketReport sends a market report with the index change and
+// a sparkline per station and ward.
+func (n *Notifier) NotifyMarketReport(ctx context.Context, index models.MarketIndex) error {
+	if len(index.Stations) == 0 && len(index.Wards) == 0 {
+		return nil
+	}
+
+	for _, msg := range n.formatMarketReport(index) {
+		if err := n.send(ctx, msg); err != nil {
+			return fmt.Errorf("failed to send market report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// formatMarketReport creates market report messages.
+// Messages are split if they exceed MaxMessageLength.
+func (n *Notifier) formatMarketReport(index models.MarketIndex) []string {
+	periodLabel := "月次"
+	if index.Period == models.IndexPeriodWeek {
+		periodLabel = "週次"
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("📈 **相場レポート（%s）**\n", periodLabel))
+	for _, section := range []struct {
+		title  string
+		series []models.IndexSeries
+	}{
+		{title: "駅別", series: index.Stations},
+		{title: "エリア別", series: index.Wards},
+	} {
+		if len(section.series) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("\n**%s**\n", section.title))
+		for i, s := range section.series {
+			if i == MaxReportSeries {
+				break
+			}
+			lines = append(lines, formatIndexSeries(s, index.Period))
+		}
+	}
+
+	var messages []string
+	var currentMsg strings.Builder
+	for _, line := range lines {
+		if currentMsg.Len()+len(line) > MaxMessageLength {
+			messages = append(messages, currentMsg.String())
+			currentMsg.Reset()
+			currentMsg.WriteString("📈 **相場レポート（続き）**\n")
+		}
+		currentMsg.WriteString(line)
+	}
+	if currentMsg.Len() > 0 {
+		messages = append(messages, currentMsg.String())
+	}
+
+	return messages
+}
+
+// formatIndexSeries formats one series as
+// "中野 9.2万円 (+1.8%) `▁▂▃▅▆█`".
+func formatIndexSeries(s models.IndexSeries, period models.IndexPeriod) string {
+	last := s.Points[len(s.Points)-1]
+
+	change := "(-)"
+	if c, ok := s.Change(period); ok {
+		change = fmt.Sprintf("(%+.1f%%)", c*100)
+	}
+
+	values := make([]float64, 0, SparklineLength)
+	from := max(0, len(s.Points)-SparklineLength)
+	for _, p := range s.Points[from:] {
+		values = append(values, p.Value)
+	}
+
+	return fmt.Sprintf("%s %.1f万円 %s `%s`\n", s.Name, last.Value/10000, change, sparkline(values))
+}
+
+// sparkline draws values as a text chart scaled between their minimum and maximum.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		level := len(sparkTicks) / 2
+		if hi > lo {
+			level = int(math.Round((v - lo) / (hi - lo) * float64(len(sparkTicks)-1)))
+		}
+		sb.WriteRune(sparkTicks[level])
+	}
+	return sb.String()
+}
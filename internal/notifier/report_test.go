@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{1, 2, 3, 4, 5, 6, 7, 8}); got != "▁▂▃▄▅▆▇█" {
+		t.Errorf("sparkline() = %q, want %q", got, "▁▂▃▄▅▆▇█")
+	}
+	if got := sparkline([]float64{5, 5}); got != "▅▅" {
+		t.Errorf("sparkline(flat) = %q, want %q", got, "▅▅")
+	}
+	if got := sparkline(nil); got != "" {
+		t.Errorf("sparkline(nil) = %q, want empty", got)
+	}
+}
+
+func TestFormatMarketReport(t *testing.T) {
+	month := func(m time.Month) time.Time { return time.Date(2025, m, 1, 0, 0, 0, 0, time.UTC) }
+	notifier := NewNotifier("https://discord.com/api/webhooks/test")
+	index := models.MarketIndex{
+		Period: models.IndexPeriodMonth,
+		Stations: []models.IndexSeries{
+			{Name: "中野", Points: []models.IndexPoint{{Start: month(1), Value: 90000}, {Start: month(2), Value: 91000}, {Start: month(3), Value: 92000}}},
+			{Name: "高円寺", Points: []models.IndexPoint{{Start: month(1), Value: 90000}, {Start: month(3), Value: 95000}}},
+		},
+		Wards: []models.IndexSeries{
+			{Name: "中野区", Points: []models.IndexPoint{{Value: 90000}}},
+		},
+	}
+
+	messages := notifier.formatMarketReport(index)
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(messages))
+	}
+
+	for _, want := range []string{"相場レポート（月次）", "中野 9.2万円 (+1.1%) `▁▅█`", "高円寺 9.5万円 (-)", "中野区 9.0万円 (-)"} {
+		if !strings.Contains(messages[0], want) {
+			t.Errorf("Report should contain %q, got %q", want, messages[0])
+		}
+	}
+}
+
+func TestNotifyMarketReportEmpty(t *testing.T) {
+	mock := &mockHTTPClient{}
+	notifier := NewNotifier("https://discord.com/api/webhooks/test", WithHTTPClient(mock))
+
+	if err := notifier.NotifyMarketReport(context.Background(), models.MarketIndex{}); err != nil {
+		t.Errorf("NotifyMarketReport() error = %v", err)
+	}
+}
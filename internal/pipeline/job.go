@@ -0,0 +1,155 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/logging"
+	"github.com/alp/suumo-hunter/internal/metrics"
+	"github.com/alp/suumo-hunter/internal/notifier"
+)
+
+// Job selects what a run does.
+type Job string
+
+const (
+	// JobScrape scrapes SUUMO, updates the stored data and notifies new properties.
+	JobScrape Job = "scrape"
+
+	// JobDigest sends the accumulated digest now, regardless of DIGEST_TIMES.
+	JobDigest Job = "digest"
+
+	// JobReport sends the market report of the stored data now.
+	JobReport Job = "report"
+
+	// JobBackfill scrapes SUUMO and updates the stored data and the model
+	// without sending notifications, e.g. to build history for a new profile.
+	JobBackfill Job = "backfill"
+)
+
+// ParseJob parses a job name. An empty name is JobScrape.
+func ParseJob(s string) (Job, error) {
+	switch job := Job(s); job {
+	case "":
+		return JobScrape, nil
+	case JobScrape, JobDigest, JobReport, JobBackfill:
+		return job, nil
+	default:
+		return "", fmt.Errorf("unknown job: %q", s)
+	}
+}
+
+// RunJob runs the job once and returns its summary, which is also persisted
+// as the run record, whether or not the job failed. The returned error is the
+// job's: failing to persist the record is only logged. In a dry run, the data
+// that would be uploaded and the messages that would be sent are logged instead.
+func (p *Pipeline) RunJob(ctx context.Context, job Job) (Summary, error) {
+	start := time.Now()
+	summary := Summary{
+		RunID:     newRunID(start),
+		Job:       job,
+		Profile:   p.cfg.Profile,
+		DryRun:    p.cfg.DryRun,
+		StartedAt: start,
+		Errors:    []StageError{},
+	}
+	ctx = logging.WithAttrs(ctx, "run_id", summary.RunID, "profile", p.cfg.Profile, "job", string(job))
+	p.logger.InfoContext(ctx, "Starting run", "dry_run", p.cfg.DryRun)
+	if p.cfg.DryRun {
+		p.logger.InfoContext(ctx, "Dry run: nothing will be uploaded or sent")
+	}
+
+	var err error
+	switch job {
+	case JobScrape:
+		err = p.scrape(ctx, &summary, false)
+	case JobBackfill:
+		err = p.scrape(ctx, &summary, true)
+	case JobDigest:
+		err = p.sendDigest(ctx, &summary)
+	case JobReport:
+		err = p.sendReport(ctx, &summary)
+	default:
+		err = fmt.Errorf("unknown job: %q", job)
+	}
+	summary.FinishedAt = time.Now()
+	p.record(summary, err)
+
+	// Persist the record with its own context, so that failed or cancelled
+	// runs are recorded too. A failed upload doesn't fail the run: retrying
+	// it would scrape and notify again.
+	key := summary.key(p.cfg.RunsPrefix)
+	if uploadErr := p.store.UploadJSON(context.WithoutCancel(ctx), key, summary); uploadErr != nil {
+		p.logger.ErrorContext(ctx, "Failed to persist run record", "key", key, "error", uploadErr)
+	}
+
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Run failed", "error", err, "duration", summary.FinishedAt.Sub(start))
+	} else {
+		p.logger.InfoContext(ctx, "Run completed", "duration", summary.FinishedAt.Sub(start))
+	}
+
+	return summary, err
+}
+
+// record records the metrics of a finished run.
+func (p *Pipeline) record(summary Summary, err error) {
+	job := metrics.L("job", string(summary.Job))
+	p.metrics.Count("runs_total", 1, job, metrics.Result(err))
+	p.metrics.Timing("run_duration_seconds", summary.FinishedAt.Sub(summary.StartedAt), job)
+	p.metrics.Count("run_stage_errors_total", float64(len(summary.Errors)), job)
+	p.metrics.Count("listings_new_total", float64(summary.New))
+	p.metrics.Count("listings_price_changes_total", float64(summary.PriceChanges))
+	p.metrics.Count("listings_delisted_total", float64(summary.Delisted))
+	p.metrics.Count("properties_notified_total", float64(summary.Notified))
+}
+
+// sendDigest sends the persisted digest and starts a new accumulation.
+// Nothing is sent if the digest is empty.
+func (p *Pipeline) sendDigest(ctx context.Context, summary *Summary) error {
+	if !p.schedule.Enabled() {
+		return summary.fail(StageDigest, errors.New("digest job requires DIGEST_MODE daily or weekly"))
+	}
+
+	var digest notifier.Digest
+	if _, err := p.store.DownloadJSON(ctx, p.cfg.DigestKey, &digest); err != nil {
+		return summary.fail(StageDownload, fmt.Errorf("failed to download digest: %w", err))
+	}
+	if digest.Empty() {
+		p.logger.InfoContext(ctx, "Digest is empty, skipping notification")
+		return nil
+	}
+
+	now := time.Now()
+	p.logger.InfoContext(ctx, "Sending digest",
+		"listings", len(digest.Listings), "price_drops", len(digest.PriceDrops), "delistings", len(digest.Delistings))
+	if err := p.notifier.NotifyDigest(ctx, digest, now.In(p.schedule.Location)); err != nil {
+		return summary.fail(StageDigest, fmt.Errorf("failed to send digest: %w", err))
+	}
+	digest.Reset(now)
+	summary.DigestSent = true
+
+	if err := p.store.UploadJSON(ctx, p.cfg.DigestKey, digest); err != nil {
+		return summary.fail(StageUpload, fmt.Errorf("failed to upload digest: %w", err))
+	}
+	summary.Uploads = append(summary.Uploads, p.cfg.DigestKey)
+
+	return nil
+}
+
+// sendReport recomputes the market index from the stored data and sends the
+// market report.
+func (p *Pipeline) sendReport(ctx context.Context, summary *Summary) error {
+	properties, err := p.store.Download(ctx)
+	if err != nil {
+		return summary.fail(StageDownload, fmt.Errorf("failed to download previous data: %w", err))
+	}
+	p.logger.InfoContext(ctx, "Downloaded stored properties", "properties", len(properties))
+
+	if err := p.updateMarketIndex(ctx, summary, properties, reportAlways); err != nil {
+		return summary.fail(StageMarketIndex, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseJob(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Job
+		wantErr bool
+	}{
+		{"", JobScrape, false},
+		{"scrape", JobScrape, false},
+		{"digest", JobDigest, false},
+		{"report", JobReport, false},
+		{"backfill", JobBackfill, false},
+		{"Scrape", "", true},
+		{"unknown", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseJob(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseJob(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseJob(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunJobBackfill(t *testing.T) {
+	p, store, out := setup(t, func() string { return suumoPage([]listing{{id: 1, rent: "8万円"}}) })
+	ctx := context.Background()
+
+	summary, err := p.RunJob(ctx, JobBackfill)
+	if err != nil {
+		t.Fatalf("RunJob() error = %v", err)
+	}
+	if summary.Job != JobBackfill || summary.New != 1 || summary.Notified != 0 {
+		t.Errorf("Summary = %+v, want a backfill with 1 new and nothing notified", summary)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Backfill should not notify, got %q", out.String())
+	}
+	if stored, err := store.Download(ctx); err != nil || len(stored) != 1 {
+		t.Errorf("Stored properties = (%d, %v), want 1", len(stored), err)
+	}
+}
+
+func TestRunNotifyAll(t *testing.T) {
+	p, _, out := setup(t, func() string { return suumoPage([]listing{{id: 1, rent: "8万円"}}) }, WithNotifyAll(true))
+	ctx := context.Background()
+
+	for run := 1; run <= 2; run++ {
+		out.Reset()
+		summary, err := p.Run(ctx)
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		// The listing is only new in the first run, but notified in both
+		if summary.Notified != 1 || !strings.Contains(out.String(), "テストマンション") {
+			t.Errorf("Run %d: summary = %+v, output = %q, want the listing notified", run, summary, out.String())
+		}
+	}
+}
+
+func TestRunJobDigestDisabled(t *testing.T) {
+	p, _, _ := setup(t, func() string { return suumoPage(nil) })
+	if _, err := p.RunJob(context.Background(), JobDigest); err == nil {
+		t.Error("RunJob(JobDigest) with DIGEST_MODE off should return an error")
+	}
+}
+
+func TestRunJobDigest(t *testing.T) {
+	t.Setenv("DIGEST_MODE", "daily")
+	t.Setenv("DIGEST_IMMEDIATE_THRESHOLD", "0")
+	p, _, out := setup(t, func() string { return suumoPage([]listing{{id: 1, rent: "8万円"}}) })
+	ctx := context.Background()
+
+	// The scrape job accumulates the new listing into the digest
+	if _, err := p.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("Scrape in digest mode should not notify, got %q", out.String())
+	}
+
+	summary, err := p.RunJob(ctx, JobDigest)
+	if err != nil {
+		t.Fatalf("RunJob() error = %v", err)
+	}
+	if !summary.DigestSent || !strings.Contains(out.String(), "ダイジェスト") {
+		t.Errorf("Summary = %+v, output = %q, want the digest sent", summary, out.String())
+	}
+
+	// The digest was reset, so nothing is sent again
+	out.Reset()
+	if summary, err := p.RunJob(ctx, JobDigest); err != nil || summary.DigestSent || out.Len() != 0 {
+		t.Errorf("Second digest = (%+v, %v), output = %q, want nothing sent", summary, err, out.String())
+	}
+}
@@ -0,0 +1,533 @@
+// Package pipeline runs SUUMO Hunter end to end: scrape SUUMO, update the
+// stored data, score new properties and send notifications.
+// It is shared by the Lambda function and the command line tool.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/analyzer"
+	"github.com/alp/suumo-hunter/internal/config"
+	"github.com/alp/suumo-hunter/internal/metrics"
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+	"github.com/alp/suumo-hunter/internal/scraper"
+	"github.com/alp/suumo-hunter/internal/storage"
+)
+
+// Pipeline holds the components configured for one profile.
+type Pipeline struct {
+	cfg      *config.Config
+	store    *storage.Storage
+	scraper  *scraper.Scraper
+	notifier *notifier.Notifier
+	analyzer *analyzer.Analyzer
+	schedule notifier.DigestSchedule
+	filter   notifier.Filter
+
+	notifyAll    bool
+	logger       *slog.Logger
+	metrics      metrics.Recorder
+	scraperOpts  []scraper.Option
+	notifierOpts []notifier.Option
+}
+
+// Option is a function that configures a Pipeline.
+type Option func(*Pipeline)
+
+// WithScraperOptions adds options to the configured scraper.
+func WithScraperOptions(opts ...scraper.Option) Option {
+	return func(p *Pipeline) {
+		p.scraperOpts = append(p.scraperOpts, opts...)
+	}
+}
+
+// WithNotifierOptions adds options to the configured notifiers
+// (e.g., notifier.WithOutput to preview messages).
+func WithNotifierOptions(opts ...notifier.Option) Option {
+	return func(p *Pipeline) {
+		p.notifierOpts = append(p.notifierOpts, opts...)
+	}
+}
+
+// WithNotifyAll scores and immediately notifies every listed property,
+// not only new ones, bypassing the digest.
+func WithNotifyAll(enabled bool) Option {
+	return func(p *Pipeline) {
+		p.notifyAll = enabled
+	}
+}
+
+// WithLogger sets the logger of the pipeline and its scraper, analyzer and
+// notifiers. Defaults to slog.Default(). Records logged during a run carry
+// the run ID, profile and job attributes if the logger was created by
+// logging.New.
+func WithLogger(l *slog.Logger) Option {
+	return func(p *Pipeline) {
+		p.logger = l
+	}
+}
+
+// WithMetrics sets the recorder of run metrics, also used by the scraper,
+// analyzer and notifiers.
+func WithMetrics(m metrics.Recorder) Option {
+	return func(p *Pipeline) {
+		p.metrics = m
+	}
+}
+
+// New creates a Pipeline for the configuration, storing data in store.
+// Returns an error if the configuration is invalid, or if cfg.DryRun is set
+// but store was not created with storage.WithDryRun.
+func New(cfg *config.Config, store *storage.Storage, opts ...Option) (*Pipeline, error) {
+	if cfg.DryRun && !store.DryRun() {
+		return nil, errors.New("dry run requires a storage created with storage.WithDryRun")
+	}
+
+	p := &Pipeline{cfg: cfg, store: store, logger: slog.Default(), metrics: metrics.Nop{}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	// Component loggers and metrics first, so that explicit options take precedence
+	p.scraperOpts = append([]scraper.Option{scraper.WithLogger(p.logger), scraper.WithMetrics(p.metrics)}, p.scraperOpts...)
+	p.notifierOpts = append([]notifier.Option{notifier.WithLogger(p.logger), notifier.WithMetrics(p.metrics)}, p.notifierOpts...)
+
+	var err error
+	if p.analyzer, err = NewAnalyzer(cfg, analyzer.WithLogger(p.logger), analyzer.WithMetrics(p.metrics)); err != nil {
+		return nil, err
+	}
+	if p.notifier, err = NewNotifier(cfg, p.notifierOpts...); err != nil {
+		return nil, err
+	}
+	if p.filter, err = NotificationFilter(cfg); err != nil {
+		return nil, err
+	}
+	p.schedule, err = notifier.ParseDigestSchedule(cfg.DigestMode, cfg.DigestTimes, cfg.DigestWeekday, cfg.DigestTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest configuration: %w", err)
+	}
+	p.scraper = scraper.NewScraper(cfg.SuumoSearchURL, append([]scraper.Option{scraper.WithMaxPages(cfg.MaxPage)}, p.scraperOpts...)...)
+
+	return p, nil
+}
+
+// NewAnalyzer creates the Analyzer configured by cfg, with additional options.
+func NewAnalyzer(cfg *config.Config, opts ...analyzer.Option) (*analyzer.Analyzer, error) {
+	transforms, err := analyzer.ParseFeatureTransforms(cfg.FeatureTransforms)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FEATURE_TRANSFORMS: %w", err)
+	}
+
+	return analyzer.NewAnalyzer(append([]analyzer.Option{
+		analyzer.WithComparablesK(cfg.ComparablesK),
+		analyzer.WithNeighborStations(cfg.StationNeighbors()),
+		analyzer.WithTimeDecay(cfg.RegressionHalfLife),
+		analyzer.WithLookback(cfg.RegressionLookback),
+		analyzer.WithSeasonality(cfg.RegressionSeasonality),
+		analyzer.WithLayoutFeature(cfg.RegressionLayout),
+		analyzer.WithModelType(analyzer.ModelType(cfg.RentModel)),
+		analyzer.WithForestTrees(cfg.ForestTrees),
+		analyzer.WithQuantiles(cfg.QuantileRegression),
+		analyzer.WithFeatureTransforms(transforms),
+		analyzer.WithDuplicateAreaTolerance(cfg.DuplicateAreaTolerance),
+		analyzer.WithReappearanceGap(cfg.ReappearanceGap),
+	}, opts...)...), nil
+}
+
+// NewNotifier creates the Notifier for new properties configured by cfg,
+// with additional options.
+func NewNotifier(cfg *config.Config, opts ...notifier.Option) (*notifier.Notifier, error) {
+	sortOrder, err := notifier.ParseSortOrder(cfg.NotifySort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFY_SORT: %w", err)
+	}
+
+	return notifier.NewNotifier(cfg.DiscordWebhookURL, append([]notifier.Option{
+		notifier.WithExplanations(cfg.NotifyExplanations),
+		notifier.WithSortOrder(sortOrder),
+		notifier.WithMaxProperties(cfg.NotifyMaxProperties),
+		notifier.WithDryRun(cfg.DryRun),
+	}, opts...)...), nil
+}
+
+// NotificationFilter returns the notification filter configured by the FILTER_* variables.
+func NotificationFilter(cfg *config.Config) (notifier.Filter, error) {
+	limits, err := notifier.ParseMetricLimits(cfg.FilterMaxMetrics)
+	if err != nil {
+		return notifier.Filter{}, fmt.Errorf("invalid FILTER_MAX_METRICS: %w", err)
+	}
+
+	return notifier.Filter{
+		MaxTotalRent:       cfg.FilterMaxTotalRent,
+		MinArea:            cfg.FilterMinArea,
+		Layouts:            cfg.FilterLayouts,
+		MaxWalkMinutes:     cfg.FilterMaxWalkMinutes,
+		Stations:           cfg.FilterStations,
+		MaxAge:             cfg.FilterMaxAge,
+		MinFloor:           cfg.FilterMinFloor,
+		ExcludeGroundFloor: cfg.FilterExcludeGroundFloor,
+		OnlyBargains:       cfg.FilterOnlyBargains,
+		IncludeKeywords:    cfg.FilterIncludeKeywords,
+		ExcludeKeywords:    cfg.FilterExcludeKeywords,
+		MaxMetrics:         limits,
+	}, nil
+}
+
+// Run runs the full pipeline once (JobScrape) and returns its summary.
+func (p *Pipeline) Run(ctx context.Context) (Summary, error) {
+	return p.RunJob(ctx, JobScrape)
+}
+
+// scrape scrapes SUUMO, updates the stored data, and scores and notifies
+// new properties. A backfill only updates the stored data and the model
+// without sending notifications.
+func (p *Pipeline) scrape(ctx context.Context, summary *Summary, backfill bool) error {
+	// Step 1: Download previous data from S3
+	p.logger.InfoContext(ctx, "Downloading previous data", "key", p.store.BucketKey())
+	previousProperties, err := p.store.Download(ctx)
+	if err != nil {
+		return summary.fail(StageDownload, fmt.Errorf("failed to download previous data: %w", err))
+	}
+	p.logger.InfoContext(ctx, "Downloaded previous data", "properties", len(previousProperties))
+
+	// Step 2: Scrape SUUMO
+	p.logger.InfoContext(ctx, "Scraping SUUMO", "max_pages", p.cfg.MaxPage)
+	currentProperties, stats, err := p.scraper.ScrapeWithStats(ctx)
+	summary.PagesFetched, summary.Retries, summary.ParseFailures = stats.Pages, stats.Retries, stats.ParseFailures()
+	summary.Truncated = stats.Truncated
+	if err != nil {
+		return summary.fail(StageScrape, fmt.Errorf("failed to scrape SUUMO: %w", err))
+	}
+	p.logger.InfoContext(ctx, "Scraped SUUMO", "properties", len(currentProperties),
+		"pages", stats.Pages, "retries", stats.Retries, "parse_failures", stats.ParseFailures())
+	models.MarkSeen(currentProperties, previousProperties, time.Now())
+	summary.Scraped = len(currentProperties)
+
+	// Fetch facilities and structure from the detail pages of properties
+	// without stored details. Failed pages are retried in a later run.
+	models.CarryOverDetails(currentProperties, previousProperties)
+	if p.cfg.MaxDetailPages > 0 {
+		fetched, failed, err := p.scraper.ScrapeDetails(ctx, currentProperties, p.cfg.MaxDetailPages)
+		summary.DetailPages = fetched
+		if err != nil {
+			return summary.fail(StageScrape, fmt.Errorf("failed to scrape detail pages: %w", err))
+		}
+		p.logger.InfoContext(ctx, "Scraped detail pages", "fetched", fetched, "failed", failed)
+	}
+
+	// Step 3: Find new, repriced and delisted properties
+	newProperties := models.FindNewProperties(currentProperties, previousProperties)
+	priceChanges := models.FindPriceChanges(currentProperties, previousProperties)
+	// Listings beyond MAX_PAGE were not scraped, so they can't be told apart
+	// from delisted ones
+	var delisted []models.Property
+	if stats.Truncated {
+		p.logger.WarnContext(ctx, "Search results exceed MAX_PAGE, skipping delisting detection", "max_pages", p.cfg.MaxPage)
+	} else {
+		delisted = models.FindDelisted(currentProperties, previousProperties)
+	}
+	p.logger.InfoContext(ctx, "Compared with previous data",
+		"new", len(newProperties), "price_changes", len(priceChanges), "delisted", len(delisted))
+	summary.New, summary.PriceChanges, summary.Delisted = len(newProperties), len(priceChanges), len(delisted)
+
+	// Step 4: Merge and save to S3
+	mergedProperties := models.MergeProperties(currentProperties, previousProperties)
+	p.logger.InfoContext(ctx, "Uploading merged data", "key", p.store.BucketKey(), "properties", len(mergedProperties))
+	if err := p.store.Upload(ctx, mergedProperties); err != nil {
+		return summary.fail(StageUpload, fmt.Errorf("failed to upload data: %w", err))
+	}
+	summary.Uploads = append(summary.Uploads, p.store.BucketKey())
+
+	// Track near-duplicate listings of the same unit across IDs
+	clusters, err := p.updateClusters(ctx, summary, currentProperties)
+	if err != nil {
+		return summary.fail(StageClusters, err)
+	}
+
+	// Track buildings and their vacancies
+	buildings, err := p.updateBuildings(ctx, summary, currentProperties)
+	if err != nil {
+		return summary.fail(StageBuildings, err)
+	}
+
+	if backfill {
+		p.logger.InfoContext(ctx, "Preparing rent model", "model", p.cfg.RentModel)
+		if _, err := p.prepareModel(ctx, summary, mergedProperties); err != nil {
+			return summary.fail(StageModel, err)
+		}
+		p.logger.InfoContext(ctx, "Backfill: skipping notifications")
+		if err := p.updateMarketIndex(ctx, summary, mergedProperties, reportNever); err != nil {
+			return summary.fail(StageMarketIndex, err)
+		}
+		return nil
+	}
+
+	// With notify-all, every listed property is scored and notified immediately
+	candidates := newProperties
+	if p.notifyAll {
+		candidates = currentProperties
+	}
+
+	// Step 5: Analyze new properties
+	var scoredProperties []notifier.PropertyWithScore
+	if len(candidates) > 0 {
+		p.logger.InfoContext(ctx, "Preparing rent model", "model", p.cfg.RentModel)
+		// Use merged data for the model, but only score new properties
+		model, err := p.prepareModel(ctx, summary, mergedProperties)
+		if err != nil {
+			return summary.fail(StageModel, err)
+		}
+		// Without a model, candidates are still described by comparables
+		scoredProperties = p.analyzer.Score(model, mergedProperties, candidates)
+		p.analyzer.FlagDuplicates(scoredProperties, clusters)
+		p.analyzer.DescribeBuildings(scoredProperties, buildings, buildings.UpdatedAt)
+
+		var filtered notifier.FilterResult
+		scoredProperties, filtered = p.filter.Apply(scoredProperties)
+		summary.Filtered = filtered.ExcludedCount()
+		if filtered.ExcludedCount() > 0 {
+			p.logger.InfoContext(ctx, "Filtered out properties",
+				"excluded", filtered.ExcludedCount(), "total", filtered.Total, "rules", filtered.String())
+		}
+	}
+
+	// Step 6: Notify new properties, or accumulate them into the digest
+	if p.schedule.Enabled() && !p.notifyAll {
+		if err := p.notifyDigest(ctx, summary, scoredProperties, priceChanges, delisted); err != nil {
+			return summary.fail(StageDigest, err)
+		}
+	} else if len(scoredProperties) > 0 {
+		p.logger.InfoContext(ctx, "Sending Discord notification", "properties", len(scoredProperties))
+		if err := p.notifier.Notify(ctx, scoredProperties); err != nil {
+			return summary.fail(StageNotify, fmt.Errorf("failed to send notification: %w", err))
+		}
+		p.logger.InfoContext(ctx, "Notified properties", "properties", len(scoredProperties))
+		summary.Notified = len(scoredProperties)
+	} else {
+		p.logger.InfoContext(ctx, "No new properties to notify, skipping notification")
+	}
+
+	// Step 7: Update market index and send a periodic market report
+	if err := p.updateMarketIndex(ctx, summary, mergedProperties, reportWhenDue); err != nil {
+		return summary.fail(StageMarketIndex, err)
+	}
+	return nil
+}
+
+// notifyDigest immediately notifies properties above the immediate alert
+// threshold, accumulates the run into the persisted digest and sends the
+// digest when a scheduled time has passed.
+func (p *Pipeline) notifyDigest(ctx context.Context, summary *Summary, scored []notifier.PropertyWithScore, changes []models.PriceChange, delisted []models.Property) error {
+	if p.cfg.DigestImmediateThreshold > 0 {
+		var immediate []notifier.PropertyWithScore
+		for _, s := range scored {
+			if s.Label != notifier.ScoreLabelAnalyzing && s.Score >= p.cfg.DigestImmediateThreshold {
+				immediate = append(immediate, s)
+			}
+		}
+		if len(immediate) > 0 {
+			p.logger.InfoContext(ctx, "Sending immediate notification", "properties", len(immediate))
+			if err := p.notifier.Notify(ctx, immediate); err != nil {
+				return fmt.Errorf("failed to send notification: %w", err)
+			}
+			summary.Notified = len(immediate)
+		}
+	}
+
+	var digest notifier.Digest
+	if _, err := p.store.DownloadJSON(ctx, p.cfg.DigestKey, &digest); err != nil {
+		return fmt.Errorf("failed to download digest: %w", err)
+	}
+
+	now := time.Now()
+	digest.Add(scored, changes, delisted, now)
+
+	if p.schedule.Due(digest, now) {
+		p.logger.InfoContext(ctx, "Sending digest",
+			"listings", len(digest.Listings), "price_drops", len(digest.PriceDrops), "delistings", len(digest.Delistings))
+		if err := p.notifier.NotifyDigest(ctx, digest, now.In(p.schedule.Location)); err != nil {
+			return fmt.Errorf("failed to send digest: %w", err)
+		}
+		digest.Reset(now)
+		summary.DigestSent = true
+	}
+
+	if err := p.store.UploadJSON(ctx, p.cfg.DigestKey, digest); err != nil {
+		return fmt.Errorf("failed to upload digest: %w", err)
+	}
+	summary.Uploads = append(summary.Uploads, p.cfg.DigestKey)
+
+	return nil
+}
+
+// updateClusters adds the current listings to the persisted history of
+// duplicate listing clusters and returns the updated history.
+func (p *Pipeline) updateClusters(ctx context.Context, summary *Summary, current []models.Property) (models.ClusterHistory, error) {
+	var history models.ClusterHistory
+	if _, err := p.store.DownloadJSON(ctx, p.cfg.ClustersKey, &history); err != nil {
+		return history, fmt.Errorf("failed to download listing clusters: %w", err)
+	}
+
+	history = p.analyzer.UpdateClusters(history, current, time.Now())
+
+	p.logger.InfoContext(ctx, "Uploading listing clusters", "key", p.cfg.ClustersKey, "units", len(history.Clusters))
+	if err := p.store.UploadJSON(ctx, p.cfg.ClustersKey, history); err != nil {
+		return history, fmt.Errorf("failed to upload listing clusters: %w", err)
+	}
+	summary.Uploads = append(summary.Uploads, p.cfg.ClustersKey)
+
+	return history, nil
+}
+
+// updateBuildings records the current listings as vacancies in the persisted
+// buildings and returns the updated history.
+func (p *Pipeline) updateBuildings(ctx context.Context, summary *Summary, current []models.Property) (models.BuildingHistory, error) {
+	var history models.BuildingHistory
+	if _, err := p.store.DownloadJSON(ctx, p.cfg.BuildingsKey, &history); err != nil {
+		return history, fmt.Errorf("failed to download buildings: %w", err)
+	}
+
+	history = models.UpdateBuildings(history, current, time.Now())
+
+	p.logger.InfoContext(ctx, "Uploading buildings", "key", p.cfg.BuildingsKey, "buildings", len(history.Buildings))
+	if err := p.store.UploadJSON(ctx, p.cfg.BuildingsKey, history); err != nil {
+		return history, fmt.Errorf("failed to upload buildings: %w", err)
+	}
+	summary.Uploads = append(summary.Uploads, p.cfg.BuildingsKey)
+
+	return history, nil
+}
+
+// prepareModel returns the rent model for scoring. The persisted model is
+// reused when a refit is not due or the new fit fails, and newly fitted models
+// are persisted. Returns a nil model (and no error) if no model is available.
+func (p *Pipeline) prepareModel(ctx context.Context, summary *Summary, properties []models.Property) (analyzer.RentModel, error) {
+	var snapshot analyzer.ModelSnapshot
+	found, err := p.store.DownloadJSON(ctx, p.cfg.ModelKey, &snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download model: %w", err)
+	}
+	var previous *analyzer.ModelSnapshot
+	if found {
+		previous = &snapshot
+	}
+
+	now := time.Now()
+	model, fitted, err := p.analyzer.FitOrReuse(properties, previous, now, p.cfg.ModelRefitInterval)
+	if err != nil {
+		p.logger.WarnContext(ctx, "No model available, skipping scoring", "error", err)
+		summary.warn(StageModel, err)
+		return nil, nil
+	}
+
+	m := model.Metrics()
+	summary.Model = &m
+	if !fitted {
+		p.logger.InfoContext(ctx, "Reusing persisted model",
+			"trained_at", previous.TrainedAt, "samples", m.Samples, "validation_rmse", m.ValidationRMSE)
+		return model, nil
+	}
+	p.logger.InfoContext(ctx, "Model fitted", "type", m.Type, "samples", m.Samples,
+		"train_rmse", m.TrainRMSE, "validation_rmse", m.ValidationRMSE, "validation_mae", m.ValidationMAE)
+
+	newSnapshot, err := analyzer.NewSnapshot(model, now)
+	if errors.Is(err, analyzer.ErrNotSerializable) {
+		return model, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize model: %w", err)
+	}
+
+	if previous != nil {
+		p.checkDrift(ctx, summary, previous, newSnapshot)
+	}
+
+	if err := p.store.UploadJSON(ctx, p.cfg.ModelKey, newSnapshot); err != nil {
+		return nil, fmt.Errorf("failed to upload model: %w", err)
+	}
+	summary.Uploads = append(summary.Uploads, p.cfg.ModelKey)
+
+	return model, nil
+}
+
+// checkDrift compares the new model with the persisted one and alerts the
+// operator if the difference exceeds the configured thresholds. A sudden drift
+// usually means that parsing of the SUUMO pages broke. A failed alert is
+// recorded in the summary without failing the run.
+func (p *Pipeline) checkDrift(ctx context.Context, summary *Summary, previous, current *analyzer.ModelSnapshot) {
+	report := analyzer.DetectDrift(previous, current, analyzer.DriftThresholds{
+		Coefficient:  p.cfg.DriftCoefficientThreshold,
+		RMSE:         p.cfg.DriftRMSEThreshold,
+		Distribution: p.cfg.DriftDistributionThreshold,
+	})
+	if !report.Drifted() {
+		return
+	}
+
+	for _, item := range report.Items {
+		if item.Exceeded {
+			p.logger.WarnContext(ctx, "Model drift", "kind", item.Kind, "feature", item.Feature,
+				"previous", item.Previous, "current", item.Current, "shift", item.Shift)
+		}
+	}
+
+	if p.cfg.OperatorWebhookURL == "" {
+		return
+	}
+	p.logger.InfoContext(ctx, "Sending model drift alert")
+	if err := notifier.NewNotifier(p.cfg.OperatorWebhookURL, append([]notifier.Option{notifier.WithDryRun(p.cfg.DryRun)}, p.notifierOpts...)...).NotifyDrift(ctx, report); err != nil {
+		p.logger.WarnContext(ctx, "Failed to send drift alert", "error", err)
+		summary.warn(StageModel, err)
+	}
+}
+
+// reportMode controls when updateMarketIndex sends a market report.
+type reportMode int
+
+const (
+	reportWhenDue reportMode = iota // When MARKET_REPORT_INTERVAL has elapsed
+	reportNever
+	reportAlways
+)
+
+// updateMarketIndex recomputes the market index from the stored history,
+// persists it, and sends a market report according to mode.
+func (p *Pipeline) updateMarketIndex(ctx context.Context, summary *Summary, properties []models.Property, mode reportMode) error {
+	var previous models.MarketIndex
+	if _, err := p.store.DownloadJSON(ctx, p.cfg.MarketIndexKey, &previous); err != nil {
+		return fmt.Errorf("failed to download market index: %w", err)
+	}
+
+	index, err := p.analyzer.MarketIndex(properties, models.IndexPeriod(p.cfg.MarketIndexPeriod))
+	if err != nil {
+		p.logger.WarnContext(ctx, "Skipping market index", "error", err)
+		summary.warn(StageMarketIndex, err)
+		return nil
+	}
+	now := time.Now()
+	index.GeneratedAt = now
+	index.LastReportAt = previous.LastReportAt
+
+	due := p.cfg.MarketReportInterval > 0 && now.Sub(index.LastReportAt) >= p.cfg.MarketReportInterval
+	if mode == reportAlways || (mode == reportWhenDue && due) {
+		p.logger.InfoContext(ctx, "Sending market report")
+		if err := p.notifier.NotifyMarketReport(ctx, index); err != nil {
+			return fmt.Errorf("failed to send market report: %w", err)
+		}
+		index.LastReportAt = now
+		summary.MarketReportSent = true
+	}
+
+	p.logger.InfoContext(ctx, "Uploading market index", "key", p.cfg.MarketIndexKey,
+		"stations", len(index.Stations), "wards", len(index.Wards))
+	if err := p.store.UploadJSON(ctx, p.cfg.MarketIndexKey, index); err != nil {
+		return fmt.Errorf("failed to upload market index: %w", err)
+	}
+	summary.Uploads = append(summary.Uploads, p.cfg.MarketIndexKey)
+
+	return nil
+}
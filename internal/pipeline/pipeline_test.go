@@ -0,0 +1,324 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alp/suumo-hunter/internal/config"
+	"github.com/alp/suumo-hunter/internal/logging"
+	"github.com/alp/suumo-hunter/internal/metrics"
+	"github.com/alp/suumo-hunter/internal/models"
+	"github.com/alp/suumo-hunter/internal/notifier"
+	"github.com/alp/suumo-hunter/internal/storage"
+)
+
+// listing is a room in the test SUUMO page.
+type listing struct {
+	id   int
+	rent string
+}
+
+// suumoPage renders a SUUMO search result page with one building and the listings.
+func suumoPage(listings []listing) string {
+	var rows strings.Builder
+	for _, l := range listings {
+		fmt.Fprintf(&rows, `<tr>
+			<td>1</td><td>-</td><td>%d階</td>
+			<td><span class="cassetteitem_price--rent">%s</span></td>
+			<td><span class="cassetteitem_price--administration">5000円</span></td>
+			<td><span class="cassetteitem_price--deposit">-</span></td>
+			<td><span class="cassetteitem_price--gratuity">-</span></td>
+			<td><span class="cassetteitem_madori">1K</span></td>
+			<td><span class="cassetteitem_menseki">%d.0m²</span></td>
+			<td><a href="/chintai/jnc_%012d/">詳細を見る</a></td>
+		</tr>`, l.id, l.rent, 20+l.id, l.id)
+	}
+	return `<html><body><div class="cassetteitem">
+		<div class="cassetteitem_content-title">テストマンション</div>
+		<ul class="cassetteitem_detail">
+			<li class="cassetteitem_detail-col1">東京都中野区中野1-1-1</li>
+			<li class="cassetteitem_detail-col2"><div class="cassetteitem_detail-text">JR中央線/中野駅 歩5分</div></li>
+			<li class="cassetteitem_detail-col3"><div>築5年</div><div>5階建</div></li>
+		</ul>
+		<table class="cassetteitem_other"><tbody>` + rows.String() + `</tbody></table>
+	</div></body></html>`
+}
+
+// setup returns a pipeline scraping a test server that serves the page
+// returned by page, storing data in a temporary directory and writing
+// notifications to the returned buffer.
+func setup(t *testing.T, page func() string, opts ...Option) (*Pipeline, *storage.Storage, *bytes.Buffer) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, page())
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("BUCKET_NAME", "bucket")
+	t.Setenv("SUUMO_SEARCH_URL", server.URL)
+	t.Setenv("DISCORD_WEBHOOK_URL", "https://discord.com/api/webhooks/test")
+	t.Setenv("MAX_PAGE", "1")
+	t.Setenv("MAX_DETAIL_PAGES", "0") // Detail page URLs point at suumo.jp
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	store := storage.NewStorage(storage.NewLocalClient(t.TempDir()), cfg.BucketName, cfg.BucketKey, storage.WithDryRun(cfg.DryRun))
+	var out bytes.Buffer
+	p, err := New(cfg, store, append([]Option{WithNotifierOptions(notifier.WithOutput(&out))}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return p, store, &out
+}
+
+func TestRun(t *testing.T) {
+	listings := []listing{{id: 1, rent: "8万円"}, {id: 2, rent: "8.5万円"}}
+	p, store, out := setup(t, func() string { return suumoPage(listings) })
+	ctx := context.Background()
+
+	summary, err := p.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if summary.Scraped != 2 || summary.New != 2 || summary.Notified != 2 || summary.DryRun {
+		t.Errorf("Summary = %+v, want 2 scraped, new and notified", summary)
+	}
+	if !strings.Contains(out.String(), "新着物件のお知らせ") || strings.Count(out.String(), "テストマンション") != 2 {
+		t.Errorf("First run should notify both listings, got %q", out.String())
+	}
+
+	stored, err := store.Download(ctx)
+	if err != nil || len(stored) != 2 {
+		t.Fatalf("Stored properties = (%d, %v), want 2", len(stored), err)
+	}
+	var buildings models.BuildingHistory
+	if found, err := store.DownloadJSON(ctx, p.cfg.BuildingsKey, &buildings); !found || err != nil || len(buildings.Buildings) != 1 {
+		t.Errorf("Buildings = (%v, %v, %+v), want 1 building", found, err, buildings)
+	}
+
+	// Second run: one listing is gone, no new listings
+	out.Reset()
+	listings = listings[:1]
+	summary, err = p.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if summary.New != 0 || summary.Delisted != 1 || summary.Notified != 0 {
+		t.Errorf("Summary = %+v, want 1 delisted and nothing new", summary)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Second run should not notify, got %q", out.String())
+	}
+}
+
+func TestRunTruncatedSkipsDelisting(t *testing.T) {
+	listings := []listing{{id: 1, rent: "8万円"}, {id: 2, rent: "8.5万円"}}
+	next := ""
+	p, _, _ := setup(t, func() string {
+		return strings.Replace(suumoPage(listings), "</body>", next+"</body>", 1)
+	})
+	ctx := context.Background()
+
+	if _, err := p.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// More pages than MAX_PAGE: listing 2 may have moved to an unscraped page
+	listings = listings[:1]
+	next = `<div class="pagination"><a href="?page=2">次へ</a></div>`
+	summary, err := p.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !summary.Truncated || summary.Delisted != 0 {
+		t.Errorf("Summary = %+v, want truncated with no delisting", summary)
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	t.Setenv("DRY_RUN", "true")
+	p, store, out := setup(t, func() string { return suumoPage([]listing{{id: 1, rent: "8万円"}}) })
+	ctx := context.Background()
+
+	summary, err := p.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !summary.DryRun || summary.New != 1 || summary.Notified != 1 {
+		t.Errorf("Summary = %+v, want a dry run notifying 1 property", summary)
+	}
+	if len(summary.Uploads) == 0 || summary.Uploads[0] != p.cfg.BucketKey {
+		t.Errorf("Uploads = %v, want the skipped uploads starting with %s", summary.Uploads, p.cfg.BucketKey)
+	}
+	// The output option still previews the messages
+	if !strings.Contains(out.String(), "新着物件のお知らせ") {
+		t.Errorf("Output = %q, want the notification preview", out.String())
+	}
+
+	stored, err := store.Download(ctx)
+	if err != nil || len(stored) != 0 {
+		t.Errorf("Stored properties = (%d, %v), want nothing stored", len(stored), err)
+	}
+	var buildings models.BuildingHistory
+	if found, err := store.DownloadJSON(ctx, p.cfg.BuildingsKey, &buildings); found || err != nil {
+		t.Errorf("DownloadJSON(%s) = (%v, %v), want not found", p.cfg.BuildingsKey, found, err)
+	}
+}
+
+func TestNewDryRunRequiresDryRunStorage(t *testing.T) {
+	cfg := &config.Config{DryRun: true, DigestMode: "off"}
+	if _, err := New(cfg, storage.NewStorage(nil, "bucket", "properties.csv")); err == nil {
+		t.Error("New() with a dry run and a writing storage should return an error")
+	}
+}
+
+func TestRunLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "debug", "json")
+	if err != nil {
+		t.Fatalf("logging.New() error = %v", err)
+	}
+	p, _, _ := setup(t, func() string { return suumoPage([]listing{{id: 1, rent: "8万円"}}) }, WithLogger(logger))
+
+	summary, err := p.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Records of the scraper carry the run attributes and the page
+	var fetched bool
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid JSON record %q: %v", line, err)
+		}
+		if record["run_id"] != summary.RunID || record["profile"] != "default" || record["job"] != "scrape" {
+			t.Errorf("Record %q lacks the run attributes", line)
+		}
+		if record["msg"] == "Fetched page" && record["page"] == 1.0 {
+			fetched = true
+		}
+	}
+	if !fetched {
+		t.Errorf("No page record in %q", buf.String())
+	}
+}
+
+func TestRunMetrics(t *testing.T) {
+	registry := metrics.NewRegistry("suumo")
+	p, _, _ := setup(t, func() string { return suumoPage([]listing{{id: 1, rent: "8万円"}, {id: 2, rent: "9万円"}}) }, WithMetrics(registry))
+
+	if _, err := p.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := registry.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	for _, want := range []string{
+		`suumo_runs_total{job="scrape",result="success"} 1`,
+		`suumo_run_duration_seconds_count{job="scrape"} 1`,
+		`suumo_listings_new_total 2`,
+		`suumo_scrape_pages_total 1`,
+		`suumo_scrape_properties_total 2`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Metrics lack %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	cfg := &config.Config{NotifySort: "random", DigestMode: "off"}
+	if _, err := New(cfg, nil); err == nil {
+		t.Error("New() with an invalid sort order should return an error")
+	}
+
+	cfg = &config.Config{FilterMaxMetrics: map[string]float64{"balcony": 1}, DigestMode: "off"}
+	if _, err := New(cfg, nil); err == nil {
+		t.Error("New() with an unknown filter metric should return an error")
+	}
+}
+
+// varyingPage renders a SUUMO search result page with one building per
+// listing id, varying in age, walk minutes and area so that the linear model
+// can be fitted. Rents rise with the area by perArea yen per m².
+func varyingPage(ids []int, perArea int) string {
+	var items strings.Builder
+	for _, id := range ids {
+		area := 20 + id%17
+		fmt.Fprintf(&items, `<div class="cassetteitem">
+			<div class="cassetteitem_content-title">テストマンション%d</div>
+			<ul class="cassetteitem_detail">
+				<li class="cassetteitem_detail-col1">東京都中野区中野%d-1-1</li>
+				<li class="cassetteitem_detail-col2"><div class="cassetteitem_detail-text">JR中央線/中野駅 歩%d分</div></li>
+				<li class="cassetteitem_detail-col3"><div>築%d年</div><div>5階建</div></li>
+			</ul>
+			<table class="cassetteitem_other"><tbody><tr>
+				<td>1</td><td>-</td><td>%d階</td>
+				<td><span class="cassetteitem_price--rent">%d円</span></td>
+				<td><span class="cassetteitem_price--administration">5000円</span></td>
+				<td><span class="cassetteitem_price--deposit">-</span></td>
+				<td><span class="cassetteitem_price--gratuity">-</span></td>
+				<td><span class="cassetteitem_madori">1K</span></td>
+				<td><span class="cassetteitem_menseki">%d.0m²</span></td>
+				<td><a href="/chintai/jnc_%012d/">詳細を見る</a></td>
+			</tr></tbody></table>
+		</div>`, id, id, 1+id%13, id%11, 1+id%5, 30000+perArea*area+(id%7)*500, area, id)
+	}
+	return `<html><body>` + items.String() + `</body></html>`
+}
+
+func TestRunDriftAlertFailure(t *testing.T) {
+	t.Setenv("OPERATOR_WEBHOOK_URL", "https://discord.com/api/webhooks/operator")
+
+	var posted []string
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(r.URL.Path, "/operator") {
+			return nil, errors.New("connection refused")
+		}
+		posted = append(posted, r.URL.Path)
+		return &http.Response{StatusCode: http.StatusNoContent, Body: http.NoBody}, nil
+	})}
+
+	var ids []int
+	for id := 1; id <= 30; id++ {
+		ids = append(ids, id)
+	}
+	perArea := 2000
+	p, _, _ := setup(t, func() string { return varyingPage(ids, perArea) },
+		WithNotifierOptions(notifier.WithOutput(nil), notifier.WithHTTPClient(client)))
+	ctx := context.Background()
+
+	if _, err := p.Run(ctx); err != nil {
+		t.Fatalf("First Run() error = %v", err)
+	}
+
+	// A broken parse would show as a sudden change of the area coefficient
+	posted = nil
+	perArea = 6000
+	for id := 101; id <= 110; id++ {
+		ids = append(ids, id)
+	}
+	summary, err := p.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v, want the failed drift alert not to fail the run", err)
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0].Stage != StageModel || !strings.Contains(summary.Errors[0].Error, "drift alert") {
+		t.Errorf("Errors = %+v, want the failed drift alert", summary.Errors)
+	}
+	if summary.Notified != 10 || len(posted) == 0 {
+		t.Errorf("Notified = %d with %d posts, want the new listings notified", summary.Notified, len(posted))
+	}
+}
@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/analyzer"
+)
+
+// Stage is a step of a run, used to attribute errors.
+type Stage string
+
+// Stages of a run.
+const (
+	StageDownload    Stage = "download"
+	StageScrape      Stage = "scrape"
+	StageUpload      Stage = "upload"
+	StageClusters    Stage = "clusters"
+	StageBuildings   Stage = "buildings"
+	StageModel       Stage = "model"
+	StageNotify      Stage = "notify"
+	StageDigest      Stage = "digest"
+	StageMarketIndex Stage = "market_index"
+)
+
+// StageError is an error that occurred in a stage of a run. Errors that
+// aborted the run and problems the run continued past (e.g., no model
+// available) are both recorded.
+type StageError struct {
+	Stage Stage  `json:"stage"`
+	Error string `json:"error"`
+}
+
+// Summary is the record of a run. It is returned by RunJob and persisted
+// under RUNS_PREFIX to track the health of the pipeline over time.
+type Summary struct {
+	RunID      string    `json:"run_id"`
+	Job        Job       `json:"job"`
+	Profile    string    `json:"profile"`
+	DryRun     bool      `json:"dry_run"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+
+	PagesFetched     int                    `json:"pages_fetched"`
+	Retries          int                    `json:"retries"`        // Retried page fetches
+	Truncated        bool                   `json:"truncated"`      // More result pages remained after MAX_PAGE
+	Scraped          int                    `json:"scraped"`        // Properties parsed from the scraped pages
+	ParseFailures    int                    `json:"parse_failures"` // Rows that could not be fully parsed
+	DetailPages      int                    `json:"detail_pages"`   // Detail pages fetched for facilities and structure
+	New              int                    `json:"new"`            // Properties not seen before
+	PriceChanges     int                    `json:"price_changes"`  // Properties whose rent changed
+	Delisted         int                    `json:"delisted"`       // Properties no longer listed (not detected if truncated)
+	Filtered         int                    `json:"filtered"`       // New properties excluded by the notification filter
+	Notified         int                    `json:"notified"`       // Properties in immediate notifications
+	DigestSent       bool                   `json:"digest_sent"`
+	MarketReportSent bool                   `json:"market_report_sent"`
+	Model            *analyzer.ModelMetrics `json:"model,omitempty"` // Metrics of the model used for scoring
+	Uploads          []string               `json:"uploads"`         // Object keys uploaded (or skipped in dry run)
+	Errors           []StageError           `json:"errors"`
+}
+
+// fail records err as an error of the stage and returns it.
+func (s *Summary) fail(stage Stage, err error) error {
+	s.warn(stage, err)
+	return err
+}
+
+// warn records err as an error of the stage that the run continued past.
+func (s *Summary) warn(stage Stage, err error) {
+	s.Errors = append(s.Errors, StageError{Stage: stage, Error: err.Error()})
+}
+
+// key returns the object key of the run record under prefix.
+func (s *Summary) key(prefix string) string {
+	return prefix + s.StartedAt.UTC().Format("2006/01/02/") + s.RunID + ".json"
+}
+
+// newRunID returns a run ID sortable by start time, e.g. "20240115T091500Z-1a2b3c4d".
+func newRunID(start time.Time) string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return start.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
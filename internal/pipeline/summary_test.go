@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/scraper"
+)
+
+// roundTripFunc is an http.RoundTripper function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestSummaryKey(t *testing.T) {
+	start := time.Date(2024, 1, 15, 18, 15, 0, 0, time.FixedZone("JST", 9*60*60))
+	s := Summary{RunID: newRunID(start), StartedAt: start}
+
+	if !regexp.MustCompile(`^20240115T091500Z-[0-9a-f]{8}$`).MatchString(s.RunID) {
+		t.Errorf("newRunID() = %q, want the UTC start time and a random suffix", s.RunID)
+	}
+	if got, want := s.key("runs/"), "runs/2024/01/15/"+s.RunID+".json"; got != want {
+		t.Errorf("key() = %q, want %q", got, want)
+	}
+	if newRunID(start) == s.RunID {
+		t.Error("newRunID() should return different IDs for the same start time")
+	}
+}
+
+func TestRunRecord(t *testing.T) {
+	p, store, _ := setup(t, func() string { return suumoPage([]listing{{id: 1, rent: "8万円"}, {id: 2, rent: "-"}}) })
+	ctx := context.Background()
+
+	summary, err := p.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if summary.PagesFetched != 1 || summary.Scraped != 2 || summary.ParseFailures != 1 {
+		t.Errorf("Summary = %+v, want 1 page, 2 properties and 1 parse failure", summary)
+	}
+	if summary.FinishedAt.Before(summary.StartedAt) {
+		t.Errorf("FinishedAt %v is before StartedAt %v", summary.FinishedAt, summary.StartedAt)
+	}
+
+	var record Summary
+	if found, err := store.DownloadJSON(ctx, summary.key(p.cfg.RunsPrefix), &record); !found || err != nil {
+		t.Fatalf("Run record = (%v, %v), want it persisted", found, err)
+	}
+	if record.RunID != summary.RunID || record.Scraped != 2 || record.New != 2 {
+		t.Errorf("Persisted record = %+v, want %+v", record, summary)
+	}
+}
+
+func TestRunRecordFailure(t *testing.T) {
+	failing := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})}
+	p, store, _ := setup(t, func() string { return "" },
+		WithScraperOptions(scraper.WithRetryAttempts(1), scraper.WithHTTPClient(failing)))
+	ctx := context.Background()
+
+	summary, err := p.Run(ctx)
+	if err == nil {
+		t.Fatal("Run() expected a scrape error, got nil")
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0].Stage != StageScrape {
+		t.Errorf("Errors = %+v, want a scrape error", summary.Errors)
+	}
+
+	var record Summary
+	if found, err := store.DownloadJSON(ctx, summary.key(p.cfg.RunsPrefix), &record); !found || err != nil {
+		t.Fatalf("Run record = (%v, %v), want failed runs persisted", found, err)
+	}
+	if len(record.Errors) != 1 || record.Errors[0].Stage != StageScrape {
+		t.Errorf("Persisted errors = %+v, want a scrape error", record.Errors)
+	}
+}
+
+func TestRunRecordUploadFailure(t *testing.T) {
+	p, _, _ := setup(t, func() string { return suumoPage([]listing{{id: 1, rent: "8万円"}}) })
+	// The properties CSV is a file, so run records under it can't be written
+	p.cfg.RunsPrefix = p.cfg.BucketKey + "/"
+
+	summary, err := p.Run(context.Background())
+	if err != nil {
+		t.Errorf("Run() error = %v, want the run to succeed when only the run record upload fails", err)
+	}
+	if summary.New != 1 {
+		t.Errorf("New = %d, want 1", summary.New)
+	}
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"* * * * *", false},
+		{"15 9,15,18,22 * * *", false},
+		{"*/15 0-6/2 1 1-12 0,7", false},
+		{"5/20 * * * *", false},
+		{"* * * *", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"* * 0 * *", true},
+		{"* * * * 8", true},
+		{"*/0 * * * *", true},
+		{"10-5 * * * *", true},
+		{"a * * * *", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseSchedule(tt.expr, time.UTC)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSchedule(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	// Monday, 2024-01-15 10:20 JST
+	now := time.Date(2024, 1, 15, 10, 20, 30, 0, tokyo)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 15, 10, 21, 0, 0, tokyo)},
+		{"*/15 * * * *", time.Date(2024, 1, 15, 10, 30, 0, 0, tokyo)},
+		{"15 9,15,18,22 * * *", time.Date(2024, 1, 15, 15, 15, 0, 0, tokyo)},
+		{"0 8 * * *", time.Date(2024, 1, 16, 8, 0, 0, 0, tokyo)},
+		{"0 10 * * 1", time.Date(2024, 1, 22, 10, 0, 0, 0, tokyo)},
+		{"0 10 * * 0", time.Date(2024, 1, 21, 10, 0, 0, 0, tokyo)},
+		{"0 10 * * 7", time.Date(2024, 1, 21, 10, 0, 0, 0, tokyo)},
+		{"0 0 1 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, tokyo)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, tokyo)},
+		// Either day field matches when both are restricted
+		{"0 0 20 * 3", time.Date(2024, 1, 17, 0, 0, 0, 0, tokyo)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr, tokyo)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) error = %v", tt.expr, err)
+			}
+			if got := s.Next(now); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The time zone of the schedule applies, not the one of the argument
+	s, _ := ParseSchedule("0 9 * * *", tokyo)
+	if got, want := s.Next(now.UTC()), time.Date(2024, 1, 16, 9, 0, 0, 0, tokyo); !got.Equal(want) {
+		t.Errorf("Next(UTC) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,248 @@
+// Package scheduler runs jobs in-process on cron schedules, for long-running
+// deployments that don't use EventBridge. At most one job runs at a time:
+// a job due while another is running is skipped.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/alp/suumo-hunter/internal/metrics"
+)
+
+// Errors returned by Trigger.
+var (
+	ErrRunning = errors.New("a job is already running")
+	ErrClosed  = errors.New("scheduler is shut down")
+)
+
+// Func runs a job.
+type Func func(ctx context.Context, job string) error
+
+// Entry is a job and its schedule.
+type Entry struct {
+	Job      string
+	Schedule Schedule
+}
+
+// RunInfo describes a job run.
+type RunInfo struct {
+	Job        string     `json:"job"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"` // Nil while running
+	Error      string     `json:"error,omitempty"`
+}
+
+// Scheduler runs jobs on their schedules and on demand.
+type Scheduler struct {
+	run     Func
+	entries []Entry
+	jitter  time.Duration
+	logger  *slog.Logger
+	metrics metrics.Recorder
+
+	// ctx is the context of runs, canceled by Shutdown if they don't finish in time.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	closed  bool
+	current *RunInfo
+	last    *RunInfo
+	next    map[string]time.Time
+}
+
+// Option is a functional option for configuring the Scheduler.
+type Option func(*Scheduler)
+
+// WithJitter delays each scheduled run by a random duration up to d,
+// to spread the requests of several instances.
+func WithJitter(d time.Duration) Option {
+	return func(s *Scheduler) {
+		s.jitter = d
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+// WithMetrics sets the recorder of started and skipped runs.
+func WithMetrics(m metrics.Recorder) Option {
+	return func(s *Scheduler) {
+		s.metrics = m
+	}
+}
+
+// New creates a Scheduler running the entries with run.
+func New(run Func, entries []Entry, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		run:     run,
+		entries: entries,
+		logger:  slog.Default(),
+		metrics: metrics.Nop{},
+		next:    make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s
+}
+
+// Run triggers the entries on their schedules until ctx is canceled.
+// Runs in progress are not canceled; see Shutdown.
+func (s *Scheduler) Run(ctx context.Context) {
+	due := make([]time.Time, len(s.entries))
+	now := time.Now()
+	for i := range s.entries {
+		due[i] = s.schedule(i, now)
+	}
+
+	for {
+		i := earliest(due)
+		if i < 0 {
+			<-ctx.Done()
+			return
+		}
+
+		timer := time.NewTimer(time.Until(due[i]))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		job := s.entries[i].Job
+		if err := s.Trigger(job); errors.Is(err, ErrClosed) {
+			return
+		} else if err != nil {
+			s.logger.WarnContext(ctx, "Skipping scheduled job: another job is running", "job", job)
+			s.metrics.Count("scheduler_skipped_total", 1, metrics.L("job", job))
+		}
+		due[i] = s.schedule(i, time.Now())
+	}
+}
+
+// schedule returns the next run time of entry i after now, jitter included,
+// or the zero time if it has none.
+func (s *Scheduler) schedule(i int, now time.Time) time.Time {
+	e := s.entries[i]
+	next := e.Schedule.Next(now)
+	if !next.IsZero() && s.jitter > 0 {
+		next = next.Add(rand.N(s.jitter))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next[e.Job] = next
+	return next
+}
+
+// earliest returns the index of the earliest non-zero time, or -1 if all are zero.
+func earliest(times []time.Time) int {
+	i := -1
+	for j, t := range times {
+		if !t.IsZero() && (i < 0 || t.Before(times[i])) {
+			i = j
+		}
+	}
+	return i
+}
+
+// Trigger starts the job in the background. Returns ErrClosed if the
+// scheduler is shut down and ErrRunning if a job is already running.
+func (s *Scheduler) Trigger(job string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if s.current != nil {
+		return ErrRunning
+	}
+
+	info := &RunInfo{Job: job, StartedAt: time.Now()}
+	s.current = info
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(info)
+	}()
+	return nil
+}
+
+// execute runs the job and records its result.
+func (s *Scheduler) execute(info *RunInfo) {
+	s.logger.InfoContext(s.ctx, "Starting job", "job", info.Job)
+	s.metrics.Count("scheduler_runs_total", 1, metrics.L("job", info.Job))
+	err := s.run(s.ctx, info.Job)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	done := *info
+	finished := time.Now()
+	done.FinishedAt = &finished
+	if err != nil {
+		done.Error = err.Error()
+		s.logger.ErrorContext(s.ctx, "Job failed", "job", info.Job, "error", err)
+	} else {
+		s.logger.InfoContext(s.ctx, "Job finished", "job", info.Job, "duration", finished.Sub(done.StartedAt))
+	}
+	s.current = nil
+	s.last = &done
+}
+
+// Status returns the running job (nil if none), the last finished run (nil
+// if none) and the next scheduled run time of each job.
+func (s *Scheduler) Status() (current, last *RunInfo, next map[string]time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		c := *s.current
+		current = &c
+	}
+	if s.last != nil {
+		l := *s.last
+		last = &l
+	}
+	next = make(map[string]time.Time, len(s.next))
+	for job, t := range s.next {
+		next[job] = t
+	}
+	return current, last, next
+}
+
+// Shutdown stops starting runs and waits for the running job to finish. If
+// ctx is done first, the job's context is canceled and Shutdown returns the
+// context error once the job has returned.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingJob returns a Func that signals started and blocks until release
+// is closed or its context is canceled.
+func blockingJob(started chan<- string, release <-chan struct{}) Func {
+	return func(ctx context.Context, job string) error {
+		started <- job
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestTriggerSkipsIfRunning(t *testing.T) {
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	s := New(blockingJob(started, release), nil)
+
+	if err := s.Trigger("scrape"); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	<-started
+	if err := s.Trigger("digest"); !errors.Is(err, ErrRunning) {
+		t.Errorf("Trigger() while running error = %v, want ErrRunning", err)
+	}
+	if current, _, _ := s.Status(); current == nil || current.Job != "scrape" {
+		t.Errorf("Status() current = %+v, want scrape", current)
+	}
+
+	close(release)
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	current, last, _ := s.Status()
+	if current != nil || last == nil || last.Job != "scrape" || last.FinishedAt == nil || last.Error != "" {
+		t.Errorf("Status() = (%+v, %+v), want last scrape run", current, last)
+	}
+	if err := s.Trigger("scrape"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Trigger() after Shutdown() error = %v, want ErrClosed", err)
+	}
+}
+
+func TestShutdownWaitsForRun(t *testing.T) {
+	started := make(chan string, 1)
+	release := make(chan struct{})
+	s := New(blockingJob(started, release), nil)
+	s.Trigger("scrape")
+	<-started
+
+	done := make(chan error)
+	go func() { done <- s.Shutdown(context.Background()) }()
+	select {
+	case <-done:
+		t.Fatal("Shutdown() returned before the run finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	started := make(chan string, 1)
+	s := New(blockingJob(started, nil), nil)
+	s.Trigger("scrape")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want deadline exceeded", err)
+	}
+	// The run was canceled
+	if _, last, _ := s.Status(); last == nil || last.Error != context.Canceled.Error() {
+		t.Errorf("Status() last = %+v, want canceled run", last)
+	}
+}
+
+func TestRunSchedulesEntries(t *testing.T) {
+	every, _ := ParseSchedule("* * * * *", time.UTC)
+	never, _ := ParseSchedule("0 0 31 2 *", time.UTC)
+	s := New(func(context.Context, string) error { return nil },
+		[]Entry{{Job: "scrape", Schedule: every}, {Job: "report", Schedule: never}},
+		WithJitter(time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	// Wait for the next run times to be computed
+	var next map[string]time.Time
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if _, _, next = s.Status(); len(next) == 2 {
+			break
+		}
+	}
+	want := every.Next(time.Now().Add(-time.Second))
+	if got := next["scrape"]; got.Before(want) || !got.Before(want.Add(time.Minute+time.Second)) {
+		t.Errorf("Next scrape run = %v, want within a second after %v", got, want)
+	}
+	if got := next["report"]; !got.IsZero() {
+		t.Errorf("Next report run = %v, want none", got)
+	}
+
+	cancel()
+	<-done
+}
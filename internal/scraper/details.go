@@ -0,0 +1,100 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/avast/retry-go/v4"
+
+	"github.com/alp/suumo-hunter/internal/metrics"
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+// facilityKeywords maps the facilities read from the detail page to the
+// spellings SUUMO uses in "部屋の特徴・設備".
+var facilityKeywords = []struct {
+	keywords []string
+	set      func(p *models.Property)
+}{
+	{[]string{"オートロック"}, func(p *models.Property) { p.AutoLock = true }},
+	{[]string{"バストイレ別", "バス・トイレ別"}, func(p *models.Property) { p.SeparateBath = true }},
+	{[]string{"室内洗濯"}, func(p *models.Property) { p.IndoorWasher = true }},
+	{[]string{"角部屋"}, func(p *models.Property) { p.CornerUnit = true }},
+}
+
+// ScrapeDetails fetches the detail pages of up to limit properties without
+// details and sets their facilities and structure. The properties are
+// modified in place. Properties whose page could not be fetched are left
+// without details, to be retried in a later run.
+// Returns the number of detail pages fetched and failed.
+func (s *Scraper) ScrapeDetails(ctx context.Context, properties []models.Property, limit int) (fetched, failed int, err error) {
+	for i := range properties {
+		p := &properties[i]
+		if p.HasDetails || p.URL == "" {
+			continue
+		}
+		if fetched+failed >= limit {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return fetched, failed, err
+		}
+
+		if err := s.scrapeDetail(ctx, p); err != nil {
+			failed++
+			s.logger.WarnContext(ctx, "Failed to scrape detail page", "id", p.ID, "url", p.URL, "error", err)
+			continue
+		}
+		fetched++
+	}
+	return fetched, failed, nil
+}
+
+// scrapeDetail fetches the detail page of the property and sets its details.
+func (s *Scraper) scrapeDetail(ctx context.Context, p *models.Property) error {
+	var doc *goquery.Document
+	err := retry.Do(
+		func() error {
+			var fetchErr error
+			doc, fetchErr = s.fetchPage(ctx, p.URL)
+			return fetchErr
+		},
+		retry.Attempts(s.retryAttempts),
+		retry.Delay(s.retryDelay),
+		retry.DelayType(retry.BackOffDelay),
+		retry.Context(ctx),
+	)
+	s.metrics.Count("scrape_detail_pages_total", 1, metrics.Result(err))
+	if err != nil {
+		return fmt.Errorf("failed to fetch detail page after retries: %w", err)
+	}
+
+	parseDetails(doc, p)
+	return nil
+}
+
+// parseDetails sets the facilities ("部屋の特徴・設備") and the structure
+// ("構造" of the property table) of the detail page on the property.
+func parseDetails(doc *goquery.Document, p *models.Property) {
+	facilities := strings.Join(strings.Fields(doc.Find("#bkdt-option li").Text()), "")
+	for _, f := range facilityKeywords {
+		for _, keyword := range f.keywords {
+			if strings.Contains(facilities, keyword) {
+				f.set(p)
+				break
+			}
+		}
+	}
+
+	doc.Find("table.data_table th").EachWithBreak(func(_ int, th *goquery.Selection) bool {
+		if strings.TrimSpace(th.Text()) != "構造" {
+			return true
+		}
+		p.Structure = strings.TrimSpace(th.Next().Text())
+		return false
+	})
+
+	p.HasDetails = true
+}
@@ -0,0 +1,123 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+// sampleDetailHTML is a simplified version of SUUMO's property detail page.
+const sampleDetailHTML = `
+<!DOCTYPE html>
+<html>
+<head><title>SUUMO</title></head>
+<body>
+<div class="section l-space_small" id="bkdt-option">
+	<h2 class="section_h2-header-title">部屋の特徴・設備</h2>
+	<div class="bgc-wht ol-g">
+		<ul class="inline_list">
+			<li>バストイレ別、エアコン、室内洗濯置、オートロック、フローリング、 角部屋</li>
+		</ul>
+	</div>
+</div>
+<table class="data_table table_gaiyou">
+	<tr>
+		<th class="data_01" scope="cols">間取り詳細</th>
+		<td>洋6.5 K2</td>
+		<th class="data_02" scope="cols">構造</th>
+		<td>鉄筋コン</td>
+	</tr>
+	<tr>
+		<th class="data_01" scope="cols">階建</th>
+		<td>3階/5階建</td>
+		<th class="data_02" scope="cols">築年月</th>
+		<td>2019年3月</td>
+	</tr>
+</table>
+</body>
+</html>
+`
+
+func TestParseDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want models.Property
+	}{
+		{
+			name: "all facilities",
+			html: sampleDetailHTML,
+			want: models.Property{AutoLock: true, SeparateBath: true, IndoorWasher: true, CornerUnit: true, Structure: "鉄筋コン", HasDetails: true},
+		},
+		{
+			name: "no facilities",
+			html: strings.Replace(strings.Replace(sampleDetailHTML,
+				"バストイレ別、エアコン、室内洗濯置、オートロック、フローリング、 角部屋", "エアコン", 1),
+				"鉄筋コン", "木造", 1),
+			want: models.Property{Structure: "木造", HasDetails: true},
+		},
+		{
+			name: "spelled with a middle dot",
+			html: strings.Replace(sampleDetailHTML, "バストイレ別", "バス・トイレ別", 1),
+			want: models.Property{AutoLock: true, SeparateBath: true, IndoorWasher: true, CornerUnit: true, Structure: "鉄筋コン", HasDetails: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+			var p models.Property
+			parseDetails(doc, &p)
+			if p != tt.want {
+				t.Errorf("parseDetails() = %+v, want %+v", p, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrapeDetails(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if strings.Contains(r.URL.Path, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(sampleDetailHTML))
+	}))
+	defer server.Close()
+
+	properties := []models.Property{
+		{ID: "known", URL: server.URL + "/chintai/known/", HasDetails: true},
+		{ID: "missing", URL: server.URL + "/chintai/missing/"},
+		{ID: "new", URL: server.URL + "/chintai/new/"},
+		{ID: "over limit", URL: server.URL + "/chintai/over/"},
+	}
+
+	s := NewScraper(server.URL, WithRetryAttempts(1))
+	fetched, failed, err := s.ScrapeDetails(context.Background(), properties, 2)
+	if err != nil {
+		t.Fatalf("ScrapeDetails() error = %v", err)
+	}
+	if fetched != 1 || failed != 1 || requests != 2 {
+		t.Errorf("ScrapeDetails() = (%d fetched, %d failed) with %d requests, want (1, 1) with 2", fetched, failed, requests)
+	}
+	if properties[1].HasDetails {
+		t.Error("A property whose page failed should be left without details")
+	}
+	if !properties[2].HasDetails || !properties[2].AutoLock || properties[2].Structure != "鉄筋コン" {
+		t.Errorf("Scraped property = %+v, want its details", properties[2])
+	}
+	if properties[3].HasDetails {
+		t.Error("Properties over the limit should not be scraped")
+	}
+}
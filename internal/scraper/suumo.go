@@ -4,6 +4,7 @@ package scraper
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"github.com/avast/retry-go/v4"
 
+	"github.com/alp/suumo-hunter/internal/metrics"
 	"github.com/alp/suumo-hunter/internal/models"
 )
 
@@ -39,6 +41,24 @@ type Scraper struct {
 	retryAttempts uint
 	retryDelay    time.Duration
 	baseURL       string
+	logger        *slog.Logger
+	metrics       metrics.Recorder
+}
+
+// Stats describes a scrape.
+type Stats struct {
+	Pages      int  `json:"pages"`      // Pages fetched
+	Retries    int  `json:"retries"`    // Retried page fetches
+	Rows       int  `json:"rows"`       // Room rows found on the pages
+	Skipped    int  `json:"skipped"`    // Rows skipped because no property ID could be parsed
+	Incomplete int  `json:"incomplete"` // Properties kept without a parsable rent or area
+	Duplicates int  `json:"duplicates"` // Properties listed more than once
+	Truncated  bool `json:"truncated"`  // More result pages remained after maxPages
+}
+
+// ParseFailures returns the number of rows that could not be fully parsed.
+func (s Stats) ParseFailures() int {
+	return s.Skipped + s.Incomplete
 }
 
 // Option is a function that configures a Scraper.
@@ -72,6 +92,21 @@ func WithHTTPClient(c *http.Client) Option {
 	}
 }
 
+// WithLogger sets the logger. Defaults to slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Scraper) {
+		s.logger = l
+	}
+}
+
+// WithMetrics sets the recorder of scrape durations, pages, retries and
+// parse failures.
+func WithMetrics(m metrics.Recorder) Option {
+	return func(s *Scraper) {
+		s.metrics = m
+	}
+}
+
 // NewScraper creates a new Scraper with the given options.
 func NewScraper(baseURL string, opts ...Option) *Scraper {
 	s := &Scraper{
@@ -82,6 +117,8 @@ func NewScraper(baseURL string, opts ...Option) *Scraper {
 		retryAttempts: DefaultRetryAttempts,
 		retryDelay:    DefaultRetryDelay,
 		baseURL:       baseURL,
+		logger:        slog.Default(),
+		metrics:       metrics.Nop{},
 	}
 
 	for _, opt := range opts {
@@ -94,19 +131,41 @@ func NewScraper(baseURL string, opts ...Option) *Scraper {
 // Scrape fetches all property listings from SUUMO.
 // It paginates through the search results up to maxPages.
 func (s *Scraper) Scrape(ctx context.Context) ([]models.Property, error) {
+	properties, _, err := s.ScrapeWithStats(ctx)
+	return properties, err
+}
+
+// ScrapeWithStats is like Scrape but also returns statistics of the scrape,
+// including those of the pages fetched before an error.
+func (s *Scraper) ScrapeWithStats(ctx context.Context) ([]models.Property, Stats, error) {
+	start := time.Now()
+	properties, stats, err := s.scrapeAll(ctx)
+
+	s.metrics.Timing("scrape_duration_seconds", time.Since(start), metrics.Result(err))
+	s.metrics.Count("scrape_pages_total", float64(stats.Pages))
+	s.metrics.Count("scrape_retries_total", float64(stats.Retries))
+	s.metrics.Count("scrape_parse_failures_total", float64(stats.ParseFailures()))
+	s.metrics.Count("scrape_properties_total", float64(len(properties)))
+
+	return properties, stats, err
+}
+
+// scrapeAll fetches the pages of the search results up to maxPages.
+func (s *Scraper) scrapeAll(ctx context.Context) ([]models.Property, Stats, error) {
 	var allProperties []models.Property
+	var stats Stats
 	seenKeys := make(map[string]bool)
 
 	for page := 1; page <= s.maxPages; page++ {
 		select {
 		case <-ctx.Done():
-			return allProperties, ctx.Err()
+			return allProperties, stats, ctx.Err()
 		default:
 		}
 
-		properties, hasMore, err := s.scrapePage(ctx, page)
+		properties, hasMore, err := s.scrapePage(ctx, page, &stats)
 		if err != nil {
-			return allProperties, fmt.Errorf("failed to scrape page %d: %w", page, err)
+			return allProperties, stats, fmt.Errorf("failed to scrape page %d: %w", page, err)
 		}
 
 		// Deduplicate properties using UniqueKey (address+area+layout+floor)
@@ -115,20 +174,23 @@ func (s *Scraper) Scrape(ctx context.Context) ([]models.Property, error) {
 			if !seenKeys[key] {
 				seenKeys[key] = true
 				allProperties = append(allProperties, p)
+			} else {
+				stats.Duplicates++
 			}
 		}
 
 		if !hasMore {
 			break
 		}
+		stats.Truncated = page == s.maxPages
 	}
 
-	return allProperties, nil
+	return allProperties, stats, nil
 }
 
-// scrapePage fetches a single page of property listings.
+// scrapePage fetches a single page of property listings and adds it to stats.
 // Returns the properties found and whether there are more pages.
-func (s *Scraper) scrapePage(ctx context.Context, page int) ([]models.Property, bool, error) {
+func (s *Scraper) scrapePage(ctx context.Context, page int, stats *Stats) ([]models.Property, bool, error) {
 	url := s.buildURL(page)
 
 	var doc *goquery.Document
@@ -143,8 +205,8 @@ func (s *Scraper) scrapePage(ctx context.Context, page int) ([]models.Property,
 		retry.DelayType(retry.BackOffDelay),
 		retry.Context(ctx),
 		retry.OnRetry(func(n uint, err error) {
-			// Log retry attempt (in production, use proper logging)
-			fmt.Printf("Retry %d for %s: %v\n", n+1, url, err)
+			stats.Retries++
+			s.logger.WarnContext(ctx, "Retrying page fetch", "page", page, "attempt", n+1, "url", url, "error", err)
 		}),
 	)
 	if err != nil {
@@ -154,6 +216,20 @@ func (s *Scraper) scrapePage(ctx context.Context, page int) ([]models.Property,
 	properties := s.parseProperties(doc)
 	hasMore := s.hasNextPage(doc)
 
+	rows := doc.Find("div.cassetteitem table.cassetteitem_other tbody tr").Length()
+	stats.Pages++
+	stats.Rows += rows
+	stats.Skipped += rows - len(properties)
+	incomplete := 0
+	for _, p := range properties {
+		if p.Rent <= 0 || p.Area <= 0 {
+			incomplete++
+		}
+	}
+	stats.Incomplete += incomplete
+	s.logger.DebugContext(ctx, "Fetched page", "page", page, "rows", rows, "properties", len(properties),
+		"skipped", rows-len(properties), "incomplete", incomplete, "has_more", hasMore)
+
 	return properties, hasMore, nil
 }
 
@@ -234,23 +310,30 @@ func (s *Scraper) parsePropertyItem(item *goquery.Selection) []models.Property {
 	})
 
 	age, _ := models.ParseAge(buildingAge)
+	totalFloors, _ := models.ParseTotalFloors(buildingFloors)
 
 	// Parse access information (walking time to station and station name)
-	// Take the first station's walking time and name
+	// Take the first station's walking time and name, and keep all lines as access
 	var walkMinutes int
 	var nearestStation string
+	var access []string
 	item.Find("li.cassetteitem_detail-col2 div.cassetteitem_detail-text").Each(func(i int, div *goquery.Selection) {
+		text := strings.TrimSpace(div.Text())
 		if i == 0 {
-			text := strings.TrimSpace(div.Text())
 			walkMinutes, _ = models.ParseWalkMinutes(text)
 			nearestStation = models.ParseStationName(text)
 		}
+		if text != "" {
+			access = append(access, text)
+		}
 	})
 
 	// Each room/unit is in a table row
 	item.Find("table.cassetteitem_other tbody tr").Each(func(_ int, row *goquery.Selection) {
 		prop := s.parseRoomRow(row, name, address, age, walkMinutes, nearestStation, buildingFloors)
 		if prop.ID != "" {
+			prop.TotalFloors = totalFloors
+			prop.Access = strings.Join(access, " / ")
 			properties = append(properties, prop)
 		}
 	})
@@ -1,7 +1,9 @@
 package scraper
 
 import (
+	"bytes"
 	"context"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -185,6 +187,12 @@ func TestParseProperties(t *testing.T) {
 	if p1.ID != "jnc_000102396492" {
 		t.Errorf("Property 1 ID = %q, want %q", p1.ID, "jnc_000102396492")
 	}
+	if p1.TotalFloors != 3 {
+		t.Errorf("Property 1 TotalFloors = %d, want %d", p1.TotalFloors, 3)
+	}
+	if want := "西武新宿線/新井薬師前駅 歩8分 / 西武新宿線/沼袋駅 歩10分"; p1.Access != want {
+		t.Errorf("Property 1 Access = %q, want %q", p1.Access, want)
+	}
 
 	// Check third property (new construction)
 	p3 := properties[2]
@@ -329,6 +337,85 @@ func TestScrapeWithMockServer(t *testing.T) {
 	}
 }
 
+func TestScrapeTruncated(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxPages int
+		want     bool
+	}{
+		{name: "last page scraped", maxPages: 5, want: false},
+		{name: "stopped at max pages", maxPages: 1, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("page") == "2" {
+					_, _ = w.Write([]byte(sampleHTMLNoNext))
+					return
+				}
+				_, _ = w.Write([]byte(sampleHTML))
+			}))
+			defer server.Close()
+
+			s := NewScraper(server.URL, WithMaxPages(tt.maxPages), WithRetryAttempts(1))
+			_, stats, err := s.ScrapeWithStats(context.Background())
+			if err != nil {
+				t.Fatalf("ScrapeWithStats() error = %v", err)
+			}
+			if stats.Truncated != tt.want {
+				t.Errorf("Truncated = %v, want %v", stats.Truncated, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrapeWithStats(t *testing.T) {
+	// A row without a property link and a row without a rent
+	page := strings.Replace(sampleHTMLNoNext, "</tbody>", `
+			<tr><td>1</td><td>-</td><td>3階</td><td><span class="cassetteitem_price--rent">8万円</span></td></tr>
+			<tr>
+				<td>1</td><td>-</td><td>4階</td>
+				<td><span class="cassetteitem_price--rent">-</span></td>
+				<td><span class="cassetteitem_madori">1K</span></td>
+				<td><span class="cassetteitem_menseki">20.0m²</span></td>
+				<td><a href="/chintai/jnc_000102396496/">詳細を見る</a></td>
+			</tr>
+		</tbody>`, 1)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	s := NewScraper(server.URL, WithRetryAttempts(2), WithRetryDelay(time.Millisecond),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	properties, stats, err := s.ScrapeWithStats(context.Background())
+	if err != nil {
+		t.Fatalf("ScrapeWithStats() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), `msg="Retrying page fetch" page=1 attempt=1`) {
+		t.Errorf("Logs = %q, want the retry logged", logs.String())
+	}
+
+	want := Stats{Pages: 1, Retries: 1, Rows: 3, Skipped: 1, Incomplete: 1}
+	if stats != want {
+		t.Errorf("Stats = %+v, want %+v", stats, want)
+	}
+	if stats.ParseFailures() != 2 {
+		t.Errorf("ParseFailures() = %d, want 2", stats.ParseFailures())
+	}
+	if len(properties) != 2 {
+		t.Errorf("Got %d properties, want 2", len(properties))
+	}
+}
+
 func TestScrapeContextCancellation(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(100 * time.Millisecond)
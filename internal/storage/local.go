@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// LocalClient is an S3API backed by the local filesystem, for running
+// without AWS. Objects are stored as files at <root>/<bucket>/<key>.
+type LocalClient struct {
+	root string
+}
+
+// NewLocalClient creates a LocalClient storing objects under root.
+func NewLocalClient(root string) *LocalClient {
+	return &LocalClient{root: root}
+}
+
+// path returns the file path of an object.
+func (c *LocalClient) path(bucket, key *string) string {
+	return filepath.Join(c.root, aws.ToString(bucket), filepath.FromSlash(aws.ToString(key)))
+}
+
+// GetObject reads the object file. Returns types.NoSuchKey if it doesn't exist.
+func (c *LocalClient) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data, err := os.ReadFile(c.path(params.Bucket, params.Key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &types.NoSuchKey{Message: aws.String(aws.ToString(params.Key))}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+// PutObject writes the object file, creating directories as needed.
+func (c *LocalClient) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	path := c.path(params.Bucket, params.Key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object body: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write object: %w", err)
+	}
+
+	return &s3.PutObjectOutput{}, nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alp/suumo-hunter/internal/models"
+)
+
+func TestLocalClientRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	store := NewStorage(NewLocalClient(root), "bucket", "data/properties.csv")
+	ctx := context.Background()
+
+	// Missing objects are not errors
+	properties, err := store.Download(ctx)
+	if err != nil || len(properties) != 0 {
+		t.Fatalf("Download() of a missing object = (%v, %v), want empty", properties, err)
+	}
+	var v map[string]int
+	if found, err := store.DownloadJSON(ctx, "state.json", &v); found || err != nil {
+		t.Fatalf("DownloadJSON() of a missing object = (%v, %v), want not found", found, err)
+	}
+
+	want := []models.Property{{ID: "jnc_001", Name: "テストマンション", Rent: 80000}}
+	if err := store.Upload(ctx, want); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "bucket", "data", "properties.csv")); err != nil {
+		t.Errorf("Object file should exist: %v", err)
+	}
+
+	got, err := store.Download(ctx)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "jnc_001" || got[0].Rent != 80000 {
+		t.Errorf("Download() = %+v, want %+v", got, want)
+	}
+
+	if err := store.UploadJSON(ctx, "state.json", map[string]int{"runs": 3}); err != nil {
+		t.Fatalf("UploadJSON() error = %v", err)
+	}
+	if found, err := store.DownloadJSON(ctx, "state.json", &v); !found || err != nil || v["runs"] != 3 {
+		t.Errorf("DownloadJSON() = (%v, %v, %v), want runs=3", found, err, v)
+	}
+}
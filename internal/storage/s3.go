@@ -4,14 +4,18 @@ package storage
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 
+	"github.com/alp/suumo-hunter/internal/metrics"
 	"github.com/alp/suumo-hunter/internal/models"
 )
 
@@ -27,15 +31,49 @@ type Storage struct {
 	client     S3API
 	bucketName string
 	bucketKey  string
+	dryRun     bool
+	logger     *slog.Logger
+	metrics    metrics.Recorder
+}
+
+// Option is a function that configures a Storage.
+type Option func(*Storage)
+
+// WithDryRun logs uploads instead of saving them. Downloads are unaffected.
+func WithDryRun(enabled bool) Option {
+	return func(s *Storage) {
+		s.dryRun = enabled
+	}
+}
+
+// WithLogger sets the logger. Defaults to slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Storage) {
+		s.logger = l
+	}
+}
+
+// WithMetrics sets the recorder of S3 operation counts, durations and
+// uploaded bytes.
+func WithMetrics(m metrics.Recorder) Option {
+	return func(s *Storage) {
+		s.metrics = m
+	}
 }
 
 // NewStorage creates a new Storage instance.
-func NewStorage(client S3API, bucketName, bucketKey string) *Storage {
-	return &Storage{
+func NewStorage(client S3API, bucketName, bucketKey string, opts ...Option) *Storage {
+	s := &Storage{
 		client:     client,
 		bucketName: bucketName,
 		bucketKey:  bucketKey,
+		logger:     slog.Default(),
+		metrics:    metrics.Nop{},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Download fetches the property CSV from S3 and returns the parsed properties.
@@ -46,21 +84,13 @@ func (s *Storage) Download(ctx context.Context) ([]models.Property, error) {
 		Key:    aws.String(s.bucketKey),
 	}
 
-	result, err := s.client.GetObject(ctx, input)
+	result, err := s.getObject(ctx, input)
 	if err != nil {
-		// Check if the error is "NoSuchKey" (file doesn't exist)
-		var noSuchKey *types.NoSuchKey
-		if errors.As(err, &noSuchKey) {
+		if isNotFound(err) {
 			// File doesn't exist yet, return empty slice
 			return []models.Property{}, nil
 		}
 
-		// Also check for NotFound error message (some S3-compatible services)
-		var notFound *types.NotFound
-		if errors.As(err, &notFound) {
-			return []models.Property{}, nil
-		}
-
 		return nil, fmt.Errorf("failed to download from S3: %w", err)
 	}
 	defer result.Body.Close()
@@ -76,6 +106,7 @@ func (s *Storage) Download(ctx context.Context) ([]models.Property, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse CSV from S3: %w", err)
 	}
+	s.logger.DebugContext(ctx, "Downloaded object", "key", s.bucketKey, "properties", len(properties), "bytes", len(data))
 
 	return properties, nil
 }
@@ -87,6 +118,10 @@ func (s *Storage) Upload(ctx context.Context, properties []models.Property) erro
 	if err := models.SaveToCSV(&buf, properties); err != nil {
 		return fmt.Errorf("failed to convert properties to CSV: %w", err)
 	}
+	if s.dryRun {
+		s.logger.InfoContext(ctx, "Dry run: skipping upload", "key", s.bucketKey, "properties", len(properties), "bytes", buf.Len())
+		return nil
+	}
 
 	input := &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucketName),
@@ -95,19 +130,122 @@ func (s *Storage) Upload(ctx context.Context, properties []models.Property) erro
 		ContentType: aws.String("text/csv; charset=utf-8"),
 	}
 
-	_, err := s.client.PutObject(ctx, input)
+	_, err := s.putObject(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to upload to S3: %w", err)
 	}
+	s.logger.DebugContext(ctx, "Uploaded object", "key", s.bucketKey, "properties", len(properties), "bytes", buf.Len())
+
+	return nil
+}
+
+// DownloadJSON fetches the JSON object at key from S3 and decodes it into v.
+// Returns false (and no error) if the object doesn't exist.
+func (s *Storage) DownloadJSON(ctx context.Context, key string, v any) (bool, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	}
+
+	result, err := s.getObject(ctx, input)
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to download %s from S3: %w", key, err)
+	}
+	defer result.Body.Close()
+
+	if err := json.NewDecoder(result.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	s.logger.DebugContext(ctx, "Downloaded object", "key", key)
+
+	return true, nil
+}
+
+// UploadJSON encodes v as JSON and saves it to S3 at key.
+func (s *Storage) UploadJSON(ctx context.Context, key string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	if s.dryRun {
+		s.logger.InfoContext(ctx, "Dry run: skipping upload", "key", key, "bytes", len(data))
+		return nil
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	}
+
+	if _, err := s.putObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload %s to S3: %w", key, err)
+	}
+	s.logger.DebugContext(ctx, "Uploaded object", "key", key, "bytes", len(data))
 
 	return nil
 }
 
+// getObject gets an object from S3 and records the operation.
+func (s *Storage) getObject(ctx context.Context, input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	start := time.Now()
+	output, err := s.client.GetObject(ctx, input)
+
+	result := metrics.Result(err)
+	if isNotFound(err) {
+		result = metrics.L("result", "not_found")
+	}
+	s.metrics.Count("storage_operations_total", 1, metrics.L("operation", "get"), result)
+	s.metrics.Timing("storage_operation_duration_seconds", time.Since(start), metrics.L("operation", "get"))
+
+	return output, err
+}
+
+// putObject puts an object to S3 and records the operation.
+func (s *Storage) putObject(ctx context.Context, input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	start := time.Now()
+	var size int64
+	if r, ok := input.Body.(*bytes.Reader); ok {
+		size = r.Size()
+	}
+	output, err := s.client.PutObject(ctx, input)
+
+	s.metrics.Count("storage_operations_total", 1, metrics.L("operation", "put"), metrics.Result(err))
+	s.metrics.Timing("storage_operation_duration_seconds", time.Since(start), metrics.L("operation", "put"))
+	if err == nil {
+		s.metrics.Count("storage_uploaded_bytes_total", float64(size))
+	}
+
+	return output, err
+}
+
+// isNotFound reports whether err indicates that the S3 object doesn't exist.
+func isNotFound(err error) bool {
+	// Check if the error is "NoSuchKey" (file doesn't exist)
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return true
+	}
+
+	// Also check for NotFound error message (some S3-compatible services)
+	var notFound *types.NotFound
+	return errors.As(err, &notFound)
+}
+
 // BucketName returns the configured bucket name.
 func (s *Storage) BucketName() string {
 	return s.bucketName
 }
 
+// DryRun reports whether uploads are skipped.
+func (s *Storage) DryRun() bool {
+	return s.dryRun
+}
+
 // BucketKey returns the configured bucket key.
 func (s *Storage) BucketKey() string {
 	return s.bucketKey
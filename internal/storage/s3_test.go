@@ -5,11 +5,13 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 
+	"github.com/alp/suumo-hunter/internal/metrics"
 	"github.com/alp/suumo-hunter/internal/models"
 )
 
@@ -188,6 +190,32 @@ func TestUploadError(t *testing.T) {
 	}
 }
 
+func TestUploadDryRun(t *testing.T) {
+	mock := &mockS3Client{
+		putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+			t.Errorf("PutObject(%s) called in dry run", *params.Key)
+			return &s3.PutObjectOutput{}, nil
+		},
+	}
+
+	storage := NewStorage(mock, "test-bucket", "properties.csv", WithDryRun(true))
+	ctx := context.Background()
+
+	if !storage.DryRun() {
+		t.Error("DryRun() = false, want true")
+	}
+	if err := storage.Upload(ctx, []models.Property{{ID: "1"}}); err != nil {
+		t.Errorf("Upload() error = %v", err)
+	}
+	if err := storage.UploadJSON(ctx, "model.json", map[string]int{"a": 1}); err != nil {
+		t.Errorf("UploadJSON() error = %v", err)
+	}
+	// Encoding errors are still reported
+	if err := storage.UploadJSON(ctx, "bad.json", make(chan int)); err == nil {
+		t.Error("UploadJSON() expected encoding error, got nil")
+	}
+}
+
 func TestStorageAccessors(t *testing.T) {
 	storage := NewStorage(nil, "my-bucket", "my-key.csv")
 
@@ -283,3 +311,72 @@ func TestDownloadAndUploadRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestJSONRoundTrip(t *testing.T) {
+	objects := make(map[string][]byte)
+
+	mock := &mockS3Client{
+		getObjectFunc: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			data, ok := objects[*params.Key]
+			if !ok {
+				return nil, &types.NoSuchKey{}
+			}
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+		},
+		putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+			data, _ := io.ReadAll(params.Body)
+			objects[*params.Key] = data
+			return &s3.PutObjectOutput{}, nil
+		},
+	}
+
+	storage := NewStorage(mock, "test-bucket", "properties.csv")
+	ctx := context.Background()
+
+	var missing map[string]int
+	found, err := storage.DownloadJSON(ctx, "state.json", &missing)
+	if err != nil || found {
+		t.Fatalf("DownloadJSON() for missing key = (%v, %v), want (false, nil)", found, err)
+	}
+
+	if err := storage.UploadJSON(ctx, "state.json", map[string]int{"count": 3}); err != nil {
+		t.Fatalf("UploadJSON() error = %v", err)
+	}
+
+	var loaded map[string]int
+	found, err = storage.DownloadJSON(ctx, "state.json", &loaded)
+	if err != nil || !found {
+		t.Fatalf("DownloadJSON() = (%v, %v), want (true, nil)", found, err)
+	}
+	if loaded["count"] != 3 {
+		t.Errorf("DownloadJSON() count = %d, want 3", loaded["count"])
+	}
+}
+
+func TestStorageMetrics(t *testing.T) {
+	registry := metrics.NewRegistry("")
+	storage := NewStorage(NewLocalClient(t.TempDir()), "test-bucket", "properties.csv", WithMetrics(registry))
+	ctx := context.Background()
+
+	if _, err := storage.Download(ctx); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if err := storage.Upload(ctx, []models.Property{{ID: "jnc_001", Name: "テストマンション"}}); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := registry.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	for _, want := range []string{
+		`storage_operations_total{operation="get",result="not_found"} 1`,
+		`storage_operations_total{operation="put",result="success"} 1`,
+		`storage_operation_duration_seconds_count{operation="get"} 1`,
+		"storage_uploaded_bytes_total ",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Metrics lack %q:\n%s", want, buf.String())
+		}
+	}
+}
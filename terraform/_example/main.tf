@@ -28,7 +28,9 @@ module "suumo_hunter" {
   discord_webhook_url = var.discord_webhook_url
   max_page            = var.max_page
   schedule_expression = var.schedule_expression
+  job_schedules       = var.job_schedules
   create_iam_role     = var.create_iam_role
+  extra_environment   = var.extra_environment
   lambda_zip_path     = "${path.module}/../../build/lambda.zip"
 }
 
@@ -56,11 +58,24 @@ variable "schedule_expression" {
   default = "cron(15 0,6,9,13 * * ? *)"
 }
 
+variable "job_schedules" {
+  type = map(object({
+    schedule_expression = string
+    event               = string # JSON event, e.g. jsonencode({ job = "digest" })
+  }))
+  default = {}
+}
+
 variable "create_iam_role" {
   type    = bool
   default = true
 }
 
+variable "extra_environment" {
+  type    = map(string)
+  default = {}
+}
+
 # Outputs
 output "lambda_function_name" {
   value = module.suumo_hunter.lambda_function_name
@@ -13,3 +13,23 @@ resource "aws_cloudwatch_event_target" "lambda" {
   target_id = "${local.name_prefix}-lambda"
   arn       = aws_lambda_function.suumo_hunter.arn
 }
+
+# Additional schedules running other jobs (e.g., digest, report) with a JSON event
+resource "aws_cloudwatch_event_rule" "job" {
+  for_each = var.job_schedules
+
+  name                = "${local.name_prefix}-${each.key}"
+  description         = "Trigger SUUMO Hunter Lambda (${var.instance_name}) job ${each.key} on schedule"
+  schedule_expression = each.value.schedule_expression
+
+  tags = local.common_tags
+}
+
+resource "aws_cloudwatch_event_target" "job" {
+  for_each = var.job_schedules
+
+  rule      = aws_cloudwatch_event_rule.job[each.key].name
+  target_id = "${local.name_prefix}-${each.key}"
+  arn       = aws_lambda_function.suumo_hunter.arn
+  input     = each.value.event
+}
@@ -13,13 +13,13 @@ resource "aws_lambda_function" "suumo_hunter" {
   memory_size   = 256
 
   environment {
-    variables = {
+    variables = merge(var.extra_environment, {
       BUCKET_NAME         = aws_s3_bucket.properties.id
       BUCKET_KEY          = "properties.csv"
       MAX_PAGE            = tostring(var.max_page)
       SUUMO_SEARCH_URL    = var.suumo_search_url
       DISCORD_WEBHOOK_URL = var.discord_webhook_url
-    }
+    })
   }
 
   tags = local.common_tags
@@ -41,3 +41,13 @@ resource "aws_lambda_permission" "eventbridge" {
   principal     = "events.amazonaws.com"
   source_arn    = aws_cloudwatch_event_rule.schedule.arn
 }
+
+resource "aws_lambda_permission" "eventbridge_job" {
+  for_each = var.job_schedules
+
+  statement_id  = "AllowEventBridgeInvoke-${each.key}"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.suumo_hunter.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.job[each.key].arn
+}
@@ -32,12 +32,27 @@ variable "schedule_expression" {
   default     = "cron(15 0,6,9,13 * * ? *)" # JST 09:15, 15:15, 18:15, 22:15
 }
 
+variable "job_schedules" {
+  description = "Additional schedules keyed by name, each invoking the function with a JSON event"
+  type = map(object({
+    schedule_expression = string
+    event               = string # JSON event, e.g. jsonencode({ job = "digest" })
+  }))
+  default = {}
+}
+
 variable "create_iam_role" {
   description = "Whether to create IAM role (set to false for additional instances to share existing role)"
   type        = bool
   default     = true
 }
 
+variable "extra_environment" {
+  description = "Additional environment variables for optional features (e.g., COMPARABLES_K, NEIGHBOR_STATIONS)"
+  type        = map(string)
+  default     = {}
+}
+
 variable "lambda_zip_path" {
   description = "Path to the Lambda zip file"
   type        = string